@@ -27,7 +27,7 @@ func (r bookCargoResponse) error() error { return r.Err }
 func makeBookCargoEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(bookCargoRequest)
-		id, err := s.BookNewCargo(req.Origin, req.Destination, req.ArrivalDeadline)
+		id, err := s.BookNewCargo(ctx, req.Origin, req.Destination, req.ArrivalDeadline)
 		return bookCargoResponse{ID: id, Err: err}, nil
 	}
 }
@@ -40,10 +40,12 @@ type unbookCargoResponse struct {
 	Err error `json:"error,omitempty"`
 }
 
+func (r unbookCargoResponse) error() error { return r.Err }
+
 func makeUnbookCargoEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(unbookCargoRequest)
-		err := s.UnbookCargo(req.ID)
+		err := s.UnbookCargo(ctx, req.ID)
 		return unbookCargoResponse{Err: err}, nil
 	}
 }
@@ -62,7 +64,7 @@ func (r loadCargoResponse) error() error { return r.Err }
 func makeLoadCargoEndpoint(bs Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(loadCargoRequest)
-		c, err := bs.LoadCargo(req.ID)
+		c, err := bs.LoadCargo(ctx, req.ID)
 		return loadCargoResponse{Cargo: &c, Err: err}, nil
 	}
 }
@@ -81,8 +83,8 @@ func (r requestRoutesResponse) error() error { return r.Err }
 func makeRequestRoutesEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(requestRoutesRequest)
-		itin := s.RequestPossibleRoutesForCargo(req.ID)
-		return requestRoutesResponse{Routes: itin, Err: nil}, nil
+		itin, err := s.RequestPossibleRoutesForCargo(ctx, req.ID)
+		return requestRoutesResponse{Routes: itin, Err: err}, nil
 	}
 }
 
@@ -100,7 +102,7 @@ func (r assignToRouteResponse) error() error { return r.Err }
 func makeAssignToRouteEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(assignToRouteRequest)
-		err := s.AssignCargoToRoute(req.ID, req.Itinerary)
+		err := s.AssignCargoToRoute(ctx, req.ID, req.Itinerary)
 		return assignToRouteResponse{Err: err}, nil
 	}
 }
@@ -119,11 +121,48 @@ func (r changeDestinationResponse) error() error { return r.Err }
 func makeChangeDestinationEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(changeDestinationRequest)
-		err := s.ChangeDestination(req.ID, req.Destination)
+		err := s.ChangeDestination(ctx, req.ID, req.Destination)
 		return changeDestinationResponse{Err: err}, nil
 	}
 }
 
+type issueTrackingTokenRequest struct {
+	ID cargo.TrackingID
+}
+
+type issueTrackingTokenResponse struct {
+	TrackingToken *TrackingToken `json:"tracking_token,omitempty"`
+	Err           error          `json:"error,omitempty"`
+}
+
+func (r issueTrackingTokenResponse) error() error { return r.Err }
+
+func makeIssueTrackingTokenEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(issueTrackingTokenRequest)
+		t, err := s.IssueTrackingToken(ctx, req.ID)
+		return issueTrackingTokenResponse{TrackingToken: &t, Err: err}, nil
+	}
+}
+
+type revokeTrackingTokensRequest struct {
+	ID cargo.TrackingID
+}
+
+type revokeTrackingTokensResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r revokeTrackingTokensResponse) error() error { return r.Err }
+
+func makeRevokeTrackingTokensEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(revokeTrackingTokensRequest)
+		err := s.RevokeTrackingTokens(ctx, req.ID)
+		return revokeTrackingTokensResponse{Err: err}, nil
+	}
+}
+
 type listCargosRequest struct{}
 
 type listCargosResponse struct {
@@ -136,7 +175,8 @@ func (r listCargosResponse) error() error { return r.Err }
 func makeListCargosEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		_ = request.(listCargosRequest)
-		return listCargosResponse{Cargos: s.Cargos(), Err: nil}, nil
+		cs, err := s.Cargos(ctx)
+		return listCargosResponse{Cargos: cs, Err: err}, nil
 	}
 }
 
@@ -148,9 +188,12 @@ type listLocationsResponse struct {
 	Err       error      `json:"error,omitempty"`
 }
 
+func (r listLocationsResponse) error() error { return r.Err }
+
 func makeListLocationsEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		_ = request.(listLocationsRequest)
-		return listLocationsResponse{Locations: s.Locations(), Err: nil}, nil
+		ls, err := s.Locations(ctx)
+		return listLocationsResponse{Locations: ls, Err: err}, nil
 	}
 }
@@ -0,0 +1,371 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	kitlog "github.com/go-kit/kit/log"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/marcusolsson/goddd/booking/pb"
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// MakeGRPCServer returns a server serving the booking service over gRPC.
+func MakeGRPCServer(ctx context.Context, bs Service, logger kitlog.Logger) pb.BookingServiceServer {
+	opts := []kitgrpc.ServerOption{
+		kitgrpc.ServerErrorLogger(logger),
+	}
+
+	return &grpcServer{
+		bookNewCargo: rpc.NewServer(
+			ctx,
+			makeBookCargoEndpoint(bs),
+			decodeGRPCBookCargoRequest,
+			encodeGRPCBookCargoResponse,
+			opts...,
+		),
+		unbookCargo: rpc.NewServer(
+			ctx,
+			makeUnbookCargoEndpoint(bs),
+			decodeGRPCUnbookCargoRequest,
+			encodeGRPCUnbookCargoResponse,
+			opts...,
+		),
+		loadCargo: rpc.NewServer(
+			ctx,
+			makeLoadCargoEndpoint(bs),
+			decodeGRPCLoadCargoRequest,
+			encodeGRPCLoadCargoResponse,
+			opts...,
+		),
+		requestPossibleRoutes: rpc.NewServer(
+			ctx,
+			makeRequestRoutesEndpoint(bs),
+			decodeGRPCRequestRoutesRequest,
+			encodeGRPCRequestRoutesResponse,
+			opts...,
+		),
+		assignCargoToRoute: rpc.NewServer(
+			ctx,
+			makeAssignToRouteEndpoint(bs),
+			decodeGRPCAssignToRouteRequest,
+			encodeGRPCAssignToRouteResponse,
+			opts...,
+		),
+		changeDestination: rpc.NewServer(
+			ctx,
+			makeChangeDestinationEndpoint(bs),
+			decodeGRPCChangeDestinationRequest,
+			encodeGRPCChangeDestinationResponse,
+			opts...,
+		),
+		listCargos: rpc.NewServer(
+			ctx,
+			makeListCargosEndpoint(bs),
+			decodeGRPCListCargosRequest,
+			encodeGRPCListCargosResponse,
+			opts...,
+		),
+		listLocations: rpc.NewServer(
+			ctx,
+			makeListLocationsEndpoint(bs),
+			decodeGRPCListLocationsRequest,
+			encodeGRPCListLocationsResponse,
+			opts...,
+		),
+	}
+}
+
+type grpcServer struct {
+	bookNewCargo          kitgrpc.Handler
+	unbookCargo           kitgrpc.Handler
+	loadCargo             kitgrpc.Handler
+	requestPossibleRoutes kitgrpc.Handler
+	assignCargoToRoute    kitgrpc.Handler
+	changeDestination     kitgrpc.Handler
+	listCargos            kitgrpc.Handler
+	listLocations         kitgrpc.Handler
+}
+
+func (s *grpcServer) BookNewCargo(ctx oldcontext.Context, req *pb.BookNewCargoRequest) (*pb.BookNewCargoResponse, error) {
+	_, resp, err := s.bookNewCargo.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.BookNewCargoResponse), nil
+}
+
+func (s *grpcServer) UnbookCargo(ctx oldcontext.Context, req *pb.UnbookCargoRequest) (*pb.UnbookCargoResponse, error) {
+	_, resp, err := s.unbookCargo.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.UnbookCargoResponse), nil
+}
+
+func (s *grpcServer) LoadCargo(ctx oldcontext.Context, req *pb.LoadCargoRequest) (*pb.LoadCargoResponse, error) {
+	_, resp, err := s.loadCargo.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.LoadCargoResponse), nil
+}
+
+func (s *grpcServer) RequestPossibleRoutes(ctx oldcontext.Context, req *pb.RequestPossibleRoutesRequest) (*pb.RequestPossibleRoutesResponse, error) {
+	_, resp, err := s.requestPossibleRoutes.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.RequestPossibleRoutesResponse), nil
+}
+
+func (s *grpcServer) AssignCargoToRoute(ctx oldcontext.Context, req *pb.AssignCargoToRouteRequest) (*pb.AssignCargoToRouteResponse, error) {
+	_, resp, err := s.assignCargoToRoute.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.AssignCargoToRouteResponse), nil
+}
+
+func (s *grpcServer) ChangeDestination(ctx oldcontext.Context, req *pb.ChangeDestinationRequest) (*pb.ChangeDestinationResponse, error) {
+	_, resp, err := s.changeDestination.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.ChangeDestinationResponse), nil
+}
+
+func (s *grpcServer) ListCargos(ctx oldcontext.Context, req *pb.ListCargosRequest) (*pb.ListCargosResponse, error) {
+	_, resp, err := s.listCargos.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.ListCargosResponse), nil
+}
+
+func (s *grpcServer) ListLocations(ctx oldcontext.Context, req *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error) {
+	_, resp, err := s.listLocations.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.ListLocationsResponse), nil
+}
+
+func decodeGRPCBookCargoRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.BookNewCargoRequest)
+	return bookCargoRequest{
+		Origin:          location.UNLocode(req.Origin),
+		Destination:     location.UNLocode(req.Destination),
+		ArrivalDeadline: rpc.Time(req.ArrivalDeadline),
+	}, nil
+}
+
+func encodeGRPCBookCargoResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(bookCargoResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.BookNewCargoResponse{TrackingId: string(resp.ID)}, nil
+}
+
+func decodeGRPCUnbookCargoRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.UnbookCargoRequest)
+	return unbookCargoRequest{ID: cargo.TrackingID(req.TrackingId)}, nil
+}
+
+func encodeGRPCUnbookCargoResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(unbookCargoResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.UnbookCargoResponse{}, nil
+}
+
+func decodeGRPCLoadCargoRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.LoadCargoRequest)
+	return loadCargoRequest{ID: cargo.TrackingID(req.TrackingId)}, nil
+}
+
+func encodeGRPCLoadCargoResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(loadCargoResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.LoadCargoResponse{Cargo: cargoToPB(*resp.Cargo)}, nil
+}
+
+func decodeGRPCRequestRoutesRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.RequestPossibleRoutesRequest)
+	return requestRoutesRequest{ID: cargo.TrackingID(req.TrackingId)}, nil
+}
+
+func encodeGRPCRequestRoutesResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(requestRoutesResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	routes := make([]*pb.Itinerary, len(resp.Routes))
+	for i, itinerary := range resp.Routes {
+		routes[i] = &pb.Itinerary{Legs: legsToPB(itinerary.Legs)}
+	}
+	return &pb.RequestPossibleRoutesResponse{Routes: routes}, nil
+}
+
+func decodeGRPCAssignToRouteRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.AssignCargoToRouteRequest)
+
+	var (
+		legs []cargo.Leg
+		errs validation.Errors
+	)
+	for i, l := range req.GetItinerary().GetLegs() {
+		mode, err := legMode(l.Mode)
+		if err != nil {
+			errs.Add(fmt.Sprintf("itinerary.legs[%d].mode", i), "%v", err)
+		}
+		legs = append(legs, cargo.Leg{
+			VoyageNumber:   voyage.Number(l.VoyageNumber),
+			Mode:           mode,
+			LoadLocation:   location.UNLocode(l.From),
+			UnloadLocation: location.UNLocode(l.To),
+			LoadTime:       rpc.Time(l.LoadTime),
+			UnloadTime:     rpc.Time(l.UnloadTime),
+		})
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignToRouteRequest{
+		ID:        cargo.TrackingID(req.TrackingId),
+		Itinerary: cargo.Itinerary{Legs: legs},
+	}, nil
+}
+
+func encodeGRPCAssignToRouteResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(assignToRouteResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.AssignCargoToRouteResponse{}, nil
+}
+
+func decodeGRPCChangeDestinationRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ChangeDestinationRequest)
+	return changeDestinationRequest{
+		ID:          cargo.TrackingID(req.TrackingId),
+		Destination: location.UNLocode(req.Destination),
+	}, nil
+}
+
+func encodeGRPCChangeDestinationResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(changeDestinationResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.ChangeDestinationResponse{}, nil
+}
+
+func decodeGRPCListCargosRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return listCargosRequest{}, nil
+}
+
+func encodeGRPCListCargosResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(listCargosResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	cargos := make([]*pb.Cargo, len(resp.Cargos))
+	for i, c := range resp.Cargos {
+		cargos[i] = cargoToPB(c)
+	}
+	return &pb.ListCargosResponse{Cargos: cargos}, nil
+}
+
+func decodeGRPCListLocationsRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return listLocationsRequest{}, nil
+}
+
+func encodeGRPCListLocationsResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(listLocationsResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	locations := make([]*pb.Location, len(resp.Locations))
+	for i, l := range resp.Locations {
+		locations[i] = &pb.Location{Locode: l.UNLocode, Name: l.Name}
+	}
+	return &pb.ListLocationsResponse{Locations: locations}, nil
+}
+
+func cargoToPB(c Cargo) *pb.Cargo {
+	return &pb.Cargo{
+		TrackingId:      c.TrackingID,
+		Origin:          c.Origin,
+		Destination:     c.Destination,
+		ArrivalDeadline: rpc.Timestamp(c.ArrivalDeadline),
+		Misrouted:       c.Misrouted,
+		Routed:          c.Routed,
+		Legs:            legsToPB(c.Legs),
+		Eta:             rpc.Timestamp(c.ETA),
+		EtaEarliest:     rpc.Timestamp(c.ETAEarliest),
+		EtaLatest:       rpc.Timestamp(c.ETALatest),
+	}
+}
+
+func legsToPB(legs []cargo.Leg) []*pb.Leg {
+	res := make([]*pb.Leg, len(legs))
+	for i, l := range legs {
+		res[i] = &pb.Leg{
+			VoyageNumber: string(l.VoyageNumber),
+			Mode:         l.Mode.String(),
+			From:         string(l.LoadLocation),
+			To:           string(l.UnloadLocation),
+			LoadTime:     rpc.Timestamp(l.LoadTime),
+			UnloadTime:   rpc.Timestamp(l.UnloadTime),
+		}
+	}
+	return res
+}
+
+// legMode parses the mode of transport of a leg, legs without one being by
+// sea.
+func legMode(s string) (voyage.Mode, error) {
+	if s == "" {
+		return voyage.Sea, nil
+	}
+	return voyage.ParseMode(s)
+}
+
+// grpcError returns err with the status code of errors from business-logic,
+// so that clients can tell them apart.
+func grpcError(err error) error {
+	return grpc.Errorf(grpcCode(err), "%v", err)
+}
+
+// grpcCode returns the status code of errors from business-logic.
+func grpcCode(err error) codes.Code {
+	var verr *validation.Error
+	switch {
+	case errors.As(err, &verr):
+		return codes.InvalidArgument
+	case err == cargo.ErrUnknown:
+		return codes.NotFound
+	case err == ErrInvalidArgument:
+		return codes.InvalidArgument
+	case errors.Is(err, routing.ErrUnavailable):
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
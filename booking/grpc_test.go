@@ -0,0 +1,145 @@
+package booking
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/marcusolsson/goddd/booking/pb"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+func dialGRPC(t *testing.T, s Service) pb.BookingServiceClient {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	pb.RegisterBookingServiceServer(srv, MakeGRPCServer(context.Background(), s, kitlog.NewNopLogger()))
+	go srv.Serve(ln)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+
+	return pb.NewBookingServiceClient(conn)
+}
+
+func TestGRPCBookAndLoadCargo(t *testing.T) {
+	var cargos mockCargoRepository
+
+	client := dialGRPC(t, NewService(&cargos, nil, nil, &stubRoutingService{}, nil, nil))
+	ctx := context.Background()
+
+	deadline := time.Date(2030, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	booked, err := client.BookNewCargo(ctx, &pb.BookNewCargoRequest{
+		Origin:          "SESTO",
+		Destination:     "AUMEL",
+		ArrivalDeadline: rpc.Timestamp(deadline),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if booked.TrackingId == "" {
+		t.Fatal("booked.TrackingId is empty")
+	}
+
+	loaded, err := client.LoadCargo(ctx, &pb.LoadCargoRequest{TrackingId: booked.TrackingId})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := loaded.GetCargo()
+	if c == nil {
+		t.Fatal("loaded.Cargo is nil")
+	}
+	if c.TrackingId != booked.TrackingId {
+		t.Errorf("c.TrackingId = %s; want = %s", c.TrackingId, booked.TrackingId)
+	}
+	if c.Origin != "SESTO" {
+		t.Errorf("c.Origin = %s; want = %s", c.Origin, "SESTO")
+	}
+	if c.Destination != "AUMEL" {
+		t.Errorf("c.Destination = %s; want = %s", c.Destination, "AUMEL")
+	}
+	if got := rpc.Time(c.GetArrivalDeadline()); !got.Equal(deadline) {
+		t.Errorf("c.ArrivalDeadline = %s; want = %s", got, deadline)
+	}
+
+	routes, err := client.RequestPossibleRoutes(ctx, &pb.RequestPossibleRoutesRequest{TrackingId: booked.TrackingId})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(routes.Routes) != 1 {
+		t.Fatalf("len(routes.Routes) = %d; want = 1", len(routes.Routes))
+	}
+	if leg := routes.Routes[0].GetLegs()[0]; leg.From != "SESTO" || leg.To != "AUMEL" {
+		t.Errorf("leg = %s-%s; want = SESTO-AUMEL", leg.From, leg.To)
+	}
+	if leg := routes.Routes[0].GetLegs()[0]; leg.Mode != "sea" {
+		t.Errorf("leg.Mode = %s; want = sea", leg.Mode)
+	}
+}
+
+func TestGRPCErrorCodes(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(_ context.Context, l location.UNLocode) (*location.Location, error) {
+		return nil, location.ErrUnknown
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(_ context.Context, n voyage.Number) (*voyage.Voyage, error) {
+		return nil, voyage.ErrUnknown
+	}
+
+	client := dialGRPC(t, NewValidatingService(&locations, &voyages, NewService(&cargos, &locations, nil, nil, nil, nil)))
+
+	tests := []struct {
+		name string
+		call func(context.Context) error
+		want codes.Code
+	}{
+		{"LoadCargo unknown", func(ctx context.Context) error {
+			_, err := client.LoadCargo(ctx, &pb.LoadCargoRequest{TrackingId: "ABC123"})
+			return err
+		}, codes.NotFound},
+		{"LoadCargo empty", func(ctx context.Context) error {
+			_, err := client.LoadCargo(ctx, &pb.LoadCargoRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"BookNewCargo", func(ctx context.Context) error {
+			_, err := client.BookNewCargo(ctx, &pb.BookNewCargoRequest{Origin: "SESTO"})
+			return err
+		}, codes.InvalidArgument},
+		{"AssignCargoToRoute", func(ctx context.Context) error {
+			_, err := client.AssignCargoToRoute(ctx, &pb.AssignCargoToRouteRequest{TrackingId: "ABC123", Itinerary: &pb.Itinerary{Legs: []*pb.Leg{{Mode: "hovercraft"}}}})
+			return err
+		}, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		err := tt.call(context.Background())
+		if code := grpc.Code(err); code != tt.want {
+			t.Errorf("%s: code = %s; want = %s (err = %v)", tt.name, code, tt.want, err)
+		}
+	}
+}
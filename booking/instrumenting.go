@@ -1,6 +1,7 @@
 package booking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -24,65 +25,83 @@ func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram,
 	}
 }
 
-func (s *instrumentingService) BookNewCargo(origin, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error) {
+func (s *instrumentingService) BookNewCargo(ctx context.Context, origin, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "book").Add(1)
 		s.requestLatency.With("method", "book").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.BookNewCargo(origin, destination, deadline)
+	return s.Service.BookNewCargo(ctx, origin, destination, deadline)
 }
 
-func (s *instrumentingService) LoadCargo(id cargo.TrackingID) (c Cargo, err error) {
+func (s *instrumentingService) LoadCargo(ctx context.Context, id cargo.TrackingID) (c Cargo, err error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "load").Add(1)
 		s.requestLatency.With("method", "load").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.LoadCargo(id)
+	return s.Service.LoadCargo(ctx, id)
 }
 
-func (s *instrumentingService) RequestPossibleRoutesForCargo(id cargo.TrackingID) []cargo.Itinerary {
+func (s *instrumentingService) RequestPossibleRoutesForCargo(ctx context.Context, id cargo.TrackingID) ([]cargo.Itinerary, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "request_routes").Add(1)
 		s.requestLatency.With("method", "request_routes").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.RequestPossibleRoutesForCargo(id)
+	return s.Service.RequestPossibleRoutesForCargo(ctx, id)
 }
 
-func (s *instrumentingService) AssignCargoToRoute(id cargo.TrackingID, itinerary cargo.Itinerary) (err error) {
+func (s *instrumentingService) AssignCargoToRoute(ctx context.Context, id cargo.TrackingID, itinerary cargo.Itinerary) (err error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "assign_to_route").Add(1)
 		s.requestLatency.With("method", "assign_to_route").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.AssignCargoToRoute(id, itinerary)
+	return s.Service.AssignCargoToRoute(ctx, id, itinerary)
 }
 
-func (s *instrumentingService) ChangeDestination(id cargo.TrackingID, l location.UNLocode) (err error) {
+func (s *instrumentingService) ChangeDestination(ctx context.Context, id cargo.TrackingID, l location.UNLocode) (err error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "change_destination").Add(1)
 		s.requestLatency.With("method", "change_destination").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.ChangeDestination(id, l)
+	return s.Service.ChangeDestination(ctx, id, l)
 }
 
-func (s *instrumentingService) Cargos() []Cargo {
+func (s *instrumentingService) Cargos(ctx context.Context) ([]Cargo, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "list_cargos").Add(1)
 		s.requestLatency.With("method", "list_cargos").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.Cargos()
+	return s.Service.Cargos(ctx)
 }
 
-func (s *instrumentingService) Locations() []Location {
+func (s *instrumentingService) Locations(ctx context.Context) ([]Location, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "list_locations").Add(1)
 		s.requestLatency.With("method", "list_locations").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.Locations()
+	return s.Service.Locations(ctx)
+}
+
+func (s *instrumentingService) IssueTrackingToken(ctx context.Context, id cargo.TrackingID) (TrackingToken, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "issue_tracking_token").Add(1)
+		s.requestLatency.With("method", "issue_tracking_token").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.Service.IssueTrackingToken(ctx, id)
+}
+
+func (s *instrumentingService) RevokeTrackingTokens(ctx context.Context, id cargo.TrackingID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "revoke_tracking_tokens").Add(1)
+		s.requestLatency.With("method", "revoke_tracking_tokens").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.Service.RevokeTrackingTokens(ctx, id)
 }
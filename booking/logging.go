@@ -1,12 +1,15 @@
 package booking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
 
 	"github.com/marcusolsson/goddd/cargo"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/requestid"
 )
 
 type loggingService struct {
@@ -19,21 +22,21 @@ func NewLoggingService(logger log.Logger, s Service) Service {
 	return &loggingService{logger, s}
 }
 
-func (s *loggingService) UnbookCargo(id cargo.TrackingID) (err error) {
+func (s *loggingService) UnbookCargo(ctx context.Context, id cargo.TrackingID) (err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "unbook",
 			"tracking_id", id,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.UnbookCargo(id)
+	return s.Service.UnbookCargo(ctx, id)
 }
 
-func (s *loggingService) BookNewCargo(origin location.UNLocode, destination location.UNLocode, deadline time.Time) (id cargo.TrackingID, err error) {
+func (s *loggingService) BookNewCargo(ctx context.Context, origin location.UNLocode, destination location.UNLocode, deadline time.Time) (id cargo.TrackingID, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "book",
 			"origin", origin,
 			"destination", destination,
@@ -42,47 +45,48 @@ func (s *loggingService) BookNewCargo(origin location.UNLocode, destination loca
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.BookNewCargo(origin, destination, deadline)
+	return s.Service.BookNewCargo(ctx, origin, destination, deadline)
 }
 
-func (s *loggingService) LoadCargo(id cargo.TrackingID) (c Cargo, err error) {
+func (s *loggingService) LoadCargo(ctx context.Context, id cargo.TrackingID) (c Cargo, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "load",
 			"tracking_id", id,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.LoadCargo(id)
+	return s.Service.LoadCargo(ctx, id)
 }
 
-func (s *loggingService) RequestPossibleRoutesForCargo(id cargo.TrackingID) []cargo.Itinerary {
+func (s *loggingService) RequestPossibleRoutesForCargo(ctx context.Context, id cargo.TrackingID) (itineraries []cargo.Itinerary, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "request_routes",
 			"tracking_id", id,
 			"took", time.Since(begin),
+			"err", err,
 		)
 	}(time.Now())
-	return s.Service.RequestPossibleRoutesForCargo(id)
+	return s.Service.RequestPossibleRoutesForCargo(ctx, id)
 }
 
-func (s *loggingService) AssignCargoToRoute(id cargo.TrackingID, itinerary cargo.Itinerary) (err error) {
+func (s *loggingService) AssignCargoToRoute(ctx context.Context, id cargo.TrackingID, itinerary cargo.Itinerary) (err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "assign_to_route",
 			"tracking_id", id,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.AssignCargoToRoute(id, itinerary)
+	return s.Service.AssignCargoToRoute(ctx, id, itinerary)
 }
 
-func (s *loggingService) ChangeDestination(id cargo.TrackingID, l location.UNLocode) (err error) {
+func (s *loggingService) ChangeDestination(ctx context.Context, id cargo.TrackingID, l location.UNLocode) (err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "change_destination",
 			"tracking_id", id,
 			"destination", l,
@@ -90,25 +94,61 @@ func (s *loggingService) ChangeDestination(id cargo.TrackingID, l location.UNLoc
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.ChangeDestination(id, l)
+	return s.Service.ChangeDestination(ctx, id, l)
 }
 
-func (s *loggingService) Cargos() []Cargo {
+func (s *loggingService) Cargos(ctx context.Context) (cs []Cargo, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "list_cargos",
 			"took", time.Since(begin),
+			"err", err,
 		)
 	}(time.Now())
-	return s.Service.Cargos()
+	return s.Service.Cargos(ctx)
 }
 
-func (s *loggingService) Locations() []Location {
+func (s *loggingService) Locations(ctx context.Context) (ls []Location, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "list_locations",
 			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Locations(ctx)
+}
+
+func (s *loggingService) IssueTrackingToken(ctx context.Context, id cargo.TrackingID) (t TrackingToken, err error) {
+	defer func(begin time.Time) {
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
+			"method", "issue_tracking_token",
+			"tracking_id", id,
+			"expires", t.Expires,
+			"took", time.Since(begin),
+			"err", err,
 		)
 	}(time.Now())
-	return s.Service.Locations()
+	return s.Service.IssueTrackingToken(ctx, id)
+}
+
+func (s *loggingService) RevokeTrackingTokens(ctx context.Context, id cargo.TrackingID) (err error) {
+	defer func(begin time.Time) {
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
+			"method", "revoke_tracking_tokens",
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RevokeTrackingTokens(ctx, id)
+}
+
+// leveled returns logger at error level if err is set, and at info level
+// otherwise, so that successful calls can be filtered by the log level.
+func leveled(logger log.Logger, err error) log.Logger {
+	if err != nil {
+		return level.Error(logger)
+	}
+	return level.Info(logger)
 }
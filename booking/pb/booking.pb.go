@@ -0,0 +1,897 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: booking.proto
+
+/*
+Package pb is a generated protocol buffer package.
+
+It is generated from these files:
+
+	booking.proto
+
+It has these top-level messages:
+
+	Cargo
+	Leg
+	Itinerary
+	Location
+	BookNewCargoRequest
+	BookNewCargoResponse
+	UnbookCargoRequest
+	UnbookCargoResponse
+	LoadCargoRequest
+	LoadCargoResponse
+	RequestPossibleRoutesRequest
+	RequestPossibleRoutesResponse
+	AssignCargoToRouteRequest
+	AssignCargoToRouteResponse
+	ChangeDestinationRequest
+	ChangeDestinationResponse
+	ListCargosRequest
+	ListCargosResponse
+	ListLocationsRequest
+	ListLocationsResponse
+*/
+package pb
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Cargo struct {
+	TrackingId      string                     `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+	Origin          string                     `protobuf:"bytes,2,opt,name=origin" json:"origin,omitempty"`
+	Destination     string                     `protobuf:"bytes,3,opt,name=destination" json:"destination,omitempty"`
+	ArrivalDeadline *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=arrival_deadline,json=arrivalDeadline" json:"arrival_deadline,omitempty"`
+	Misrouted       bool                       `protobuf:"varint,5,opt,name=misrouted" json:"misrouted,omitempty"`
+	Routed          bool                       `protobuf:"varint,6,opt,name=routed" json:"routed,omitempty"`
+	Legs            []*Leg                     `protobuf:"bytes,7,rep,name=legs" json:"legs,omitempty"`
+	// The estimated time of arrival, and the interval the cargo arrives within
+	// with 90% confidence. Not set for cargos that are not on track.
+	Eta         *google_protobuf.Timestamp `protobuf:"bytes,8,opt,name=eta" json:"eta,omitempty"`
+	EtaEarliest *google_protobuf.Timestamp `protobuf:"bytes,9,opt,name=eta_earliest,json=etaEarliest" json:"eta_earliest,omitempty"`
+	EtaLatest   *google_protobuf.Timestamp `protobuf:"bytes,10,opt,name=eta_latest,json=etaLatest" json:"eta_latest,omitempty"`
+}
+
+func (m *Cargo) Reset()                    { *m = Cargo{} }
+func (m *Cargo) String() string            { return proto.CompactTextString(m) }
+func (*Cargo) ProtoMessage()               {}
+func (*Cargo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Cargo) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+func (m *Cargo) GetOrigin() string {
+	if m != nil {
+		return m.Origin
+	}
+	return ""
+}
+
+func (m *Cargo) GetDestination() string {
+	if m != nil {
+		return m.Destination
+	}
+	return ""
+}
+
+func (m *Cargo) GetArrivalDeadline() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.ArrivalDeadline
+	}
+	return nil
+}
+
+func (m *Cargo) GetMisrouted() bool {
+	if m != nil {
+		return m.Misrouted
+	}
+	return false
+}
+
+func (m *Cargo) GetRouted() bool {
+	if m != nil {
+		return m.Routed
+	}
+	return false
+}
+
+func (m *Cargo) GetLegs() []*Leg {
+	if m != nil {
+		return m.Legs
+	}
+	return nil
+}
+
+func (m *Cargo) GetEta() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Eta
+	}
+	return nil
+}
+
+func (m *Cargo) GetEtaEarliest() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.EtaEarliest
+	}
+	return nil
+}
+
+func (m *Cargo) GetEtaLatest() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.EtaLatest
+	}
+	return nil
+}
+
+type Leg struct {
+	VoyageNumber string                     `protobuf:"bytes,1,opt,name=voyage_number,json=voyageNumber" json:"voyage_number,omitempty"`
+	From         string                     `protobuf:"bytes,2,opt,name=from" json:"from,omitempty"`
+	To           string                     `protobuf:"bytes,3,opt,name=to" json:"to,omitempty"`
+	LoadTime     *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=load_time,json=loadTime" json:"load_time,omitempty"`
+	UnloadTime   *google_protobuf.Timestamp `protobuf:"bytes,5,opt,name=unload_time,json=unloadTime" json:"unload_time,omitempty"`
+	// One of sea, rail, road or air. Legs without a mode are by sea.
+	Mode string `protobuf:"bytes,6,opt,name=mode" json:"mode,omitempty"`
+}
+
+func (m *Leg) Reset()                    { *m = Leg{} }
+func (m *Leg) String() string            { return proto.CompactTextString(m) }
+func (*Leg) ProtoMessage()               {}
+func (*Leg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Leg) GetVoyageNumber() string {
+	if m != nil {
+		return m.VoyageNumber
+	}
+	return ""
+}
+
+func (m *Leg) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Leg) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *Leg) GetLoadTime() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.LoadTime
+	}
+	return nil
+}
+
+func (m *Leg) GetUnloadTime() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.UnloadTime
+	}
+	return nil
+}
+
+func (m *Leg) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
+}
+
+type Itinerary struct {
+	Legs []*Leg `protobuf:"bytes,1,rep,name=legs" json:"legs,omitempty"`
+}
+
+func (m *Itinerary) Reset()                    { *m = Itinerary{} }
+func (m *Itinerary) String() string            { return proto.CompactTextString(m) }
+func (*Itinerary) ProtoMessage()               {}
+func (*Itinerary) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Itinerary) GetLegs() []*Leg {
+	if m != nil {
+		return m.Legs
+	}
+	return nil
+}
+
+type Location struct {
+	Locode string `protobuf:"bytes,1,opt,name=locode" json:"locode,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *Location) Reset()                    { *m = Location{} }
+func (m *Location) String() string            { return proto.CompactTextString(m) }
+func (*Location) ProtoMessage()               {}
+func (*Location) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *Location) GetLocode() string {
+	if m != nil {
+		return m.Locode
+	}
+	return ""
+}
+
+func (m *Location) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type BookNewCargoRequest struct {
+	Origin          string                     `protobuf:"bytes,1,opt,name=origin" json:"origin,omitempty"`
+	Destination     string                     `protobuf:"bytes,2,opt,name=destination" json:"destination,omitempty"`
+	ArrivalDeadline *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=arrival_deadline,json=arrivalDeadline" json:"arrival_deadline,omitempty"`
+}
+
+func (m *BookNewCargoRequest) Reset()                    { *m = BookNewCargoRequest{} }
+func (m *BookNewCargoRequest) String() string            { return proto.CompactTextString(m) }
+func (*BookNewCargoRequest) ProtoMessage()               {}
+func (*BookNewCargoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *BookNewCargoRequest) GetOrigin() string {
+	if m != nil {
+		return m.Origin
+	}
+	return ""
+}
+
+func (m *BookNewCargoRequest) GetDestination() string {
+	if m != nil {
+		return m.Destination
+	}
+	return ""
+}
+
+func (m *BookNewCargoRequest) GetArrivalDeadline() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.ArrivalDeadline
+	}
+	return nil
+}
+
+type BookNewCargoResponse struct {
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+}
+
+func (m *BookNewCargoResponse) Reset()                    { *m = BookNewCargoResponse{} }
+func (m *BookNewCargoResponse) String() string            { return proto.CompactTextString(m) }
+func (*BookNewCargoResponse) ProtoMessage()               {}
+func (*BookNewCargoResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *BookNewCargoResponse) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+type UnbookCargoRequest struct {
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+}
+
+func (m *UnbookCargoRequest) Reset()                    { *m = UnbookCargoRequest{} }
+func (m *UnbookCargoRequest) String() string            { return proto.CompactTextString(m) }
+func (*UnbookCargoRequest) ProtoMessage()               {}
+func (*UnbookCargoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *UnbookCargoRequest) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+type UnbookCargoResponse struct {
+}
+
+func (m *UnbookCargoResponse) Reset()                    { *m = UnbookCargoResponse{} }
+func (m *UnbookCargoResponse) String() string            { return proto.CompactTextString(m) }
+func (*UnbookCargoResponse) ProtoMessage()               {}
+func (*UnbookCargoResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+type LoadCargoRequest struct {
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+}
+
+func (m *LoadCargoRequest) Reset()                    { *m = LoadCargoRequest{} }
+func (m *LoadCargoRequest) String() string            { return proto.CompactTextString(m) }
+func (*LoadCargoRequest) ProtoMessage()               {}
+func (*LoadCargoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *LoadCargoRequest) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+type LoadCargoResponse struct {
+	Cargo *Cargo `protobuf:"bytes,1,opt,name=cargo" json:"cargo,omitempty"`
+}
+
+func (m *LoadCargoResponse) Reset()                    { *m = LoadCargoResponse{} }
+func (m *LoadCargoResponse) String() string            { return proto.CompactTextString(m) }
+func (*LoadCargoResponse) ProtoMessage()               {}
+func (*LoadCargoResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *LoadCargoResponse) GetCargo() *Cargo {
+	if m != nil {
+		return m.Cargo
+	}
+	return nil
+}
+
+type RequestPossibleRoutesRequest struct {
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+}
+
+func (m *RequestPossibleRoutesRequest) Reset()                    { *m = RequestPossibleRoutesRequest{} }
+func (m *RequestPossibleRoutesRequest) String() string            { return proto.CompactTextString(m) }
+func (*RequestPossibleRoutesRequest) ProtoMessage()               {}
+func (*RequestPossibleRoutesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *RequestPossibleRoutesRequest) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+type RequestPossibleRoutesResponse struct {
+	Routes []*Itinerary `protobuf:"bytes,1,rep,name=routes" json:"routes,omitempty"`
+}
+
+func (m *RequestPossibleRoutesResponse) Reset()                    { *m = RequestPossibleRoutesResponse{} }
+func (m *RequestPossibleRoutesResponse) String() string            { return proto.CompactTextString(m) }
+func (*RequestPossibleRoutesResponse) ProtoMessage()               {}
+func (*RequestPossibleRoutesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *RequestPossibleRoutesResponse) GetRoutes() []*Itinerary {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+type AssignCargoToRouteRequest struct {
+	TrackingId string     `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+	Itinerary  *Itinerary `protobuf:"bytes,2,opt,name=itinerary" json:"itinerary,omitempty"`
+}
+
+func (m *AssignCargoToRouteRequest) Reset()                    { *m = AssignCargoToRouteRequest{} }
+func (m *AssignCargoToRouteRequest) String() string            { return proto.CompactTextString(m) }
+func (*AssignCargoToRouteRequest) ProtoMessage()               {}
+func (*AssignCargoToRouteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *AssignCargoToRouteRequest) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+func (m *AssignCargoToRouteRequest) GetItinerary() *Itinerary {
+	if m != nil {
+		return m.Itinerary
+	}
+	return nil
+}
+
+type AssignCargoToRouteResponse struct {
+}
+
+func (m *AssignCargoToRouteResponse) Reset()                    { *m = AssignCargoToRouteResponse{} }
+func (m *AssignCargoToRouteResponse) String() string            { return proto.CompactTextString(m) }
+func (*AssignCargoToRouteResponse) ProtoMessage()               {}
+func (*AssignCargoToRouteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+type ChangeDestinationRequest struct {
+	TrackingId  string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+	Destination string `protobuf:"bytes,2,opt,name=destination" json:"destination,omitempty"`
+}
+
+func (m *ChangeDestinationRequest) Reset()                    { *m = ChangeDestinationRequest{} }
+func (m *ChangeDestinationRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChangeDestinationRequest) ProtoMessage()               {}
+func (*ChangeDestinationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ChangeDestinationRequest) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+func (m *ChangeDestinationRequest) GetDestination() string {
+	if m != nil {
+		return m.Destination
+	}
+	return ""
+}
+
+type ChangeDestinationResponse struct {
+}
+
+func (m *ChangeDestinationResponse) Reset()                    { *m = ChangeDestinationResponse{} }
+func (m *ChangeDestinationResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChangeDestinationResponse) ProtoMessage()               {}
+func (*ChangeDestinationResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+type ListCargosRequest struct {
+}
+
+func (m *ListCargosRequest) Reset()                    { *m = ListCargosRequest{} }
+func (m *ListCargosRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListCargosRequest) ProtoMessage()               {}
+func (*ListCargosRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+type ListCargosResponse struct {
+	Cargos []*Cargo `protobuf:"bytes,1,rep,name=cargos" json:"cargos,omitempty"`
+}
+
+func (m *ListCargosResponse) Reset()                    { *m = ListCargosResponse{} }
+func (m *ListCargosResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListCargosResponse) ProtoMessage()               {}
+func (*ListCargosResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *ListCargosResponse) GetCargos() []*Cargo {
+	if m != nil {
+		return m.Cargos
+	}
+	return nil
+}
+
+type ListLocationsRequest struct {
+}
+
+func (m *ListLocationsRequest) Reset()                    { *m = ListLocationsRequest{} }
+func (m *ListLocationsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListLocationsRequest) ProtoMessage()               {}
+func (*ListLocationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+type ListLocationsResponse struct {
+	Locations []*Location `protobuf:"bytes,1,rep,name=locations" json:"locations,omitempty"`
+}
+
+func (m *ListLocationsResponse) Reset()                    { *m = ListLocationsResponse{} }
+func (m *ListLocationsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListLocationsResponse) ProtoMessage()               {}
+func (*ListLocationsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *ListLocationsResponse) GetLocations() []*Location {
+	if m != nil {
+		return m.Locations
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Cargo)(nil), "goddd.booking.v1.Cargo")
+	proto.RegisterType((*Leg)(nil), "goddd.booking.v1.Leg")
+	proto.RegisterType((*Itinerary)(nil), "goddd.booking.v1.Itinerary")
+	proto.RegisterType((*Location)(nil), "goddd.booking.v1.Location")
+	proto.RegisterType((*BookNewCargoRequest)(nil), "goddd.booking.v1.BookNewCargoRequest")
+	proto.RegisterType((*BookNewCargoResponse)(nil), "goddd.booking.v1.BookNewCargoResponse")
+	proto.RegisterType((*UnbookCargoRequest)(nil), "goddd.booking.v1.UnbookCargoRequest")
+	proto.RegisterType((*UnbookCargoResponse)(nil), "goddd.booking.v1.UnbookCargoResponse")
+	proto.RegisterType((*LoadCargoRequest)(nil), "goddd.booking.v1.LoadCargoRequest")
+	proto.RegisterType((*LoadCargoResponse)(nil), "goddd.booking.v1.LoadCargoResponse")
+	proto.RegisterType((*RequestPossibleRoutesRequest)(nil), "goddd.booking.v1.RequestPossibleRoutesRequest")
+	proto.RegisterType((*RequestPossibleRoutesResponse)(nil), "goddd.booking.v1.RequestPossibleRoutesResponse")
+	proto.RegisterType((*AssignCargoToRouteRequest)(nil), "goddd.booking.v1.AssignCargoToRouteRequest")
+	proto.RegisterType((*AssignCargoToRouteResponse)(nil), "goddd.booking.v1.AssignCargoToRouteResponse")
+	proto.RegisterType((*ChangeDestinationRequest)(nil), "goddd.booking.v1.ChangeDestinationRequest")
+	proto.RegisterType((*ChangeDestinationResponse)(nil), "goddd.booking.v1.ChangeDestinationResponse")
+	proto.RegisterType((*ListCargosRequest)(nil), "goddd.booking.v1.ListCargosRequest")
+	proto.RegisterType((*ListCargosResponse)(nil), "goddd.booking.v1.ListCargosResponse")
+	proto.RegisterType((*ListLocationsRequest)(nil), "goddd.booking.v1.ListLocationsRequest")
+	proto.RegisterType((*ListLocationsResponse)(nil), "goddd.booking.v1.ListLocationsResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for BookingService service
+
+type BookingServiceClient interface {
+	// BookNewCargo registers a new cargo in the tracking system, not yet
+	// routed.
+	BookNewCargo(ctx context.Context, in *BookNewCargoRequest, opts ...grpc.CallOption) (*BookNewCargoResponse, error)
+	// UnbookCargo removes a cargo that has not yet been handled.
+	UnbookCargo(ctx context.Context, in *UnbookCargoRequest, opts ...grpc.CallOption) (*UnbookCargoResponse, error)
+	// LoadCargo returns a read model of a cargo.
+	LoadCargo(ctx context.Context, in *LoadCargoRequest, opts ...grpc.CallOption) (*LoadCargoResponse, error)
+	// RequestPossibleRoutes requests a list of itineraries describing possible
+	// routes for the cargo.
+	RequestPossibleRoutes(ctx context.Context, in *RequestPossibleRoutesRequest, opts ...grpc.CallOption) (*RequestPossibleRoutesResponse, error)
+	// AssignCargoToRoute assigns a cargo to the route specified by the
+	// itinerary.
+	AssignCargoToRoute(ctx context.Context, in *AssignCargoToRouteRequest, opts ...grpc.CallOption) (*AssignCargoToRouteResponse, error)
+	// ChangeDestination changes the destination of a cargo.
+	ChangeDestination(ctx context.Context, in *ChangeDestinationRequest, opts ...grpc.CallOption) (*ChangeDestinationResponse, error)
+	// ListCargos returns a list of all cargos that have been booked.
+	ListCargos(ctx context.Context, in *ListCargosRequest, opts ...grpc.CallOption) (*ListCargosResponse, error)
+	// ListLocations returns a list of registered locations.
+	ListLocations(ctx context.Context, in *ListLocationsRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error)
+}
+
+type bookingServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBookingServiceClient(cc *grpc.ClientConn) BookingServiceClient {
+	return &bookingServiceClient{cc}
+}
+
+func (c *bookingServiceClient) BookNewCargo(ctx context.Context, in *BookNewCargoRequest, opts ...grpc.CallOption) (*BookNewCargoResponse, error) {
+	out := new(BookNewCargoResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/BookNewCargo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) UnbookCargo(ctx context.Context, in *UnbookCargoRequest, opts ...grpc.CallOption) (*UnbookCargoResponse, error) {
+	out := new(UnbookCargoResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/UnbookCargo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) LoadCargo(ctx context.Context, in *LoadCargoRequest, opts ...grpc.CallOption) (*LoadCargoResponse, error) {
+	out := new(LoadCargoResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/LoadCargo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) RequestPossibleRoutes(ctx context.Context, in *RequestPossibleRoutesRequest, opts ...grpc.CallOption) (*RequestPossibleRoutesResponse, error) {
+	out := new(RequestPossibleRoutesResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/RequestPossibleRoutes", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) AssignCargoToRoute(ctx context.Context, in *AssignCargoToRouteRequest, opts ...grpc.CallOption) (*AssignCargoToRouteResponse, error) {
+	out := new(AssignCargoToRouteResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/AssignCargoToRoute", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) ChangeDestination(ctx context.Context, in *ChangeDestinationRequest, opts ...grpc.CallOption) (*ChangeDestinationResponse, error) {
+	out := new(ChangeDestinationResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/ChangeDestination", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) ListCargos(ctx context.Context, in *ListCargosRequest, opts ...grpc.CallOption) (*ListCargosResponse, error) {
+	out := new(ListCargosResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/ListCargos", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) ListLocations(ctx context.Context, in *ListLocationsRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error) {
+	out := new(ListLocationsResponse)
+	err := grpc.Invoke(ctx, "/goddd.booking.v1.BookingService/ListLocations", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for BookingService service
+
+type BookingServiceServer interface {
+	// BookNewCargo registers a new cargo in the tracking system, not yet
+	// routed.
+	BookNewCargo(context.Context, *BookNewCargoRequest) (*BookNewCargoResponse, error)
+	// UnbookCargo removes a cargo that has not yet been handled.
+	UnbookCargo(context.Context, *UnbookCargoRequest) (*UnbookCargoResponse, error)
+	// LoadCargo returns a read model of a cargo.
+	LoadCargo(context.Context, *LoadCargoRequest) (*LoadCargoResponse, error)
+	// RequestPossibleRoutes requests a list of itineraries describing possible
+	// routes for the cargo.
+	RequestPossibleRoutes(context.Context, *RequestPossibleRoutesRequest) (*RequestPossibleRoutesResponse, error)
+	// AssignCargoToRoute assigns a cargo to the route specified by the
+	// itinerary.
+	AssignCargoToRoute(context.Context, *AssignCargoToRouteRequest) (*AssignCargoToRouteResponse, error)
+	// ChangeDestination changes the destination of a cargo.
+	ChangeDestination(context.Context, *ChangeDestinationRequest) (*ChangeDestinationResponse, error)
+	// ListCargos returns a list of all cargos that have been booked.
+	ListCargos(context.Context, *ListCargosRequest) (*ListCargosResponse, error)
+	// ListLocations returns a list of registered locations.
+	ListLocations(context.Context, *ListLocationsRequest) (*ListLocationsResponse, error)
+}
+
+func RegisterBookingServiceServer(s *grpc.Server, srv BookingServiceServer) {
+	s.RegisterService(&_BookingService_serviceDesc, srv)
+}
+
+func _BookingService_BookNewCargo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookNewCargoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).BookNewCargo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/BookNewCargo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).BookNewCargo(ctx, req.(*BookNewCargoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_UnbookCargo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbookCargoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).UnbookCargo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/UnbookCargo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).UnbookCargo(ctx, req.(*UnbookCargoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_LoadCargo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoadCargoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).LoadCargo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/LoadCargo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).LoadCargo(ctx, req.(*LoadCargoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_RequestPossibleRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPossibleRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).RequestPossibleRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/RequestPossibleRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).RequestPossibleRoutes(ctx, req.(*RequestPossibleRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_AssignCargoToRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignCargoToRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).AssignCargoToRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/AssignCargoToRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).AssignCargoToRoute(ctx, req.(*AssignCargoToRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_ChangeDestination_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeDestinationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).ChangeDestination(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/ChangeDestination",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).ChangeDestination(ctx, req.(*ChangeDestinationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_ListCargos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCargosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).ListCargos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/ListCargos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).ListCargos(ctx, req.(*ListCargosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_ListLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).ListLocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.booking.v1.BookingService/ListLocations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).ListLocations(ctx, req.(*ListLocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _BookingService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "goddd.booking.v1.BookingService",
+	HandlerType: (*BookingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BookNewCargo",
+			Handler:    _BookingService_BookNewCargo_Handler,
+		},
+		{
+			MethodName: "UnbookCargo",
+			Handler:    _BookingService_UnbookCargo_Handler,
+		},
+		{
+			MethodName: "LoadCargo",
+			Handler:    _BookingService_LoadCargo_Handler,
+		},
+		{
+			MethodName: "RequestPossibleRoutes",
+			Handler:    _BookingService_RequestPossibleRoutes_Handler,
+		},
+		{
+			MethodName: "AssignCargoToRoute",
+			Handler:    _BookingService_AssignCargoToRoute_Handler,
+		},
+		{
+			MethodName: "ChangeDestination",
+			Handler:    _BookingService_ChangeDestination_Handler,
+		},
+		{
+			MethodName: "ListCargos",
+			Handler:    _BookingService_ListCargos_Handler,
+		},
+		{
+			MethodName: "ListLocations",
+			Handler:    _BookingService_ListLocations_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "booking.proto",
+}
+
+func init() { proto.RegisterFile("booking.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 842 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xed, 0x8e, 0xdb, 0x44,
+	0x14, 0x95, 0xf3, 0x45, 0x7c, 0xbd, 0x5b, 0x76, 0x67, 0x9b, 0xc5, 0x75, 0x17, 0x35, 0x72, 0x3f,
+	0x08, 0xb4, 0x38, 0x22, 0x2b, 0x5a, 0x2a, 0x84, 0x10, 0x69, 0xf7, 0x47, 0xa5, 0xa8, 0x02, 0x13,
+	0x84, 0x54, 0x69, 0x15, 0x26, 0xf1, 0xac, 0x19, 0xad, 0xe3, 0x09, 0xf6, 0x24, 0xcb, 0xbe, 0x08,
+	0x0f, 0x82, 0xc4, 0xbb, 0xf0, 0x38, 0xd5, 0x8c, 0xc7, 0xf9, 0xb2, 0x13, 0x7b, 0xff, 0x79, 0xee,
+	0x9c, 0x73, 0xef, 0xcd, 0xbd, 0xe7, 0x4c, 0xe0, 0x70, 0xcc, 0xd8, 0x35, 0x0d, 0x7d, 0x67, 0x16,
+	0x31, 0xce, 0xd0, 0x91, 0xcf, 0x3c, 0xcf, 0x73, 0xd2, 0xe0, 0xe2, 0x1b, 0xeb, 0x91, 0xcf, 0x98,
+	0x1f, 0x90, 0xae, 0xbc, 0x1f, 0xcf, 0xaf, 0xba, 0x9c, 0x4e, 0x49, 0xcc, 0xf1, 0x74, 0x96, 0x50,
+	0xec, 0x7f, 0xab, 0x50, 0x7f, 0x83, 0x23, 0x9f, 0xa1, 0x47, 0x60, 0xf0, 0x08, 0x4f, 0x04, 0x73,
+	0x44, 0x3d, 0x53, 0x6b, 0x6b, 0x1d, 0xdd, 0x85, 0x34, 0xf4, 0xce, 0x43, 0xa7, 0xd0, 0x60, 0x11,
+	0xf5, 0x69, 0x68, 0x56, 0xe4, 0x9d, 0x3a, 0xa1, 0x36, 0x18, 0x1e, 0x89, 0x39, 0x0d, 0x31, 0xa7,
+	0x2c, 0x34, 0xab, 0xf2, 0x72, 0x3d, 0x84, 0x2e, 0xe0, 0x08, 0x47, 0x11, 0x5d, 0xe0, 0x60, 0xe4,
+	0x11, 0xec, 0x05, 0x34, 0x24, 0x66, 0xad, 0xad, 0x75, 0x8c, 0x9e, 0xe5, 0x24, 0x0d, 0x3a, 0x69,
+	0x83, 0xce, 0x30, 0x6d, 0xd0, 0xfd, 0x54, 0x71, 0xde, 0x2a, 0x0a, 0x3a, 0x03, 0x7d, 0x4a, 0xe3,
+	0x88, 0xcd, 0x39, 0xf1, 0xcc, 0x7a, 0x5b, 0xeb, 0x34, 0xdd, 0x55, 0x40, 0xb4, 0xa7, 0xae, 0x1a,
+	0xf2, 0x4a, 0x9d, 0xd0, 0x97, 0x50, 0x0b, 0x88, 0x1f, 0x9b, 0x9f, 0xb4, 0xab, 0x1d, 0xa3, 0xd7,
+	0x72, 0xb6, 0x67, 0xe4, 0x0c, 0x88, 0xef, 0x4a, 0x08, 0x7a, 0x01, 0x55, 0xc2, 0xb1, 0xd9, 0x2c,
+	0x6c, 0x4d, 0xc0, 0xd0, 0x0f, 0x70, 0x40, 0x38, 0x1e, 0x11, 0x1c, 0x05, 0x94, 0xc4, 0xdc, 0xd4,
+	0x0b, 0x69, 0x06, 0xe1, 0xf8, 0x42, 0xc1, 0xd1, 0x6b, 0x00, 0x41, 0x0f, 0x30, 0x17, 0x64, 0x28,
+	0x24, 0xeb, 0x84, 0xe3, 0x81, 0x04, 0xdb, 0xff, 0x6b, 0x50, 0x1d, 0x10, 0x1f, 0x3d, 0x86, 0xc3,
+	0x05, 0xbb, 0xc5, 0x3e, 0x19, 0x85, 0xf3, 0xe9, 0x98, 0x44, 0x6a, 0x69, 0x07, 0x49, 0xf0, 0xbd,
+	0x8c, 0x21, 0x04, 0xb5, 0xab, 0x88, 0x4d, 0xd5, 0xd2, 0xe4, 0x37, 0xba, 0x07, 0x15, 0xce, 0xd4,
+	0xa6, 0x2a, 0x9c, 0xa1, 0x57, 0xa0, 0x07, 0x0c, 0x7b, 0x23, 0xa1, 0x8e, 0x12, 0x9b, 0x69, 0x0a,
+	0xb0, 0x38, 0xa2, 0xef, 0xc1, 0x98, 0x87, 0x2b, 0x6a, 0xbd, 0x90, 0x0a, 0x09, 0x5c, 0x92, 0x11,
+	0xd4, 0xa6, 0xcc, 0x23, 0x72, 0x5f, 0xba, 0x2b, 0xbf, 0xed, 0x97, 0xa0, 0xbf, 0xe3, 0x34, 0x24,
+	0x11, 0x8e, 0x6e, 0x97, 0xab, 0xd3, 0x0a, 0x57, 0x67, 0xbf, 0x84, 0xe6, 0x80, 0x4d, 0x12, 0xb9,
+	0x9d, 0x42, 0x23, 0x60, 0x13, 0x91, 0x39, 0x99, 0x87, 0x3a, 0x89, 0x7a, 0x21, 0x9e, 0x92, 0x74,
+	0x12, 0xe2, 0xdb, 0xfe, 0x47, 0x83, 0x93, 0x3e, 0x63, 0xd7, 0xef, 0xc9, 0x8d, 0xb4, 0x81, 0x4b,
+	0xfe, 0x9a, 0x8b, 0xed, 0xac, 0xc4, 0xae, 0xed, 0x13, 0x7b, 0xa5, 0x9c, 0xd8, 0xab, 0x77, 0x16,
+	0xbb, 0xfd, 0x0a, 0xee, 0x6f, 0xf6, 0x15, 0xcf, 0x58, 0x18, 0x93, 0x42, 0x9b, 0xda, 0xdf, 0x02,
+	0xfa, 0x2d, 0x14, 0x33, 0xda, 0xf8, 0x3d, 0x85, 0xb4, 0x16, 0x9c, 0x6c, 0xd0, 0x92, 0x72, 0xf6,
+	0x39, 0x1c, 0x0d, 0x18, 0xf6, 0xee, 0x96, 0xab, 0x0f, 0xc7, 0x6b, 0x24, 0xd5, 0xf8, 0xd7, 0x50,
+	0x9f, 0x88, 0x80, 0xc4, 0x1b, 0xbd, 0xcf, 0xb2, 0xdb, 0x4c, 0xf0, 0x09, 0xca, 0xfe, 0x11, 0xce,
+	0x54, 0xbd, 0x9f, 0x59, 0x1c, 0xd3, 0x71, 0x40, 0x5c, 0xe1, 0xe7, 0xb8, 0x74, 0x13, 0x43, 0xf8,
+	0x7c, 0x47, 0x02, 0xd5, 0xd0, 0xb9, 0x7a, 0x30, 0x52, 0x7d, 0x3d, 0xcc, 0x76, 0xb4, 0x94, 0xa2,
+	0x7a, 0x4d, 0x62, 0xfb, 0x06, 0x1e, 0xfc, 0x14, 0xc7, 0xd4, 0x0f, 0x65, 0xb3, 0x43, 0x26, 0x73,
+	0x96, 0xed, 0x09, 0xbd, 0x06, 0x9d, 0xa6, 0x29, 0xa5, 0x76, 0x0a, 0xaa, 0xae, 0xd0, 0xf6, 0x19,
+	0x58, 0x79, 0x85, 0xd5, 0x9a, 0x2e, 0xc1, 0x7c, 0xf3, 0x27, 0x0e, 0x7d, 0xf2, 0x76, 0xa5, 0xc4,
+	0xd2, 0x5d, 0x15, 0x6a, 0xda, 0x7e, 0x08, 0x0f, 0x72, 0xd2, 0xab, 0xda, 0x27, 0x70, 0x3c, 0xa0,
+	0x31, 0x97, 0x7d, 0xa5, 0xeb, 0xb1, 0x2f, 0x00, 0xad, 0x07, 0xd5, 0xc8, 0xbb, 0xd0, 0x90, 0xdb,
+	0x4d, 0x47, 0xbe, 0x53, 0x04, 0x0a, 0x66, 0x9f, 0xc2, 0x7d, 0x91, 0x26, 0xb5, 0xf6, 0x32, 0xfd,
+	0x2f, 0xd0, 0xda, 0x8a, 0xab, 0x0a, 0xdf, 0x89, 0x97, 0x4c, 0x05, 0x55, 0x11, 0x2b, 0xe7, 0xdd,
+	0x50, 0x10, 0x77, 0x05, 0xee, 0xfd, 0xd7, 0x80, 0x7b, 0xfd, 0x04, 0xf2, 0x2b, 0x89, 0x16, 0x74,
+	0x42, 0xd0, 0x25, 0x1c, 0xac, 0x7b, 0x10, 0x3d, 0xcd, 0x66, 0xca, 0x79, 0x3b, 0xac, 0x67, 0x45,
+	0x30, 0xd5, 0xeb, 0x07, 0x30, 0xd6, 0x2c, 0x87, 0x9e, 0x64, 0x69, 0x59, 0x23, 0x5b, 0x4f, 0x0b,
+	0x50, 0x2a, 0xf7, 0x10, 0xf4, 0xa5, 0x05, 0x91, 0x9d, 0x37, 0x81, 0x4d, 0x53, 0x5b, 0x8f, 0xf7,
+	0x62, 0x54, 0xd6, 0xbf, 0xa1, 0x95, 0xeb, 0x29, 0xe4, 0x64, 0xd9, 0xfb, 0xdc, 0x6b, 0x75, 0x4b,
+	0xe3, 0x55, 0x65, 0x06, 0x28, 0x2b, 0x7f, 0xf4, 0x3c, 0x9b, 0x66, 0xa7, 0x3b, 0xad, 0x17, 0xe5,
+	0xc0, 0xaa, 0x60, 0x00, 0xc7, 0x19, 0xc9, 0xa3, 0xaf, 0x72, 0xf4, 0xba, 0xc3, 0x76, 0xd6, 0xf3,
+	0x52, 0x58, 0x55, 0xed, 0x77, 0x80, 0x95, 0x5d, 0x50, 0xde, 0x2e, 0xb6, 0x1d, 0x66, 0x3d, 0xd9,
+	0x0f, 0x52, 0x89, 0xff, 0x80, 0xc3, 0x0d, 0xa3, 0xa0, 0x67, 0xf9, 0xb4, 0x6d, 0x87, 0x59, 0x5f,
+	0x14, 0xe2, 0x92, 0x0a, 0xfd, 0xda, 0x87, 0xca, 0x6c, 0x3c, 0x6e, 0xc8, 0xff, 0xb4, 0xf3, 0x8f,
+	0x03, 0x00, 0xa1, 0x97, 0x70, 0x8c, 0x92, 0x0a, 0x00, 0x00,
+}
@@ -3,14 +3,16 @@
 package booking
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"math/rand"
 	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/eta"
 	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/token"
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
@@ -21,30 +23,39 @@ var r = rand.New(rand.NewSource(99))
 type Service interface {
 	// BookNewCargo registers a new cargo in the tracking system, not yet
 	// routed.
-	BookNewCargo(origin location.UNLocode, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error)
+	BookNewCargo(ctx context.Context, origin location.UNLocode, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error)
 
 	// Deletes existing Cargo
-	UnbookCargo(cargo.TrackingID) error
+	UnbookCargo(ctx context.Context, id cargo.TrackingID) error
 
 	// LoadCargo returns a read model of a cargo.
-	LoadCargo(id cargo.TrackingID) (Cargo, error)
+	LoadCargo(ctx context.Context, id cargo.TrackingID) (Cargo, error)
 
 	// RequestPossibleRoutesForCargo requests a list of itineraries describing
-	// possible routes for this cargo.
-	RequestPossibleRoutesForCargo(id cargo.TrackingID) []cargo.Itinerary
+	// possible routes for this cargo. It fails with an error wrapping
+	// routing.ErrUnavailable if the routing service cannot be reached.
+	RequestPossibleRoutesForCargo(ctx context.Context, id cargo.TrackingID) ([]cargo.Itinerary, error)
 
 	// AssignCargoToRoute assigns a cargo to the route specified by the
 	// itinerary.
-	AssignCargoToRoute(id cargo.TrackingID, itinerary cargo.Itinerary) error
+	AssignCargoToRoute(ctx context.Context, id cargo.TrackingID, itinerary cargo.Itinerary) error
 
 	// ChangeDestination changes the destination of a cargo.
-	ChangeDestination(id cargo.TrackingID, destination location.UNLocode) error
+	ChangeDestination(ctx context.Context, id cargo.TrackingID, destination location.UNLocode) error
 
 	// Cargos returns a list of all cargos that have been booked.
-	Cargos() []Cargo
+	Cargos(ctx context.Context) ([]Cargo, error)
 
 	// Locations returns a list of registered locations.
-	Locations() []Location
+	Locations(ctx context.Context) ([]Location, error)
+
+	// IssueTrackingToken issues a token for tracking a cargo publicly,
+	// without an account.
+	IssueTrackingToken(ctx context.Context, id cargo.TrackingID) (TrackingToken, error)
+
+	// RevokeTrackingTokens revokes all tracking tokens issued for a cargo so
+	// far.
+	RevokeTrackingTokens(ctx context.Context, id cargo.TrackingID) error
 }
 
 type service struct {
@@ -52,25 +63,26 @@ type service struct {
 	locations      location.Repository
 	handlingEvents cargo.HandlingEventRepository
 	routingService routing.Service
+	tokens         *token.Issuer
+	estimator      *eta.Estimator
 }
 
-func (s *service) AssignCargoToRoute(id cargo.TrackingID, itinerary cargo.Itinerary) error {
+func (s *service) AssignCargoToRoute(ctx context.Context, id cargo.TrackingID, itinerary cargo.Itinerary) error {
 	if id == "" || len(itinerary.Legs) == 0 {
 		return ErrInvalidArgument
 	}
 
-	c, err := s.cargos.Find(id)
+	c, err := s.cargos.Find(ctx, id)
 	if err != nil {
-		fmt.Printf("Unable to find cargo %s in assigning cargo to route, error: \n", id, err.Error())
 		return err
 	}
 
 	c.AssignToRoute(itinerary)
 
-	return s.cargos.Store(c)
+	return s.cargos.Store(ctx, c)
 }
 
-func (s *service) BookNewCargo(origin, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error) {
+func (s *service) BookNewCargo(ctx context.Context, origin, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error) {
 	if origin == "" || destination == "" || deadline.IsZero() {
 		return "", ErrInvalidArgument
 	}
@@ -84,44 +96,52 @@ func (s *service) BookNewCargo(origin, destination location.UNLocode, deadline t
 
 	c := cargo.New(id, rs)
 
-	if err := s.cargos.Store(c); err != nil {
+	if err := s.cargos.Store(ctx, c); err != nil {
 		return "", err
 	}
 
 	return c.TrackingID, nil
 }
 
-func (s *service) UnbookCargo(id cargo.TrackingID) error {
+func (s *service) UnbookCargo(ctx context.Context, id cargo.TrackingID) error {
 	rs := cargo.RouteSpecification{}
 	c := cargo.New(id, rs)
 
-	return s.cargos.Remove(c)
+	return s.cargos.Remove(ctx, c)
 }
 
-func (s *service) LoadCargo(id cargo.TrackingID) (Cargo, error) {
+func (s *service) LoadCargo(ctx context.Context, id cargo.TrackingID) (Cargo, error) {
 	if id == "" {
 		return Cargo{}, ErrInvalidArgument
 	}
 
-	c, err := s.cargos.Find(id)
+	c, err := s.cargos.Find(ctx, id)
 	if err != nil {
 		return Cargo{}, err
 	}
 
-	return assemble(c, s.handlingEvents), nil
+	var h cargo.HandlingHistory
+	if c.Delivery.IsOnTrack() {
+		h, err = s.handlingEvents.QueryHandlingHistory(ctx, id)
+		if err != nil {
+			return Cargo{}, err
+		}
+	}
+
+	return assemble(c, h, s.estimator), nil
 }
 
-func (s *service) ChangeDestination(id cargo.TrackingID, destination location.UNLocode) error {
+func (s *service) ChangeDestination(ctx context.Context, id cargo.TrackingID, destination location.UNLocode) error {
 	if id == "" || destination == "" {
 		return ErrInvalidArgument
 	}
 
-	c, err := s.cargos.Find(id)
+	c, err := s.cargos.Find(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	l, err := s.locations.Find(destination)
+	l, err := s.locations.Find(ctx, destination)
 	if err != nil {
 		return err
 	}
@@ -132,53 +152,110 @@ func (s *service) ChangeDestination(id cargo.TrackingID, destination location.UN
 		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
 	})
 
-	if err := s.cargos.Store(c); err != nil {
+	if err := s.cargos.Store(ctx, c); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *service) RequestPossibleRoutesForCargo(id cargo.TrackingID) []cargo.Itinerary {
+func (s *service) RequestPossibleRoutesForCargo(ctx context.Context, id cargo.TrackingID) ([]cargo.Itinerary, error) {
 	if id == "" {
-		return nil
+		return nil, ErrInvalidArgument
 	}
 
-	c, err := s.cargos.Find(id)
+	c, err := s.cargos.Find(ctx, id)
 	if err != nil {
-		fmt.Printf("Unable to find cargo %s in RequestPossibleRoutesForCargo, error: %s\n", id, err.Error())
-		return []cargo.Itinerary{}
+		return nil, err
 	}
 
-	return s.routingService.FetchRoutesForSpecification(c.RouteSpecification)
+	return s.routingService.FetchRoutesForSpecification(ctx, c.RouteSpecification)
 }
 
-func (s *service) Cargos() []Cargo {
+func (s *service) Cargos(ctx context.Context) ([]Cargo, error) {
+	cs, err := s.cargos.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only cargos on track have an estimated time of arrival, so only their
+	// handling histories are needed.
+	var ids []cargo.TrackingID
+	for _, c := range cs {
+		if c.Delivery.IsOnTrack() {
+			ids = append(ids, c.TrackingID)
+		}
+	}
+
+	var histories map[cargo.TrackingID]cargo.HandlingHistory
+	if len(ids) > 0 {
+		histories, err = s.handlingEvents.QueryHandlingHistories(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var result []Cargo
-	for _, c := range s.cargos.FindAll() {
-		result = append(result, assemble(c, s.handlingEvents))
+	for _, c := range cs {
+		result = append(result, assemble(c, histories[c.TrackingID], s.estimator))
 	}
-	return result
+	return result, nil
 }
 
-func (s *service) Locations() []Location {
+func (s *service) Locations(ctx context.Context) ([]Location, error) {
+	ls, err := s.locations.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []Location
-	for _, v := range s.locations.FindAll() {
+	for _, v := range ls {
 		result = append(result, Location{
 			UNLocode: string(v.UNLocode),
 			Name:     v.Name,
 		})
 	}
-	return result
+	return result, nil
+}
+
+func (s *service) IssueTrackingToken(ctx context.Context, id cargo.TrackingID) (TrackingToken, error) {
+	if id == "" {
+		return TrackingToken{}, ErrInvalidArgument
+	}
+
+	if _, err := s.cargos.Find(ctx, id); err != nil {
+		return TrackingToken{}, err
+	}
+
+	tok, t := s.tokens.Issue(id)
+
+	return TrackingToken{
+		Token:   tok,
+		Expires: t.Expires,
+	}, nil
+}
+
+func (s *service) RevokeTrackingTokens(ctx context.Context, id cargo.TrackingID) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	if _, err := s.cargos.Find(ctx, id); err != nil {
+		return err
+	}
+
+	return s.tokens.Revoke(ctx, id)
 }
 
 // NewService creates a booking service with necessary dependencies.
-func NewService(cargos cargo.Repository, locations location.Repository, events cargo.HandlingEventRepository, rs routing.Service) Service {
+func NewService(cargos cargo.Repository, locations location.Repository, events cargo.HandlingEventRepository, rs routing.Service, tokens *token.Issuer, estimator *eta.Estimator) Service {
 	return &service{
 		cargos:         cargos,
 		locations:      locations,
 		handlingEvents: events,
 		routingService: rs,
+		tokens:         tokens,
+		estimator:      estimator,
 	}
 }
 
@@ -197,9 +274,25 @@ type Cargo struct {
 	Origin          string      `json:"origin"`
 	Routed          bool        `json:"routed"`
 	TrackingID      string      `json:"tracking_id"`
+
+	// ETA is the estimated time of arrival, and ETAEarliest and ETALatest
+	// bound the interval the cargo arrives within with 90% confidence. They
+	// are zero for cargos that are not on track.
+	ETA         time.Time `json:"eta"`
+	ETAEarliest time.Time `json:"eta_earliest"`
+	ETALatest   time.Time `json:"eta_latest"`
+}
+
+// TrackingToken is a token for tracking a cargo publicly, without an
+// account.
+type TrackingToken struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
 }
 
-func assemble(c *cargo.Cargo, events cargo.HandlingEventRepository) Cargo {
+func assemble(c *cargo.Cargo, h cargo.HandlingHistory, estimator *eta.Estimator) Cargo {
+	est := estimator.Estimate(c, h)
+
 	return Cargo{
 		TrackingID:      string(c.TrackingID),
 		Origin:          string(c.Origin),
@@ -208,5 +301,8 @@ func assemble(c *cargo.Cargo, events cargo.HandlingEventRepository) Cargo {
 		Routed:          !c.Itinerary.IsEmpty(),
 		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
 		Legs:            c.Itinerary.Legs,
+		ETA:             est.ETA,
+		ETAEarliest:     est.Earliest,
+		ETALatest:       est.Latest,
 	}
 }
@@ -1,12 +1,16 @@
 package booking
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/token"
 )
 
 func TestBookNewCargo(t *testing.T) {
@@ -18,14 +22,14 @@ func TestBookNewCargo(t *testing.T) {
 
 	var cargos mockCargoRepository
 
-	s := NewService(&cargos, nil, nil, nil)
+	s := NewService(&cargos, nil, nil, nil, nil, nil)
 
-	id, err := s.BookNewCargo(origin, destination, deadline)
+	id, err := s.BookNewCargo(context.Background(), origin, destination, deadline)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	c, err := cargos.Find(id)
+	c, err := cargos.Find(context.Background(), id)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -48,14 +52,14 @@ func TestBookNewCargo(t *testing.T) {
 
 type stubRoutingService struct{}
 
-func (s *stubRoutingService) FetchRoutesForSpecification(rs cargo.RouteSpecification) []cargo.Itinerary {
+func (s *stubRoutingService) FetchRoutesForSpecification(_ context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
 	legs := []cargo.Leg{
 		{LoadLocation: rs.Origin, UnloadLocation: rs.Destination},
 	}
 
 	return []cargo.Itinerary{
 		{Legs: legs},
-	}
+	}, nil
 }
 
 func TestRequestPossibleRoutesForCargo(t *testing.T) {
@@ -69,32 +73,53 @@ func TestRequestPossibleRoutesForCargo(t *testing.T) {
 
 	var rs stubRoutingService
 
-	s := NewService(&cargos, nil, nil, &rs)
-
-	r := s.RequestPossibleRoutesForCargo("no_such_id")
+	s := NewService(&cargos, nil, nil, &rs, nil, nil)
 
-	if len(r) != 0 {
-		t.Errorf("len(r) = %d; want = %d", len(r), 0)
+	if _, err := s.RequestPossibleRoutesForCargo(context.Background(), "no_such_id"); err != cargo.ErrUnknown {
+		t.Errorf("err = %v; want = %v", err, cargo.ErrUnknown)
 	}
 
-	id, err := s.BookNewCargo(origin, destination, deadline)
+	id, err := s.BookNewCargo(context.Background(), origin, destination, deadline)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	i := s.RequestPossibleRoutesForCargo(id)
+	i, err := s.RequestPossibleRoutesForCargo(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if len(i) != 1 {
 		t.Errorf("len(i) = %d; want = %d", len(i), 1)
 	}
 }
 
+func TestRequestPossibleRoutesForCargoUnavailable(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var rs mock.RoutingService
+	rs.FetchRoutesFn = func(context.Context, cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+		return nil, routing.ErrUnavailable
+	}
+
+	s := NewService(&cargos, nil, nil, &rs, nil, nil)
+
+	id, err := s.BookNewCargo(context.Background(), location.SESTO, location.AUMEL, time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RequestPossibleRoutesForCargo(context.Background(), id); err != routing.ErrUnavailable {
+		t.Errorf("err = %v; want = %v", err, routing.ErrUnavailable)
+	}
+}
+
 func TestAssignCargoToRoute(t *testing.T) {
 	var cargos mockCargoRepository
 
 	var rs stubRoutingService
 
-	s := NewService(&cargos, nil, nil, &rs)
+	s := NewService(&cargos, nil, nil, &rs, nil, nil)
 
 	var (
 		origin      = location.SESTO
@@ -102,22 +127,25 @@ func TestAssignCargoToRoute(t *testing.T) {
 		deadline    = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
 	)
 
-	id, err := s.BookNewCargo(origin, destination, deadline)
+	id, err := s.BookNewCargo(context.Background(), origin, destination, deadline)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	i := s.RequestPossibleRoutesForCargo(id)
+	i, err := s.RequestPossibleRoutesForCargo(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if len(i) != 1 {
 		t.Errorf("len(i) = %d; want = %d", len(i), 1)
 	}
 
-	if err := s.AssignCargoToRoute(id, i[0]); err != nil {
+	if err := s.AssignCargoToRoute(context.Background(), id, i[0]); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := s.AssignCargoToRoute("no_such_id", cargo.Itinerary{}); err != ErrInvalidArgument {
+	if err := s.AssignCargoToRoute(context.Background(), "no_such_id", cargo.Itinerary{}); err != ErrInvalidArgument {
 		t.Errorf("err = %s; want = %s", err, ErrInvalidArgument)
 	}
 }
@@ -126,7 +154,7 @@ func TestChangeCargoDestination(t *testing.T) {
 	var cargos mockCargoRepository
 	var locations mock.LocationRepository
 
-	locations.FindFn = func(loc location.UNLocode) (*location.Location, error) {
+	locations.FindFn = func(_ context.Context, loc location.UNLocode) (*location.Location, error) {
 		if loc != location.AUMEL {
 			return nil, location.ErrUnknown
 		}
@@ -135,7 +163,7 @@ func TestChangeCargoDestination(t *testing.T) {
 
 	var rs stubRoutingService
 
-	s := NewService(&cargos, &locations, nil, &rs)
+	s := NewService(&cargos, &locations, nil, &rs, nil, nil)
 
 	c := cargo.New("ABC", cargo.RouteSpecification{
 		Origin:          location.SESTO,
@@ -143,15 +171,15 @@ func TestChangeCargoDestination(t *testing.T) {
 		ArrivalDeadline: time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC),
 	})
 
-	if err := s.ChangeDestination("no_such_id", location.SESTO); err != cargo.ErrUnknown {
+	if err := s.ChangeDestination(context.Background(), "no_such_id", location.SESTO); err != cargo.ErrUnknown {
 		t.Errorf("err = %s; want = %s", err, cargo.ErrUnknown)
 	}
 
-	if err := cargos.Store(c); err != nil {
+	if err := cargos.Store(context.Background(), c); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := s.ChangeDestination(c.TrackingID, "no_such_unlocode"); err != location.ErrUnknown {
+	if err := s.ChangeDestination(context.Background(), c.TrackingID, "no_such_unlocode"); err != location.ErrUnknown {
 		t.Errorf("err = %s; want = %s", err, location.ErrUnknown)
 	}
 
@@ -160,11 +188,11 @@ func TestChangeCargoDestination(t *testing.T) {
 			c.RouteSpecification.Destination, location.CNHKG)
 	}
 
-	if err := s.ChangeDestination(c.TrackingID, location.AUMEL); err != nil {
+	if err := s.ChangeDestination(context.Background(), c.TrackingID, location.AUMEL); err != nil {
 		t.Fatal(err)
 	}
 
-	uc, err := cargos.Find(c.TrackingID)
+	uc, err := cargos.Find(context.Background(), c.TrackingID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -179,7 +207,7 @@ func TestLoadCargo(t *testing.T) {
 	deadline := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
 
 	var cargos mock.CargoRepository
-	cargos.FindFn = func(id cargo.TrackingID) (*cargo.Cargo, error) {
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 		return &cargo.Cargo{
 			TrackingID: "test_id",
 			Origin:     location.SESTO,
@@ -190,9 +218,9 @@ func TestLoadCargo(t *testing.T) {
 			},
 		}, nil
 	}
-	s := NewService(&cargos, nil, nil, nil)
+	s := NewService(&cargos, nil, nil, nil, nil, nil)
 
-	c, err := s.LoadCargo("test_id")
+	c, err := s.LoadCargo(context.Background(), "test_id")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -220,27 +248,84 @@ func TestLoadCargo(t *testing.T) {
 	}
 }
 
+func TestTrackingTokens(t *testing.T) {
+	ctx := context.Background()
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+		if id != "test_id" {
+			return nil, cargo.ErrUnknown
+		}
+		return &cargo.Cargo{TrackingID: id}, nil
+	}
+
+	tokens := token.NewIssuer([]byte("secret"), time.Hour, inmem.NewRevocationStore())
+
+	s := NewService(&cargos, nil, nil, nil, tokens, nil)
+
+	if _, err := s.IssueTrackingToken(ctx, "unknown"); err != cargo.ErrUnknown {
+		t.Errorf("err = %v; want = %v", err, cargo.ErrUnknown)
+	}
+
+	tt, err := s.IssueTrackingToken(ctx, "test_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tokens.Verify(ctx, tt.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TrackingID != "test_id" {
+		t.Errorf("got.TrackingID = %s; want = %s", got.TrackingID, "test_id")
+	}
+	if !got.Expires.Equal(tt.Expires) {
+		t.Errorf("got.Expires = %v; want = %v", got.Expires, tt.Expires)
+	}
+
+	if err := s.RevokeTrackingTokens(ctx, "unknown"); err != cargo.ErrUnknown {
+		t.Errorf("err = %v; want = %v", err, cargo.ErrUnknown)
+	}
+	if err := s.RevokeTrackingTokens(ctx, "test_id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tokens.Verify(ctx, tt.Token); err != token.ErrRevoked {
+		t.Errorf("err = %v; want = %v", err, token.ErrRevoked)
+	}
+}
+
 type mockCargoRepository struct {
 	cargo *cargo.Cargo
 }
 
-func (r *mockCargoRepository) Remove(cargo *cargo.Cargo) error {
+func (r *mockCargoRepository) Remove(_ context.Context, c *cargo.Cargo) error {
 	r.cargo = nil
 	return nil
 }
 
-func (r *mockCargoRepository) Store(c *cargo.Cargo) error {
+func (r *mockCargoRepository) Store(_ context.Context, c *cargo.Cargo) error {
 	r.cargo = c
 	return nil
 }
 
-func (r *mockCargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
+func (r *mockCargoRepository) Find(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 	if r.cargo != nil {
 		return r.cargo, nil
 	}
 	return nil, cargo.ErrUnknown
 }
 
-func (r *mockCargoRepository) FindAll() []*cargo.Cargo {
-	return []*cargo.Cargo{r.cargo}
+func (r *mockCargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	var cs []*cargo.Cargo
+	for _, id := range ids {
+		if c, err := r.Find(ctx, id); err == nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+func (r *mockCargoRepository) FindAll(_ context.Context) ([]*cargo.Cargo, error) {
+	return []*cargo.Cargo{r.cargo}, nil
 }
@@ -0,0 +1,120 @@
+package booking
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/tracing"
+)
+
+type tracingService struct {
+	tracer *tracing.Tracer
+	Service
+}
+
+// NewTracingService returns an instance of a tracing Service.
+func NewTracingService(tracer *tracing.Tracer, s Service) Service {
+	return &tracingService{tracer, s}
+}
+
+func (s *tracingService) BookNewCargo(ctx context.Context, origin, destination location.UNLocode, deadline time.Time) (id cargo.TrackingID, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.book", tracing.KindInternal)
+	span.SetAttributes("origin", origin, "destination", destination)
+	defer func() {
+		span.SetAttributes("tracking_id", id)
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.BookNewCargo(ctx, origin, destination, deadline)
+}
+
+func (s *tracingService) UnbookCargo(ctx context.Context, id cargo.TrackingID) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.unbook", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.UnbookCargo(ctx, id)
+}
+
+func (s *tracingService) LoadCargo(ctx context.Context, id cargo.TrackingID) (c Cargo, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.load", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.LoadCargo(ctx, id)
+}
+
+func (s *tracingService) RequestPossibleRoutesForCargo(ctx context.Context, id cargo.TrackingID) (itineraries []cargo.Itinerary, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.request_routes", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetAttributes("count", len(itineraries))
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.RequestPossibleRoutesForCargo(ctx, id)
+}
+
+func (s *tracingService) AssignCargoToRoute(ctx context.Context, id cargo.TrackingID, itinerary cargo.Itinerary) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.assign_to_route", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.AssignCargoToRoute(ctx, id, itinerary)
+}
+
+func (s *tracingService) ChangeDestination(ctx context.Context, id cargo.TrackingID, l location.UNLocode) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.change_destination", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id, "destination", l)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.ChangeDestination(ctx, id, l)
+}
+
+func (s *tracingService) Cargos(ctx context.Context) (cs []Cargo, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.list_cargos", tracing.KindInternal)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.Cargos(ctx)
+}
+
+func (s *tracingService) Locations(ctx context.Context) (ls []Location, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.list_locations", tracing.KindInternal)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.Locations(ctx)
+}
+
+func (s *tracingService) IssueTrackingToken(ctx context.Context, id cargo.TrackingID) (t TrackingToken, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.issue_tracking_token", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.IssueTrackingToken(ctx, id)
+}
+
+func (s *tracingService) RevokeTrackingTokens(ctx context.Context, id cargo.TrackingID) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.revoke_tracking_tokens", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.RevokeTrackingTokens(ctx, id)
+}
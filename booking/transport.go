@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"time"
 
 	"context"
 
@@ -14,12 +13,17 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/httpctx"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/requestid"
+	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/validation"
 )
 
-// MakeHandler returns a handler for the booking service.
-func MakeHandler(ctx context.Context, bs Service, logger kitlog.Logger) http.Handler {
+// MakeHandler returns a router for the booking service.
+func MakeHandler(ctx context.Context, bs Service, logger kitlog.Logger) *mux.Router {
 	opts := []kithttp.ServerOption{
+		kithttp.ServerBefore(httpctx.FromRequest, requestid.HTTPToContext),
 		kithttp.ServerErrorLogger(logger),
 		kithttp.ServerErrorEncoder(encodeError),
 	}
@@ -68,6 +72,20 @@ func MakeHandler(ctx context.Context, bs Service, logger kitlog.Logger) http.Han
 		encodeResponse,
 		opts...,
 	)
+	issueTrackingTokenHandler := kithttp.NewServer(
+		ctx,
+		makeIssueTrackingTokenEndpoint(bs),
+		decodeIssueTrackingTokenRequest,
+		encodeResponse,
+		opts...,
+	)
+	revokeTrackingTokensHandler := kithttp.NewServer(
+		ctx,
+		makeRevokeTrackingTokensEndpoint(bs),
+		decodeRevokeTrackingTokensRequest,
+		encodeResponse,
+		opts...,
+	)
 	listCargosHandler := kithttp.NewServer(
 		ctx,
 		makeListCargosEndpoint(bs),
@@ -92,8 +110,10 @@ func MakeHandler(ctx context.Context, bs Service, logger kitlog.Logger) http.Han
 	r.Handle("/booking/v1/cargos/{id}/request_routes", requestRoutesHandler).Methods("GET")
 	r.Handle("/booking/v1/cargos/{id}/assign_to_route", assignToRouteHandler).Methods("POST")
 	r.Handle("/booking/v1/cargos/{id}/change_destination", changeDestinationHandler).Methods("POST")
+	r.Handle("/booking/v1/cargos/{id}/tracking_tokens", issueTrackingTokenHandler).Methods("POST")
+	r.Handle("/booking/v1/cargos/{id}/tracking_tokens", revokeTrackingTokensHandler).Methods("DELETE")
 	r.Handle("/booking/v1/locations", listLocationsHandler).Methods("GET")
-	r.Handle("/booking/v1/docs", http.StripPrefix("/booking/v1/docs", http.FileServer(http.Dir("booking/docs"))))
+	r.Handle("/booking/v1/openapi.json", OpenAPI).Methods("GET")
 
 	return r
 }
@@ -102,19 +122,24 @@ var errBadRoute = errors.New("bad route")
 
 func decodeBookCargoRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var body struct {
-		Origin          string    `json:"origin"`
-		Destination     string    `json:"destination"`
-		ArrivalDeadline time.Time `json:"arrival_deadline"`
+		Origin          string `json:"origin"`
+		Destination     string `json:"destination"`
+		ArrivalDeadline string `json:"arrival_deadline"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+	if err := validation.DecodeJSON(r.Body, &body); err != nil {
+		return nil, err
+	}
+
+	deadline, err := validation.ParseTime("arrival_deadline", body.ArrivalDeadline)
+	if err != nil {
 		return nil, err
 	}
 
 	return bookCargoRequest{
 		Origin:          location.UNLocode(body.Origin),
 		Destination:     location.UNLocode(body.Destination),
-		ArrivalDeadline: body.ArrivalDeadline,
+		ArrivalDeadline: deadline,
 	}, nil
 }
 
@@ -156,7 +181,7 @@ func decodeAssignToRouteRequest(_ context.Context, r *http.Request) (interface{}
 	}
 
 	var itinerary cargo.Itinerary
-	if err := json.NewDecoder(r.Body).Decode(&itinerary); err != nil {
+	if err := validation.DecodeJSON(r.Body, &itinerary); err != nil {
 		return nil, err
 	}
 
@@ -177,7 +202,7 @@ func decodeChangeDestinationRequest(_ context.Context, r *http.Request) (interfa
 		Destination string `json:"destination"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+	if err := validation.DecodeJSON(r.Body, &body); err != nil {
 		return nil, err
 	}
 
@@ -187,6 +212,24 @@ func decodeChangeDestinationRequest(_ context.Context, r *http.Request) (interfa
 	}, nil
 }
 
+func decodeIssueTrackingTokenRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return issueTrackingTokenRequest{ID: cargo.TrackingID(id)}, nil
+}
+
+func decodeRevokeTrackingTokensRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return revokeTrackingTokensRequest{ID: cargo.TrackingID(id)}, nil
+}
+
 func decodeListCargosRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	return listCargosRequest{}, nil
 }
@@ -210,16 +253,24 @@ type errorer interface {
 
 // encode errors from business-logic
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	switch err {
-	case cargo.ErrUnknown:
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	body := map[string]interface{}{
+		"error": err.Error(),
+	}
+
+	var verr *validation.Error
+	switch {
+	case errors.As(err, &verr):
+		body["fields"] = verr.Fields
+		w.WriteHeader(verr.StatusCode())
+	case err == cargo.ErrUnknown:
 		w.WriteHeader(http.StatusNotFound)
-	case ErrInvalidArgument:
+	case err == ErrInvalidArgument:
 		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, routing.ErrUnavailable):
+		w.WriteHeader(http.StatusServiceUnavailable)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
+	json.NewEncoder(w).Encode(body)
 }
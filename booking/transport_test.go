@@ -0,0 +1,14 @@
+package booking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/openapi/openapitest"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	openapitest.CheckRoutes(t, OpenAPI, MakeHandler(context.Background(), nil, log.NewNopLogger()))
+}
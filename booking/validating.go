@@ -0,0 +1,154 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type validatingService struct {
+	locations location.Repository
+	voyages   voyage.Repository
+	now       func() time.Time
+	Service
+}
+
+// NewValidatingService returns a new instance of a validating Service, which
+// rejects arguments referring to unknown locations or voyages, legs with
+// another mode of transport than their voyage, or deadlines in the past with
+// a *validation.Error.
+func NewValidatingService(locations location.Repository, voyages voyage.Repository, s Service) Service {
+	return &validatingService{
+		locations: locations,
+		voyages:   voyages,
+		now:       time.Now,
+		Service:   s,
+	}
+}
+
+func (s *validatingService) BookNewCargo(ctx context.Context, origin, destination location.UNLocode, deadline time.Time) (cargo.TrackingID, error) {
+	var errs validation.Errors
+
+	if err := s.checkLocation(ctx, &errs, "origin", origin); err != nil {
+		return "", err
+	}
+	if err := s.checkLocation(ctx, &errs, "destination", destination); err != nil {
+		return "", err
+	}
+	if origin != "" && origin == destination {
+		errs.Add("destination", "must differ from origin")
+	}
+
+	switch {
+	case deadline.IsZero():
+		errs.Add("arrival_deadline", "is required")
+	case !deadline.After(s.now()):
+		errs.Add("arrival_deadline", "must be in the future")
+	}
+
+	if err := errs.Err(); err != nil {
+		return "", err
+	}
+
+	return s.Service.BookNewCargo(ctx, origin, destination, deadline)
+}
+
+func (s *validatingService) AssignCargoToRoute(ctx context.Context, id cargo.TrackingID, itinerary cargo.Itinerary) error {
+	var errs validation.Errors
+
+	if len(itinerary.Legs) == 0 {
+		errs.Add("legs", "must not be empty")
+	}
+
+	for i, leg := range itinerary.Legs {
+		field := func(name string) string {
+			return fmt.Sprintf("legs[%d].%s", i, name)
+		}
+		if err := s.checkVoyage(ctx, &errs, field, leg); err != nil {
+			return err
+		}
+		if err := s.checkLocation(ctx, &errs, field("from"), leg.LoadLocation); err != nil {
+			return err
+		}
+		if err := s.checkLocation(ctx, &errs, field("to"), leg.UnloadLocation); err != nil {
+			return err
+		}
+		if leg.UnloadTime.Before(leg.LoadTime) {
+			errs.Add(field("unload_time"), "must not be before load_time")
+		}
+		if i > 0 && leg.LoadLocation != itinerary.Legs[i-1].UnloadLocation {
+			errs.Add(field("from"), "must be where the previous leg ends")
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	return s.Service.AssignCargoToRoute(ctx, id, itinerary)
+}
+
+func (s *validatingService) ChangeDestination(ctx context.Context, id cargo.TrackingID, destination location.UNLocode) error {
+	var errs validation.Errors
+
+	if err := s.checkLocation(ctx, &errs, "destination", destination); err != nil {
+		return err
+	}
+
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	return s.Service.ChangeDestination(ctx, id, destination)
+}
+
+// checkVoyage adds field errors unless the voyage of the leg is registered
+// with the mode of transport of the leg. Errors other than the voyage being
+// unknown are returned.
+func (s *validatingService) checkVoyage(ctx context.Context, errs *validation.Errors, field func(string) string, leg cargo.Leg) error {
+	if leg.VoyageNumber == "" {
+		errs.Add(field("voyage_number"), "is required")
+		return nil
+	}
+
+	v, err := s.voyages.Find(ctx, leg.VoyageNumber)
+	switch err {
+	case nil:
+	case voyage.ErrUnknown:
+		errs.Add(field("voyage_number"), "unknown voyage %q", leg.VoyageNumber)
+		return nil
+	default:
+		return err
+	}
+
+	if leg.Mode != v.Mode {
+		errs.Add(field("mode"), "must be %s, the mode of voyage %s", v.Mode, v.Number)
+	}
+
+	return nil
+}
+
+// checkLocation adds a field error unless the location is registered. Errors
+// other than the location being unknown are returned.
+func (s *validatingService) checkLocation(ctx context.Context, errs *validation.Errors, field string, l location.UNLocode) error {
+	if l == "" {
+		errs.Add(field, "is required")
+		return nil
+	}
+
+	_, err := s.locations.Find(ctx, l)
+	switch err {
+	case nil:
+		return nil
+	case location.ErrUnknown:
+		errs.Add(field, "unknown location %q", l)
+		return nil
+	}
+
+	return err
+}
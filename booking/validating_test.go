@@ -0,0 +1,100 @@
+package booking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+func newTestValidatingService() Service {
+	var locations mock.LocationRepository
+	locations.FindFn = func(_ context.Context, l location.UNLocode) (*location.Location, error) {
+		switch l {
+		case location.SESTO, location.AUMEL, location.CNHKG:
+			return &location.Location{UNLocode: l}, nil
+		}
+		return nil, location.ErrUnknown
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(_ context.Context, n voyage.Number) (*voyage.Voyage, error) {
+		switch n {
+		case voyage.V100.Number:
+			return voyage.V100, nil
+		case voyage.R100.Number:
+			return voyage.R100, nil
+		}
+		return nil, voyage.ErrUnknown
+	}
+
+	s := NewValidatingService(&locations, &voyages, nil).(*validatingService)
+	s.now = func() time.Time {
+		return time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return s
+}
+
+func TestValidatingServiceBookNewCargo(t *testing.T) {
+	s := newTestValidatingService()
+
+	var (
+		future = time.Date(2016, time.April, 1, 0, 0, 0, 0, time.UTC)
+		past   = time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	tests := []struct {
+		origin, destination location.UNLocode
+		deadline            time.Time
+		err                 string
+	}{
+		{"", "", time.Time{}, "invalid request: origin: is required; destination: is required; arrival_deadline: is required"},
+		{"XXXXX", location.AUMEL, future, `invalid request: origin: unknown location "XXXXX"`},
+		{location.SESTO, location.SESTO, future, "invalid request: destination: must differ from origin"},
+		{location.SESTO, location.AUMEL, past, "invalid request: arrival_deadline: must be in the future"},
+	}
+
+	for _, tt := range tests {
+		_, err := s.BookNewCargo(context.Background(), tt.origin, tt.destination, tt.deadline)
+		if _, ok := err.(*validation.Error); !ok || err.Error() != tt.err {
+			t.Errorf("BookNewCargo(%s, %s, %s) = %v; want = %s", tt.origin, tt.destination, tt.deadline, err, tt.err)
+		}
+	}
+}
+
+func TestValidatingServiceAssignCargoToRoute(t *testing.T) {
+	s := newTestValidatingService()
+
+	var (
+		t1 = time.Date(2016, time.March, 2, 0, 0, 0, 0, time.UTC)
+		t2 = time.Date(2016, time.March, 3, 0, 0, 0, 0, time.UTC)
+	)
+
+	itinerary := cargo.Itinerary{Legs: []cargo.Leg{
+		{VoyageNumber: "V100", LoadLocation: location.SESTO, UnloadLocation: location.CNHKG, LoadTime: t2, UnloadTime: t1},
+		{LoadLocation: location.AUMEL, UnloadLocation: "XXXXX", LoadTime: t1, UnloadTime: t2},
+		{VoyageNumber: "X999", LoadLocation: "XXXXX", UnloadLocation: location.SESTO, LoadTime: t1, UnloadTime: t2},
+		{VoyageNumber: "R100", Mode: voyage.Sea, LoadLocation: location.SESTO, UnloadLocation: location.AUMEL, LoadTime: t1, UnloadTime: t2},
+	}}
+
+	want := "invalid request: legs[0].unload_time: must not be before load_time; " +
+		"legs[1].voyage_number: is required; " +
+		`legs[1].to: unknown location "XXXXX"; ` +
+		"legs[1].from: must be where the previous leg ends; " +
+		`legs[2].voyage_number: unknown voyage "X999"; ` +
+		`legs[2].from: unknown location "XXXXX"; ` +
+		"legs[3].mode: must be rail, the mode of voyage R100"
+
+	if err := s.AssignCargoToRoute(context.Background(), "ABC123", itinerary); err == nil || err.Error() != want {
+		t.Errorf("err = %v; want = %s", err, want)
+	}
+
+	if err := s.AssignCargoToRoute(context.Background(), "ABC123", cargo.Itinerary{}); err == nil || err.Error() != "invalid request: legs: must not be empty" {
+		t.Errorf("err = %v; want = invalid request: legs: must not be empty", err)
+	}
+}
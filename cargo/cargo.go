@@ -2,6 +2,7 @@
 package cargo
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"time"
@@ -56,10 +57,16 @@ func New(id TrackingID, rs RouteSpecification) *Cargo {
 
 // Repository provides access a cargo store.
 type Repository interface {
-	Remove(cargo *Cargo) error
-	Store(cargo *Cargo) error
-	Find(id TrackingID) (*Cargo, error)
-	FindAll() []*Cargo
+	Remove(ctx context.Context, cargo *Cargo) error
+	Store(ctx context.Context, cargo *Cargo) error
+	Find(ctx context.Context, id TrackingID) (*Cargo, error)
+
+	// FindMany returns the cargos with the given tracking IDs, in a single
+	// lookup where the backend allows it. Cargos that do not exist are left
+	// out, and the cargos are returned in no particular order.
+	FindMany(ctx context.Context, ids []TrackingID) ([]*Cargo, error)
+
+	FindAll(ctx context.Context) ([]*Cargo, error)
 }
 
 // ErrUnknown is used when a cargo could not be found.
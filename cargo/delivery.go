@@ -104,19 +104,11 @@ func calculateUnloadedAtDestination(event HandlingEvent, rs RouteSpecification)
 }
 
 func calculateTransportStatus(event HandlingEvent) TransportStatus {
-	switch event.Activity.Type {
-	case NotHandled:
+	if event.Activity.Type == NotHandled {
 		return NotReceived
-	case Load:
-		return OnboardCarrier
-	case Unload:
-		return InPort
-	case Receive:
-		return InPort
-	case Customs:
-		return InPort
-	case Claim:
-		return Claimed
+	}
+	if s, ok := event.Activity.Type.Spec(); ok {
+		return s.TransportStatus
 	}
 	return Unknown
 }
@@ -125,31 +117,45 @@ func calculateLastKnownLocation(event HandlingEvent) location.UNLocode {
 	return event.Activity.Location
 }
 
+// calculateNextExpectedActivity predicts the next activity from where in the
+// itinerary the last event was expected. After events expected elsewhere,
+// like customs or terminal operations, the next activity is not known.
 func calculateNextExpectedActivity(d Delivery) HandlingActivity {
 	if !d.IsOnTrack() {
 		return HandlingActivity{}
 	}
 
-	switch d.LastEvent.Activity.Type {
-	case NotHandled:
-		return HandlingActivity{Type: Receive, Location: d.RouteSpecification.Origin}
-	case Receive:
-		l := d.Itinerary.Legs[0]
-		return HandlingActivity{Type: Load, Location: l.LoadLocation, VoyageNumber: l.VoyageNumber}
-	case Load:
-		for _, l := range d.Itinerary.Legs {
-			if l.LoadLocation == d.LastEvent.Activity.Location {
-				return HandlingActivity{Type: Unload, Location: l.UnloadLocation, VoyageNumber: l.VoyageNumber}
+	legs := d.Itinerary.Legs
+
+	last := d.LastEvent.Activity
+	if last.Type == NotHandled {
+		return expectedActivity(ExpectedAtOrigin, legs[0].Mode, d.RouteSpecification.Origin, "")
+	}
+
+	s, ok := last.Type.Spec()
+	if !ok {
+		return HandlingActivity{}
+	}
+
+	switch s.ExpectedAt {
+	case ExpectedAtOrigin:
+		l := legs[0]
+		return expectedActivity(ExpectedAtLoad, l.Mode, l.LoadLocation, l.VoyageNumber)
+	case ExpectedAtLoad:
+		for _, l := range legs {
+			if l.LoadLocation == last.Location {
+				return expectedActivity(ExpectedAtUnload, l.Mode, l.UnloadLocation, l.VoyageNumber)
 			}
 		}
-	case Unload:
-		for i, l := range d.Itinerary.Legs {
-			if l.UnloadLocation == d.LastEvent.Activity.Location {
-				if i < len(d.Itinerary.Legs)-1 {
-					return HandlingActivity{Type: Load, Location: d.Itinerary.Legs[i+1].LoadLocation, VoyageNumber: d.Itinerary.Legs[i+1].VoyageNumber}
+	case ExpectedAtUnload:
+		for i, l := range legs {
+			if l.UnloadLocation == last.Location {
+				if i < len(legs)-1 {
+					next := legs[i+1]
+					return expectedActivity(ExpectedAtLoad, next.Mode, next.LoadLocation, next.VoyageNumber)
 				}
 
-				return HandlingActivity{Type: Claim, Location: l.UnloadLocation}
+				return expectedActivity(ExpectedAtDestination, l.Mode, l.UnloadLocation, "")
 			}
 		}
 	}
@@ -157,6 +163,16 @@ func calculateNextExpectedActivity(d Delivery) HandlingActivity {
 	return HandlingActivity{}
 }
 
+// expectedActivity returns the activity of the event type expected at e in
+// legs by mode m, or no activity if no such type is registered.
+func expectedActivity(e Expectation, m voyage.Mode, loc location.UNLocode, v voyage.Number) HandlingActivity {
+	t := expectedHandlingEventType(e, m)
+	if t == NotHandled {
+		return HandlingActivity{}
+	}
+	return HandlingActivity{Type: t, Location: loc, VoyageNumber: v}
+}
+
 func calculateCurrentVoyage(transportStatus TransportStatus, event HandlingEvent) voyage.Number {
 	if transportStatus == OnboardCarrier && event.Activity.Type != NotHandled {
 		return event.Activity.VoyageNumber
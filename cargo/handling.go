@@ -9,6 +9,7 @@ package cargo
 // It would make sense not having the cargo package depend on handling.
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -30,6 +31,11 @@ type HandlingActivity struct {
 type HandlingEvent struct {
 	TrackingID TrackingID
 	Activity   HandlingActivity
+
+	// CompletionTime is when the cargo was handled, and RegistrationTime
+	// when the event was registered in the system.
+	CompletionTime   time.Time
+	RegistrationTime time.Time
 }
 
 // HandlingEventType describes type of a handling event.
@@ -43,45 +49,216 @@ const (
 	Receive
 	Claim
 	Customs
+	GateIn
+	GateOut
+	Stuff
+	Strip
+	RailTransfer
+	InspectionHold
+	InspectionRelease
+)
+
+// Expectation describes where in an itinerary events of a type are expected.
+type Expectation int
+
+// Where events may be expected.
+const (
+	// ExpectedAnywhere events are expected wherever they take place.
+	ExpectedAnywhere Expectation = iota
+	// ExpectedAtOrigin events are expected at the initial departure
+	// location of the itinerary.
+	ExpectedAtOrigin
+	// ExpectedAtDestination events are expected at the final arrival
+	// location of the itinerary.
+	ExpectedAtDestination
+	// ExpectedOnItinerary events are expected at any location the
+	// itinerary passes through.
+	ExpectedOnItinerary
+	// ExpectedAtLoad events are expected where a leg of the itinerary is
+	// loaded onto the voyage of the event.
+	ExpectedAtLoad
+	// ExpectedAtUnload events are expected where a leg of the itinerary is
+	// unloaded off the voyage of the event.
+	ExpectedAtUnload
+	// ExpectedAtTransfer events are expected where the itinerary changes
+	// between a leg by one of the modes of the spec and a leg by another
+	// mode.
+	ExpectedAtTransfer
 )
 
+// HandlingEventTypeSpec describes a handling event type.
+type HandlingEventTypeSpec struct {
+	// Name is how the type is named in requests, e.g. "GateIn".
+	Name string
+
+	// Code is how the type is named in read models, e.g. "gate_in".
+	Code string
+
+	// TransportStatus is the status of a cargo last handled by an event of
+	// the type.
+	TransportStatus TransportStatus
+
+	// ExpectedAt is where in its itinerary the event is expected. The first
+	// type registered as expected at the origin, at a load, at an unload or
+	// at the destination is the one predicted as the next activity of cargos
+	// getting there.
+	ExpectedAt Expectation
+
+	// RequiresVoyage is set for events handling the cargo onto or off a
+	// voyage.
+	RequiresVoyage bool
+
+	// Modes limits where the event is expected to the locations of legs by
+	// these modes of transport. If nil, legs by any mode will do.
+	Modes []voyage.Mode
+}
+
+// HandlesMode reports whether events of the type are expected at legs by the
+// mode of transport.
+func (s HandlingEventTypeSpec) HandlesMode(m voyage.Mode) bool {
+	if s.Modes == nil {
+		return true
+	}
+	for _, mode := range s.Modes {
+		if mode == m {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	handlingEventTypes     = make(map[HandlingEventType]HandlingEventTypeSpec)
+	handlingEventTypeNames = make(map[string]HandlingEventType)
+	handlingEventTypeOrder []HandlingEventType
+)
+
+// RegisterHandlingEventType makes an event type known by its spec. It panics
+// if the type, its name or its code is registered twice. Types are meant to be
+// registered from init functions, as the registry is not safe for concurrent
+// use.
+func RegisterHandlingEventType(t HandlingEventType, s HandlingEventTypeSpec) {
+	if t == NotHandled {
+		panic("cargo: cannot register NotHandled")
+	}
+	if _, dup := handlingEventTypes[t]; dup {
+		panic("cargo: handling event type registered twice: " + s.Name)
+	}
+	if _, dup := handlingEventTypeNames[s.Name]; dup {
+		panic("cargo: handling event type name registered twice: " + s.Name)
+	}
+	for _, other := range handlingEventTypes {
+		if other.Code == s.Code {
+			panic("cargo: handling event type code registered twice: " + s.Code)
+		}
+	}
+
+	handlingEventTypes[t] = s
+	handlingEventTypeNames[s.Name] = t
+	handlingEventTypeOrder = append(handlingEventTypeOrder, t)
+}
+
+// HandlingEventTypes returns the registered event types, in the order they
+// were registered.
+func HandlingEventTypes() []HandlingEventType {
+	return append([]HandlingEventType(nil), handlingEventTypeOrder...)
+}
+
+// ParseHandlingEventType returns the registered event type by name, or
+// NotHandled if there is none.
+func ParseHandlingEventType(name string) HandlingEventType {
+	return handlingEventTypeNames[name]
+}
+
+// expectedHandlingEventType returns the first registered event type expected
+// at e in legs by mode m, or NotHandled if there is none.
+func expectedHandlingEventType(e Expectation, m voyage.Mode) HandlingEventType {
+	for _, t := range handlingEventTypeOrder {
+		if s := handlingEventTypes[t]; s.ExpectedAt == e && s.HandlesMode(m) {
+			return t
+		}
+	}
+	return NotHandled
+}
+
+// Spec returns the spec of the event type, and whether it is registered.
+func (t HandlingEventType) Spec() (HandlingEventTypeSpec, bool) {
+	s, ok := handlingEventTypes[t]
+	return s, ok
+}
+
 func (t HandlingEventType) String() string {
-	switch t {
-	case NotHandled:
+	if t == NotHandled {
 		return "Not Handled"
-	case Load:
-		return "Load"
-	case Unload:
-		return "Unload"
-	case Receive:
-		return "Receive"
-	case Claim:
-		return "Claim"
-	case Customs:
-		return "Customs"
 	}
+	return handlingEventTypes[t].Name
+}
+
+func init() {
+	for _, r := range []struct {
+		t HandlingEventType
+		s HandlingEventTypeSpec
+	}{
+		{Receive, HandlingEventTypeSpec{Name: "Receive", Code: "receive", TransportStatus: InPort, ExpectedAt: ExpectedAtOrigin}},
+		{Load, HandlingEventTypeSpec{Name: "Load", Code: "load", TransportStatus: OnboardCarrier, ExpectedAt: ExpectedAtLoad, RequiresVoyage: true}},
+		{Unload, HandlingEventTypeSpec{Name: "Unload", Code: "unload", TransportStatus: InPort, ExpectedAt: ExpectedAtUnload, RequiresVoyage: true}},
+		{Customs, HandlingEventTypeSpec{Name: "Customs", Code: "customs", TransportStatus: InPort, ExpectedAt: ExpectedAnywhere}},
+		{Claim, HandlingEventTypeSpec{Name: "Claim", Code: "claim", TransportStatus: Claimed, ExpectedAt: ExpectedAtDestination}},
 
-	return ""
+		// Terminal operations. Locations are ports, so moving a cargo
+		// between terminals of a port leaves it in port. Gating a cargo
+		// out is only expected at its destination, where it is how the
+		// consignee takes the cargo out of the terminal: like a claim, it
+		// ends the transport. Rail transfers take place where the
+		// itinerary joins or leaves the railway.
+		{GateIn, HandlingEventTypeSpec{Name: "GateIn", Code: "gate_in", TransportStatus: InPort, ExpectedAt: ExpectedOnItinerary}},
+		{GateOut, HandlingEventTypeSpec{Name: "GateOut", Code: "gate_out", TransportStatus: Claimed, ExpectedAt: ExpectedAtDestination}},
+		{Stuff, HandlingEventTypeSpec{Name: "Stuff", Code: "stuff", TransportStatus: InPort, ExpectedAt: ExpectedOnItinerary}},
+		{Strip, HandlingEventTypeSpec{Name: "Strip", Code: "strip", TransportStatus: InPort, ExpectedAt: ExpectedOnItinerary}},
+		{RailTransfer, HandlingEventTypeSpec{Name: "RailTransfer", Code: "rail_transfer", TransportStatus: InPort, ExpectedAt: ExpectedAtTransfer, Modes: []voyage.Mode{voyage.Rail}}},
+		{InspectionHold, HandlingEventTypeSpec{Name: "InspectionHold", Code: "inspection_hold", TransportStatus: InPort, ExpectedAt: ExpectedAnywhere}},
+		{InspectionRelease, HandlingEventTypeSpec{Name: "InspectionRelease", Code: "inspection_release", TransportStatus: InPort, ExpectedAt: ExpectedAnywhere}},
+	} {
+		RegisterHandlingEventType(r.t, r.s)
+	}
 }
 
-// HandlingHistory is the handling history of a cargo.
+// HandlingHistory is the handling history of a cargo, ordered by completion
+// time.
 type HandlingHistory struct {
 	HandlingEvents []HandlingEvent
 }
 
 // MostRecentlyCompletedEvent returns most recently completed handling event.
+// Of events completed at the same time, the last one is returned.
 func (h HandlingHistory) MostRecentlyCompletedEvent() (HandlingEvent, error) {
 	if len(h.HandlingEvents) == 0 {
 		return HandlingEvent{}, errors.New("delivery history is empty")
 	}
 
-	return h.HandlingEvents[len(h.HandlingEvents)-1], nil
+	last := h.HandlingEvents[0]
+	for _, e := range h.HandlingEvents[1:] {
+		if !e.CompletionTime.Before(last.CompletionTime) {
+			last = e
+		}
+	}
+
+	return last, nil
 }
 
 // HandlingEventRepository provides access a handling event store.
 type HandlingEventRepository interface {
-	Store(e HandlingEvent)
-	QueryHandlingHistory(TrackingID) HandlingHistory
+	// Store stores the event. Events may be stored out of order.
+	Store(ctx context.Context, e HandlingEvent) error
+
+	// QueryHandlingHistory returns the events of the cargo, ordered by
+	// completion time.
+	QueryHandlingHistory(ctx context.Context, id TrackingID) (HandlingHistory, error)
+
+	// QueryHandlingHistories returns the handling histories of the cargos,
+	// by tracking ID, in a single query where the backend allows it.
+	// Cargos without events may be left out.
+	QueryHandlingHistories(ctx context.Context, ids []TrackingID) (map[TrackingID]HandlingHistory, error)
 }
 
 // HandlingEventFactory creates handling events.
@@ -92,21 +269,21 @@ type HandlingEventFactory struct {
 }
 
 // CreateHandlingEvent creates a validated handling event.
-func (f *HandlingEventFactory) CreateHandlingEvent(registered time.Time, completed time.Time, id TrackingID,
+func (f *HandlingEventFactory) CreateHandlingEvent(ctx context.Context, registered time.Time, completed time.Time, id TrackingID,
 	voyageNumber voyage.Number, unLocode location.UNLocode, eventType HandlingEventType) (HandlingEvent, error) {
 
-	if _, err := f.CargoRepository.Find(id); err != nil {
+	if _, err := f.CargoRepository.Find(ctx, id); err != nil {
 		return HandlingEvent{}, err
 	}
 
-	if _, err := f.VoyageRepository.Find(voyageNumber); err != nil {
+	if _, err := f.VoyageRepository.Find(ctx, voyageNumber); err != nil {
 		// TODO: This is pretty ugly, but when creating a Receive event, the voyage number is not known.
 		if len(voyageNumber) > 0 {
 			return HandlingEvent{}, err
 		}
 	}
 
-	if _, err := f.LocationRepository.Find(unLocode); err != nil {
+	if _, err := f.LocationRepository.Find(ctx, unLocode); err != nil {
 		return HandlingEvent{}, err
 	}
 
@@ -117,5 +294,7 @@ func (f *HandlingEventFactory) CreateHandlingEvent(registered time.Time, complet
 			Location:     unLocode,
 			VoyageNumber: voyageNumber,
 		},
+		CompletionTime:   completed,
+		RegistrationTime: registered,
 	}, nil
 }
@@ -0,0 +1,137 @@
+package cargo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+func TestMostRecentlyCompletedEvent(t *testing.T) {
+	at := func(day int, typ HandlingEventType, loc location.UNLocode) HandlingEvent {
+		return HandlingEvent{
+			Activity:       HandlingActivity{Type: typ, Location: loc},
+			CompletionTime: time.Date(2009, time.March, day, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	h := HandlingHistory{HandlingEvents: []HandlingEvent{
+		at(1, Receive, location.CNHKG),
+		at(5, Unload, location.JNTKO),
+		at(3, Load, location.CNHKG),
+		at(5, Customs, location.JNTKO),
+	}}
+
+	e, err := h.MostRecentlyCompletedEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Activity.Type != Customs {
+		t.Errorf("e.Activity.Type = %s; want = %s", e.Activity.Type, Customs)
+	}
+
+	if _, err := (HandlingHistory{}).MostRecentlyCompletedEvent(); err == nil {
+		t.Errorf("empty history should fail")
+	}
+}
+
+func TestHandlingEventTypes(t *testing.T) {
+	for _, typ := range HandlingEventTypes() {
+		s, ok := typ.Spec()
+		if !ok {
+			t.Fatalf("%d is not registered", typ)
+		}
+		if got := ParseHandlingEventType(s.Name); got != typ {
+			t.Errorf("ParseHandlingEventType(%q) = %v; want = %v", s.Name, got, typ)
+		}
+		if got := typ.String(); got != s.Name {
+			t.Errorf("String() = %q; want = %q", got, s.Name)
+		}
+	}
+
+	if got := ParseHandlingEventType("Teleport"); got != NotHandled {
+		t.Errorf("ParseHandlingEventType(%q) = %v; want = %v", "Teleport", got, NotHandled)
+	}
+}
+
+func TestTransportStatusOfHandlingEvents(t *testing.T) {
+	for _, tt := range []struct {
+		typ  HandlingEventType
+		want TransportStatus
+	}{
+		{NotHandled, NotReceived},
+		{Receive, InPort},
+		{Load, OnboardCarrier},
+		{Unload, InPort},
+		{GateIn, InPort},
+		{Stuff, InPort},
+		{Strip, InPort},
+		{RailTransfer, InPort},
+		{InspectionHold, InPort},
+		{InspectionRelease, InPort},
+		{GateOut, Claimed},
+		{Claim, Claimed},
+		{HandlingEventType(1000), Unknown},
+	} {
+		e := HandlingEvent{Activity: HandlingActivity{Type: tt.typ}}
+		if got := calculateTransportStatus(e); got != tt.want {
+			t.Errorf("calculateTransportStatus(%v) = %v; want = %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestNextExpectedActivity(t *testing.T) {
+	rs := RouteSpecification{Origin: location.CNHKG, Destination: location.USCHI}
+	i := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", Mode: voyage.Sea, LoadLocation: location.CNHKG, UnloadLocation: location.USNYC},
+		{VoyageNumber: "R100", Mode: voyage.Rail, LoadLocation: location.USNYC, UnloadLocation: location.USCHI},
+	}}
+
+	tests := []struct {
+		last HandlingActivity
+		want HandlingActivity
+	}{
+		{HandlingActivity{}, HandlingActivity{Type: Receive, Location: location.CNHKG}},
+		{HandlingActivity{Type: Receive, Location: location.CNHKG}, HandlingActivity{Type: Load, Location: location.CNHKG, VoyageNumber: "V100"}},
+		{HandlingActivity{Type: Load, Location: location.CNHKG, VoyageNumber: "V100"}, HandlingActivity{Type: Unload, Location: location.USNYC, VoyageNumber: "V100"}},
+		{HandlingActivity{Type: Unload, Location: location.USNYC, VoyageNumber: "V100"}, HandlingActivity{Type: Load, Location: location.USNYC, VoyageNumber: "R100"}},
+		{HandlingActivity{Type: Unload, Location: location.USCHI, VoyageNumber: "R100"}, HandlingActivity{Type: Claim, Location: location.USCHI}},
+		{HandlingActivity{Type: RailTransfer, Location: location.USNYC}, HandlingActivity{}},
+		{HandlingActivity{Type: Claim, Location: location.USCHI}, HandlingActivity{}},
+	}
+
+	for _, tt := range tests {
+		d := newDelivery(HandlingEvent{Activity: tt.last}, i, rs)
+		if got := d.NextExpectedActivity; got != tt.want {
+			t.Errorf("after %v in %s: NextExpectedActivity = %+v; want = %+v", tt.last.Type, tt.last.Location, got, tt.want)
+		}
+	}
+}
+
+// A cargo gated out at its destination has been taken out of the terminal by
+// the consignee, which ends its transport as a claim does.
+func TestGateOutAtDestination(t *testing.T) {
+	rs := RouteSpecification{Origin: location.SESTO, Destination: location.AUMEL}
+	i := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", LoadLocation: location.SESTO, UnloadLocation: location.AUMEL},
+	}}
+
+	for _, typ := range []HandlingEventType{Claim, GateOut} {
+		d := newDelivery(HandlingEvent{Activity: HandlingActivity{Type: typ, Location: location.AUMEL}}, i, rs)
+		if d.TransportStatus != Claimed {
+			t.Errorf("%v: TransportStatus = %v; want = %v", typ, d.TransportStatus, Claimed)
+		}
+		if d.IsMisdirected {
+			t.Errorf("%v: IsMisdirected = true; want = false", typ)
+		}
+		if d.NextExpectedActivity != (HandlingActivity{}) {
+			t.Errorf("%v: NextExpectedActivity = %+v; want none", typ, d.NextExpectedActivity)
+		}
+	}
+
+	d := newDelivery(HandlingEvent{Activity: HandlingActivity{Type: GateOut, Location: location.SESTO}}, i, rs)
+	if !d.IsMisdirected {
+		t.Errorf("GateOut at origin: IsMisdirected = false; want = true")
+	}
+}
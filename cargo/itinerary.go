@@ -7,16 +7,19 @@ import (
 	"github.com/marcusolsson/goddd/voyage"
 )
 
-// Leg describes the transportation between two locations on a voyage.
+// Leg describes the transportation between two locations on a voyage. Mode is
+// the mode of transport of the voyage, so that itineraries may mix sea voyages
+// with inland moves by rail and road.
 type Leg struct {
 	VoyageNumber   voyage.Number     `json:"voyage_number"`
+	Mode           voyage.Mode       `json:"mode"`
 	LoadLocation   location.UNLocode `json:"from"`
 	UnloadLocation location.UNLocode `json:"to"`
 	LoadTime       time.Time         `json:"load_time"`
 	UnloadTime     time.Time         `json:"unload_time"`
 }
 
-// NewLeg creates a new itinerary leg.
+// NewLeg creates a new itinerary leg by sea.
 func NewLeg(voyageNumber voyage.Number, loadLocation, unloadLocation location.UNLocode, loadTime, unloadTime time.Time) Leg {
 	return Leg{
 		VoyageNumber:   voyageNumber,
@@ -66,25 +69,56 @@ func (i Itinerary) IsExpected(event HandlingEvent) bool {
 		return true
 	}
 
-	switch event.Activity.Type {
-	case Receive:
-		return i.InitialDepartureLocation() == event.Activity.Location
-	case Load:
-		for _, l := range i.Legs {
-			if l.LoadLocation == event.Activity.Location && l.VoyageNumber == event.Activity.VoyageNumber {
+	s, ok := event.Activity.Type.Spec()
+	if !ok {
+		return true
+	}
+
+	a := event.Activity
+
+	// Events limited to some modes of transport are only expected at legs
+	// by those modes.
+	var legs []Leg
+	for _, l := range i.Legs {
+		if s.HandlesMode(l.Mode) {
+			legs = append(legs, l)
+		}
+	}
+
+	switch s.ExpectedAt {
+	case ExpectedAtOrigin:
+		return i.InitialDepartureLocation() == a.Location
+	case ExpectedAtDestination:
+		return i.FinalArrivalLocation() == a.Location
+	case ExpectedOnItinerary:
+		for _, l := range legs {
+			if l.LoadLocation == a.Location || l.UnloadLocation == a.Location {
+				return true
+			}
+		}
+		return false
+	case ExpectedAtLoad:
+		for _, l := range legs {
+			if l.LoadLocation == a.Location && l.VoyageNumber == a.VoyageNumber {
+				return true
+			}
+		}
+		return false
+	case ExpectedAtUnload:
+		for _, l := range legs {
+			if l.UnloadLocation == a.Location && l.VoyageNumber == a.VoyageNumber {
 				return true
 			}
 		}
 		return false
-	case Unload:
-		for _, l := range i.Legs {
-			if l.UnloadLocation == event.Activity.Location && l.VoyageNumber == event.Activity.VoyageNumber {
+	case ExpectedAtTransfer:
+		for n := 1; n < len(i.Legs); n++ {
+			prev, next := i.Legs[n-1], i.Legs[n]
+			if prev.UnloadLocation == a.Location && s.HandlesMode(prev.Mode) != s.HandlesMode(next.Mode) {
 				return true
 			}
 		}
 		return false
-	case Claim:
-		return i.FinalArrivalLocation() == event.Activity.Location
 	}
 
 	return true
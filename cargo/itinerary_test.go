@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
 )
 
 func TestItinerary_CreateEmpty(t *testing.T) {
@@ -49,6 +50,12 @@ var eventExpectedTests = []eventExpectedTest{
 	{HandlingActivity{Type: Unload, Location: location.SESTO, VoyageNumber: "001A"}, false},
 	{HandlingActivity{Type: Claim, Location: location.CNHKG}, true},
 	{HandlingActivity{Type: Claim, Location: location.SESTO}, false},
+	{HandlingActivity{Type: GateIn, Location: location.AUMEL}, true},
+	{HandlingActivity{Type: GateIn, Location: location.DEHAM}, false},
+	{HandlingActivity{Type: GateOut, Location: location.CNHKG}, true},
+	{HandlingActivity{Type: GateOut, Location: location.AUMEL}, false},
+	{HandlingActivity{Type: Strip, Location: location.CNHKG}, true},
+	{HandlingActivity{Type: InspectionHold, Location: location.DEHAM}, true},
 }
 
 func TestItinerary_IsExpected(t *testing.T) {
@@ -75,3 +82,28 @@ func TestItinerary_IsExpected(t *testing.T) {
 		}
 	}
 }
+
+func TestItinerary_IsExpected_MixedModes(t *testing.T) {
+	i := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", Mode: voyage.Sea, LoadLocation: location.CNHKG, UnloadLocation: location.USNYC},
+		{VoyageNumber: "R100", Mode: voyage.Rail, LoadLocation: location.USNYC, UnloadLocation: location.USCHI},
+	}}
+
+	tests := []struct {
+		act  HandlingActivity
+		want bool
+	}{
+		{HandlingActivity{Type: Load, Location: location.USNYC, VoyageNumber: "R100"}, true},
+		{HandlingActivity{Type: Unload, Location: location.USCHI, VoyageNumber: "R100"}, true},
+		{HandlingActivity{Type: RailTransfer, Location: location.USNYC}, true},
+		{HandlingActivity{Type: RailTransfer, Location: location.USCHI}, false},
+		{HandlingActivity{Type: RailTransfer, Location: location.CNHKG}, false},
+		{HandlingActivity{Type: Claim, Location: location.USCHI}, true},
+	}
+
+	for _, tt := range tests {
+		if got := i.IsExpected(HandlingEvent{Activity: tt.act}); got != tt.want {
+			t.Errorf("IsExpected(%v in %s) = %v; want = %v", tt.act.Type, tt.act.Location, got, tt.want)
+		}
+	}
+}
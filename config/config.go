@@ -0,0 +1,746 @@
+// Package config provides the application configuration. Settings are
+// layered, in order of increasing precedence, from built-in defaults, a YAML
+// configuration file, environment variables and command-line flags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the complete application configuration.
+type Config struct {
+	HTTP       HTTPConfig       `yaml:"http"`
+	GRPC       GRPCConfig       `yaml:"grpc"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Routing    RoutingConfig    `yaml:"routing"`
+	Inspection InspectionConfig `yaml:"inspection"`
+	Handling   HandlingConfig   `yaml:"handling"`
+	Tracking   TrackingConfig   `yaml:"tracking"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Logging    LoggingConfig    `yaml:"logging"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	CORS       CORSConfig       `yaml:"cors"`
+}
+
+// HTTPConfig configures the HTTP server.
+type HTTPConfig struct {
+	Addr string `yaml:"addr"`
+
+	// ShutdownDelay is the time between reporting the instance as not ready
+	// and starting to drain connections, giving load balancers time to stop
+	// routing traffic to it.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+
+	// ShutdownTimeout is the maximum time spent draining in-flight requests
+	// and queued work on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// HealthCheckTimeout is the maximum time the liveness and readiness
+	// endpoints wait for a dependency to respond.
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+
+	// ValidateRequests rejects requests that do not match the OpenAPI
+	// specifications of the APIs.
+	ValidateRequests bool `yaml:"validate_requests"`
+
+	// IdempotencyTTL is how long the responses to requests with an
+	// Idempotency-Key header are kept for replay.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+}
+
+// GRPCConfig configures the gRPC server.
+type GRPCConfig struct {
+	// Addr is the listen address of the gRPC server, which serves the
+	// booking, handling and tracking services next to the HTTP server. An
+	// empty address disables it.
+	Addr string `yaml:"addr"`
+}
+
+// Storage backends.
+const (
+	StorageMongo = "mongo"
+	StorageInmem = "inmem"
+)
+
+// StorageConfig configures the repositories.
+type StorageConfig struct {
+	// Backend is either "mongo" or "inmem".
+	Backend string      `yaml:"backend"`
+	Mongo   MongoConfig `yaml:"mongo"`
+}
+
+// MongoConfig configures the MongoDB repositories.
+type MongoConfig struct {
+	URL         string `yaml:"url"`
+	Database    string `yaml:"database"`
+	MaxPoolSize int    `yaml:"max_pool_size"`
+
+	// PaddingBytes is the size of the padding document stored alongside
+	// each cargo. Zero disables padding.
+	PaddingBytes int `yaml:"padding_bytes"`
+}
+
+// Routing backends.
+const (
+	RoutingPathfinder = "pathfinder"
+)
+
+// RoutingConfig configures the routing service.
+type RoutingConfig struct {
+	// Backend is the routing service implementation. Currently only
+	// "pathfinder" is supported.
+	Backend string `yaml:"backend"`
+	URL     string `yaml:"url"`
+
+	// Path is the path of the routes resource, relative to URL.
+	Path string `yaml:"path"`
+
+	// Timeout bounds a request for routes, retries included. Each attempt
+	// is bounded by the timeout of the circuit breaker.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retries is the number of times a failed request is retried, waiting
+	// Backoff before the first retry and twice as long before each one
+	// after.
+	Retries int           `yaml:"retries"`
+	Backoff time.Duration `yaml:"backoff"`
+
+	// LocalFallback routes cargos over the schedules of known voyages when
+	// the routing service fails.
+	LocalFallback bool `yaml:"local_fallback"`
+
+	Cache          RoutingCacheConfig   `yaml:"cache"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+// RoutingCacheConfig configures the cache of routes found by the routing
+// service.
+type RoutingCacheConfig struct {
+	// TTL is how long routes are served from the cache before asking the
+	// routing service again. Zero disables the cache.
+	TTL time.Duration `yaml:"ttl"`
+
+	// StaleTTL is how long routes are kept in the cache, to be served as a
+	// last resort when the routing service and the local fallback fail.
+	StaleTTL time.Duration `yaml:"stale_ttl"`
+}
+
+// CircuitBreakerConfig configures the circuit breaker protecting calls to
+// the routing service.
+type CircuitBreakerConfig struct {
+	Timeout                time.Duration `yaml:"timeout"`
+	MaxConcurrentRequests  int           `yaml:"max_concurrent_requests"`
+	RequestVolumeThreshold int           `yaml:"request_volume_threshold"`
+	SleepWindow            time.Duration `yaml:"sleep_window"`
+	ErrorPercentThreshold  int           `yaml:"error_percent_threshold"`
+}
+
+// InspectionConfig configures the asynchronous cargo inspection.
+type InspectionConfig struct {
+	// QueueSize is the number of handling events that can wait for
+	// inspection before registering new events blocks.
+	QueueSize int `yaml:"queue_size"`
+}
+
+// HandlingConfig configures the registration of handling events.
+type HandlingConfig struct {
+	// CheckSequence rejects handling events that are not consistent with
+	// the handling history of the cargo, such as a cargo being unloaded
+	// before it has been loaded.
+	CheckSequence bool `yaml:"check_sequence"`
+
+	// DuplicateWindow is the time within which events completed for the
+	// same cargo, activity and location are considered duplicates.
+	DuplicateWindow time.Duration `yaml:"duplicate_window"`
+}
+
+// TrackingConfig configures the tracking of cargos.
+type TrackingConfig struct {
+	// StreamHistory is the number of tracking updates kept for streaming
+	// clients to resume from after reconnecting.
+	StreamHistory int `yaml:"stream_history"`
+
+	// TokenKey is the secret key signing the tokens of public tracking
+	// links. It must be shared by all instances. If empty, a random key is
+	// generated at startup, invalidating links issued before a restart.
+	TokenKey string `yaml:"token_key"`
+
+	// TokenTTL is the time public tracking links are valid for.
+	TokenTTL time.Duration `yaml:"token_ttl"`
+}
+
+// MetricsConfig configures the Prometheus metrics.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+
+	// SummaryMaxAge is the sliding window of the request latency summaries.
+	SummaryMaxAge time.Duration `yaml:"summary_max_age"`
+}
+
+// Log formats.
+const (
+	LogFormatLogfmt = "logfmt"
+	LogFormatJSON   = "json"
+)
+
+// LoggingConfig configures the application logger.
+type LoggingConfig struct {
+	// Level is one of "debug", "info", "warn" or "error".
+	Level string `yaml:"level"`
+
+	// Format is either "logfmt" or "json".
+	Format string `yaml:"format"`
+}
+
+// Tracing exporters.
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+// TracingConfig configures the tracing of requests.
+type TracingConfig struct {
+	// Exporter is one of "none", "stdout" or "otlp".
+	Exporter string `yaml:"exporter"`
+
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint of a collector.
+	Endpoint string `yaml:"endpoint"`
+
+	// ServiceName identifies the application in the traces.
+	ServiceName string `yaml:"service_name"`
+
+	// SampleRatio is the fraction of new traces that are recorded.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// CORSConfig configures the CORS policy of the HTTP APIs.
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// Default returns the default configuration.
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:               ":8080",
+			ShutdownTimeout:    15 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+			IdempotencyTTL:     24 * time.Hour,
+		},
+		GRPC: GRPCConfig{
+			Addr: ":8081",
+		},
+		Storage: StorageConfig{
+			Backend: StorageMongo,
+			Mongo: MongoConfig{
+				URL:          "127.0.0.1",
+				Database:     "dddsample",
+				MaxPoolSize:  60,
+				PaddingBytes: 60 * 1024,
+			},
+		},
+		Routing: RoutingConfig{
+			Backend:       RoutingPathfinder,
+			URL:           "http://localhost:7878",
+			Path:          "/paths",
+			Timeout:       10 * time.Second,
+			Retries:       2,
+			Backoff:       100 * time.Millisecond,
+			LocalFallback: true,
+			Cache: RoutingCacheConfig{
+				TTL:      5 * time.Minute,
+				StaleTTL: 24 * time.Hour,
+			},
+			CircuitBreaker: CircuitBreakerConfig{
+				Timeout:                2 * time.Second,
+				MaxConcurrentRequests:  1000,
+				RequestVolumeThreshold: 20,
+				SleepWindow:            5 * time.Second,
+				ErrorPercentThreshold:  50,
+			},
+		},
+		Inspection: InspectionConfig{
+			QueueSize: 1000,
+		},
+		Handling: HandlingConfig{
+			CheckSequence:   true,
+			DuplicateWindow: 5 * time.Minute,
+		},
+		Tracking: TrackingConfig{
+			StreamHistory: 1000,
+			TokenTTL:      30 * 24 * time.Hour,
+		},
+		Metrics: MetricsConfig{
+			Enabled:       true,
+			Path:          "/metrics",
+			SummaryMaxAge: 15 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: LogFormatLogfmt,
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingNone,
+			Endpoint:    "http://localhost:4318/v1/traces",
+			ServiceName: "goddd",
+			SampleRatio: 1,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+			AllowedHeaders: []string{"Origin", "Content-Type", "X-Request-ID", "Idempotency-Key", "traceparent", "Last-Event-ID"},
+			ExposedHeaders: []string{"X-Request-ID", "Idempotent-Replayed"},
+		},
+	}
+}
+
+// Load returns the configuration given by the defaults, the configuration
+// file, the environment and the command-line arguments, in that order. The
+// configuration flags are registered on fs, which may hold additional flags
+// defined by the caller.
+func Load(fs *flag.FlagSet, args []string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+
+	path := fs.String("config", getenv("GODDD_CONFIG"), "path to YAML configuration file")
+	cfg.registerFlags(fs)
+
+	// Parse once to find the configuration file, then again after the file
+	// and the environment have been applied so that flags take precedence.
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg = Default()
+
+	if *path != "" {
+		if err := cfg.readFile(*path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.applyEnv(getenv); err != nil {
+		return Config{}, err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+func (c *Config) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.HTTP.Addr, "http.addr", c.HTTP.Addr, "HTTP listen address")
+	fs.DurationVar(&c.HTTP.ShutdownDelay, "http.shutdowndelay", c.HTTP.ShutdownDelay, "time between becoming unready and draining connections on shutdown")
+	fs.DurationVar(&c.HTTP.ShutdownTimeout, "http.shutdowntimeout", c.HTTP.ShutdownTimeout, "maximum time to drain connections and queued work on shutdown")
+	fs.DurationVar(&c.HTTP.HealthCheckTimeout, "http.healthchecktimeout", c.HTTP.HealthCheckTimeout, "maximum time to wait for dependencies in health checks")
+	fs.BoolVar(&c.HTTP.ValidateRequests, "http.validaterequests", c.HTTP.ValidateRequests, "reject requests not matching the OpenAPI specifications")
+	fs.DurationVar(&c.HTTP.IdempotencyTTL, "http.idempotencyttl", c.HTTP.IdempotencyTTL, "how long responses are kept for replay of requests with an Idempotency-Key")
+
+	fs.StringVar(&c.GRPC.Addr, "grpc.addr", c.GRPC.Addr, "gRPC listen address (empty to disable)")
+
+	fs.StringVar(&c.Storage.Backend, "storage.backend", c.Storage.Backend, "storage backend (mongo, inmem)")
+	fs.Var(inmemFlag{&c.Storage.Backend}, "inmem", "use in-memory repositories")
+	fs.StringVar(&c.Storage.Mongo.URL, "db.url", c.Storage.Mongo.URL, "MongoDB URL")
+	fs.StringVar(&c.Storage.Mongo.Database, "db.name", c.Storage.Mongo.Database, "MongoDB database name")
+	fs.IntVar(&c.Storage.Mongo.MaxPoolSize, "db.maxpoolsize", c.Storage.Mongo.MaxPoolSize, "MongoDB maximum connection pool size")
+	fs.IntVar(&c.Storage.Mongo.PaddingBytes, "db.padding", c.Storage.Mongo.PaddingBytes, "size in bytes of cargo padding documents, 0 disables padding")
+
+	fs.StringVar(&c.Routing.Backend, "routing.backend", c.Routing.Backend, "routing backend (pathfinder)")
+	fs.StringVar(&c.Routing.URL, "service.routing", c.Routing.URL, "routing service URL")
+	fs.StringVar(&c.Routing.Path, "routing.path", c.Routing.Path, "path of the routes resource of the routing service")
+	fs.DurationVar(&c.Routing.Timeout, "routing.totaltimeout", c.Routing.Timeout, "routing request timeout, retries included")
+	fs.IntVar(&c.Routing.Retries, "routing.retries", c.Routing.Retries, "number of retries of failed routing requests")
+	fs.DurationVar(&c.Routing.Backoff, "routing.backoff", c.Routing.Backoff, "wait before the first retry of a routing request, doubled for each one after")
+	fs.BoolVar(&c.Routing.LocalFallback, "routing.localfallback", c.Routing.LocalFallback, "route over known voyage schedules when the routing service fails")
+	fs.DurationVar(&c.Routing.Cache.TTL, "routing.cachettl", c.Routing.Cache.TTL, "time to serve cached routes (0 disables caching)")
+	fs.DurationVar(&c.Routing.Cache.StaleTTL, "routing.stalettl", c.Routing.Cache.StaleTTL, "time to keep cached routes as a last resort")
+	fs.DurationVar(&c.Routing.CircuitBreaker.Timeout, "routing.timeout", c.Routing.CircuitBreaker.Timeout, "routing service request timeout")
+	fs.IntVar(&c.Routing.CircuitBreaker.MaxConcurrentRequests, "routing.maxconcurrent", c.Routing.CircuitBreaker.MaxConcurrentRequests, "maximum concurrent requests to the routing service")
+	fs.IntVar(&c.Routing.CircuitBreaker.RequestVolumeThreshold, "routing.volumethreshold", c.Routing.CircuitBreaker.RequestVolumeThreshold, "minimum number of requests before the circuit can trip")
+	fs.DurationVar(&c.Routing.CircuitBreaker.SleepWindow, "routing.sleepwindow", c.Routing.CircuitBreaker.SleepWindow, "time to wait before probing an open circuit")
+	fs.IntVar(&c.Routing.CircuitBreaker.ErrorPercentThreshold, "routing.errorthreshold", c.Routing.CircuitBreaker.ErrorPercentThreshold, "error percentage that trips the circuit")
+
+	fs.IntVar(&c.Inspection.QueueSize, "inspection.queuesize", c.Inspection.QueueSize, "number of handling events that can wait for inspection")
+
+	fs.BoolVar(&c.Handling.CheckSequence, "handling.checksequence", c.Handling.CheckSequence, "reject handling events inconsistent with the handling history")
+	fs.DurationVar(&c.Handling.DuplicateWindow, "handling.duplicatewindow", c.Handling.DuplicateWindow, "time within which identical handling events are considered duplicates")
+
+	fs.IntVar(&c.Tracking.StreamHistory, "tracking.streamhistory", c.Tracking.StreamHistory, "number of tracking updates kept for streaming clients to resume from")
+	fs.StringVar(&c.Tracking.TokenKey, "tracking.tokenkey", c.Tracking.TokenKey, "secret key signing public tracking links")
+	fs.DurationVar(&c.Tracking.TokenTTL, "tracking.tokenttl", c.Tracking.TokenTTL, "time public tracking links are valid for")
+
+	fs.BoolVar(&c.Metrics.Enabled, "metrics.enabled", c.Metrics.Enabled, "expose Prometheus metrics")
+	fs.StringVar(&c.Metrics.Path, "metrics.path", c.Metrics.Path, "HTTP path of the Prometheus metrics")
+	fs.DurationVar(&c.Metrics.SummaryMaxAge, "metrics.maxage", c.Metrics.SummaryMaxAge, "sliding window of latency summaries")
+
+	fs.StringVar(&c.Logging.Level, "log.level", c.Logging.Level, "log level (debug, info, warn, error)")
+	fs.StringVar(&c.Logging.Format, "log.format", c.Logging.Format, "log format (logfmt, json)")
+
+	fs.StringVar(&c.Tracing.Exporter, "tracing.exporter", c.Tracing.Exporter, "trace exporter (none, stdout, otlp)")
+	fs.StringVar(&c.Tracing.Endpoint, "tracing.endpoint", c.Tracing.Endpoint, "OTLP/HTTP traces endpoint")
+	fs.StringVar(&c.Tracing.ServiceName, "tracing.servicename", c.Tracing.ServiceName, "service name reported in traces")
+	fs.Float64Var(&c.Tracing.SampleRatio, "tracing.sampleratio", c.Tracing.SampleRatio, "fraction of new traces to record")
+
+	fs.Var((*listFlag)(&c.CORS.AllowedOrigins), "cors.origins", "comma-separated list of allowed CORS origins")
+	fs.Var((*listFlag)(&c.CORS.AllowedMethods), "cors.methods", "comma-separated list of allowed CORS methods")
+	fs.Var((*listFlag)(&c.CORS.AllowedHeaders), "cors.headers", "comma-separated list of allowed CORS request headers")
+	fs.Var((*listFlag)(&c.CORS.ExposedHeaders), "cors.exposedheaders", "comma-separated list of CORS response headers exposed to clients")
+	fs.BoolVar(&c.CORS.AllowCredentials, "cors.credentials", c.CORS.AllowCredentials, "allow credentials in CORS requests")
+	fs.DurationVar(&c.CORS.MaxAge, "cors.maxage", c.CORS.MaxAge, "how long clients may cache CORS preflight responses")
+}
+
+func (c *Config) applyEnv(getenv func(string) string) error {
+	var err error
+
+	setString := func(env string, dst *string) {
+		if v := getenv(env); v != "" {
+			*dst = v
+		}
+	}
+	setInt := func(env string, dst *int) {
+		if v := getenv(env); v != "" && err == nil {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				err = fmt.Errorf("%s: %v", env, err)
+			}
+		}
+	}
+	setDuration := func(env string, dst *time.Duration) {
+		if v := getenv(env); v != "" && err == nil {
+			if *dst, err = time.ParseDuration(v); err != nil {
+				err = fmt.Errorf("%s: %v", env, err)
+			}
+		}
+	}
+	setBool := func(env string, dst *bool) {
+		if v := getenv(env); v != "" && err == nil {
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				err = fmt.Errorf("%s: %v", env, err)
+			}
+		}
+	}
+	setFloat := func(env string, dst *float64) {
+		if v := getenv(env); v != "" && err == nil {
+			if *dst, err = strconv.ParseFloat(v, 64); err != nil {
+				err = fmt.Errorf("%s: %v", env, err)
+			}
+		}
+	}
+	setList := func(env string, dst *[]string) {
+		if v := getenv(env); v != "" {
+			*dst = splitList(v)
+		}
+	}
+
+	if v := getenv("PORT"); v != "" {
+		c.HTTP.Addr = ":" + v
+	}
+	setDuration("SHUTDOWN_DELAY", &c.HTTP.ShutdownDelay)
+	setDuration("SHUTDOWN_TIMEOUT", &c.HTTP.ShutdownTimeout)
+	setDuration("HEALTH_CHECK_TIMEOUT", &c.HTTP.HealthCheckTimeout)
+	setBool("VALIDATE_REQUESTS", &c.HTTP.ValidateRequests)
+	setDuration("IDEMPOTENCY_TTL", &c.HTTP.IdempotencyTTL)
+
+	if v := getenv("GRPC_PORT"); v != "" {
+		c.GRPC.Addr = ":" + v
+	}
+
+	setString("STORAGE_BACKEND", &c.Storage.Backend)
+	setString("MONGODB_URL", &c.Storage.Mongo.URL)
+	setString("DB_NAME", &c.Storage.Mongo.Database)
+	setInt("MONGO_MAXPOOLSIZE", &c.Storage.Mongo.MaxPoolSize)
+	setInt("MONGO_PADDING_BYTES", &c.Storage.Mongo.PaddingBytes)
+	if getenv("NO_PADDING") != "" {
+		c.Storage.Mongo.PaddingBytes = 0
+	}
+
+	setString("ROUTING_BACKEND", &c.Routing.Backend)
+	setString("ROUTINGSERVICE_URL", &c.Routing.URL)
+	setString("ROUTING_PATH", &c.Routing.Path)
+	setDuration("ROUTING_TOTAL_TIMEOUT", &c.Routing.Timeout)
+	setInt("ROUTING_RETRIES", &c.Routing.Retries)
+	setDuration("ROUTING_BACKOFF", &c.Routing.Backoff)
+	setBool("ROUTING_LOCAL_FALLBACK", &c.Routing.LocalFallback)
+	setDuration("ROUTING_CACHE_TTL", &c.Routing.Cache.TTL)
+	setDuration("ROUTING_STALE_TTL", &c.Routing.Cache.StaleTTL)
+	setDuration("ROUTING_TIMEOUT", &c.Routing.CircuitBreaker.Timeout)
+	setInt("ROUTING_MAX_CONCURRENT_REQUESTS", &c.Routing.CircuitBreaker.MaxConcurrentRequests)
+	setInt("ROUTING_REQUEST_VOLUME_THRESHOLD", &c.Routing.CircuitBreaker.RequestVolumeThreshold)
+	setDuration("ROUTING_SLEEP_WINDOW", &c.Routing.CircuitBreaker.SleepWindow)
+	setInt("ROUTING_ERROR_PERCENT_THRESHOLD", &c.Routing.CircuitBreaker.ErrorPercentThreshold)
+
+	setInt("INSPECTION_QUEUE_SIZE", &c.Inspection.QueueSize)
+
+	setBool("HANDLING_CHECK_SEQUENCE", &c.Handling.CheckSequence)
+	setDuration("HANDLING_DUPLICATE_WINDOW", &c.Handling.DuplicateWindow)
+
+	setInt("TRACKING_STREAM_HISTORY", &c.Tracking.StreamHistory)
+	setString("TRACKING_TOKEN_KEY", &c.Tracking.TokenKey)
+	setDuration("TRACKING_TOKEN_TTL", &c.Tracking.TokenTTL)
+
+	setBool("METRICS_ENABLED", &c.Metrics.Enabled)
+	setString("METRICS_PATH", &c.Metrics.Path)
+	if v := getenv("BOOKING_MAXAGE"); v != "" && err == nil {
+		var secs int
+		if secs, err = strconv.Atoi(v); err != nil {
+			err = fmt.Errorf("BOOKING_MAXAGE: %v", err)
+		}
+		c.Metrics.SummaryMaxAge = time.Duration(secs) * time.Second
+	}
+
+	setString("LOG_LEVEL", &c.Logging.Level)
+	setString("LOG_FORMAT", &c.Logging.Format)
+
+	setString("TRACING_EXPORTER", &c.Tracing.Exporter)
+	setString("TRACING_ENDPOINT", &c.Tracing.Endpoint)
+	setString("TRACING_SERVICE_NAME", &c.Tracing.ServiceName)
+	setFloat("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	setList("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	setList("CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods)
+	setList("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	setList("CORS_EXPOSED_HEADERS", &c.CORS.ExposedHeaders)
+	setBool("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials)
+	setDuration("CORS_MAX_AGE", &c.CORS.MaxAge)
+
+	return err
+}
+
+// Validate checks that the configuration is complete and consistent.
+func (c Config) Validate() error {
+	var errs []string
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if c.HTTP.Addr == "" {
+		fail("http.addr must not be empty")
+	}
+	if c.GRPC.Addr != "" && c.GRPC.Addr == c.HTTP.Addr {
+		fail("grpc.addr must differ from http.addr")
+	}
+	if c.HTTP.ShutdownDelay < 0 {
+		fail("http.shutdown_delay must not be negative")
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		fail("http.shutdown_timeout must be positive")
+	}
+	if c.HTTP.HealthCheckTimeout <= 0 {
+		fail("http.health_check_timeout must be positive")
+	}
+	if c.HTTP.IdempotencyTTL <= 0 {
+		fail("http.idempotency_ttl must be positive")
+	}
+
+	switch c.Storage.Backend {
+	case StorageInmem:
+	case StorageMongo:
+		if c.Storage.Mongo.URL == "" {
+			fail("storage.mongo.url must not be empty")
+		}
+		if c.Storage.Mongo.Database == "" {
+			fail("storage.mongo.database must not be empty")
+		}
+		if c.Storage.Mongo.MaxPoolSize <= 0 {
+			fail("storage.mongo.max_pool_size must be positive")
+		}
+		if c.Storage.Mongo.PaddingBytes < 0 {
+			fail("storage.mongo.padding_bytes must not be negative")
+		}
+	default:
+		fail("storage.backend: unknown backend %q", c.Storage.Backend)
+	}
+
+	switch c.Routing.Backend {
+	case RoutingPathfinder:
+		if c.Routing.URL == "" {
+			fail("routing.url must not be empty")
+		}
+	default:
+		fail("routing.backend: unknown backend %q", c.Routing.Backend)
+	}
+	if !strings.HasPrefix(c.Routing.Path, "/") {
+		fail("routing.path must start with /")
+	}
+	if c.Routing.Timeout <= 0 {
+		fail("routing.timeout must be positive")
+	}
+	if c.Routing.Retries < 0 {
+		fail("routing.retries must not be negative")
+	}
+	if c.Routing.Backoff < 0 {
+		fail("routing.backoff must not be negative")
+	}
+	if c.Routing.Cache.TTL < 0 {
+		fail("routing.cache.ttl must not be negative")
+	}
+	if c.Routing.Cache.StaleTTL < c.Routing.Cache.TTL {
+		fail("routing.cache.stale_ttl must not be shorter than routing.cache.ttl")
+	}
+
+	cb := c.Routing.CircuitBreaker
+	if cb.Timeout <= 0 {
+		fail("routing.circuit_breaker.timeout must be positive")
+	}
+	if cb.MaxConcurrentRequests <= 0 {
+		fail("routing.circuit_breaker.max_concurrent_requests must be positive")
+	}
+	if cb.RequestVolumeThreshold < 0 {
+		fail("routing.circuit_breaker.request_volume_threshold must not be negative")
+	}
+	if cb.SleepWindow < 0 {
+		fail("routing.circuit_breaker.sleep_window must not be negative")
+	}
+	if cb.ErrorPercentThreshold <= 0 || cb.ErrorPercentThreshold > 100 {
+		fail("routing.circuit_breaker.error_percent_threshold must be between 1 and 100")
+	}
+
+	if c.Inspection.QueueSize < 0 {
+		fail("inspection.queue_size must not be negative")
+	}
+	if c.Handling.DuplicateWindow < 0 {
+		fail("handling.duplicate_window must not be negative")
+	}
+	if c.Tracking.StreamHistory < 0 {
+		fail("tracking.stream_history must not be negative")
+	}
+	if c.Tracking.TokenTTL <= 0 {
+		fail("tracking.token_ttl must be positive")
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		fail("metrics.path must start with /")
+	}
+	if c.Metrics.SummaryMaxAge <= 0 {
+		fail("metrics.summary_max_age must be positive")
+	}
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("logging.level: unknown level %q", c.Logging.Level)
+	}
+
+	switch c.Logging.Format {
+	case LogFormatLogfmt, LogFormatJSON:
+	default:
+		fail("logging.format: unknown format %q", c.Logging.Format)
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingNone, TracingStdout:
+	case TracingOTLP:
+		if c.Tracing.Endpoint == "" {
+			fail("tracing.endpoint must not be empty")
+		}
+	default:
+		fail("tracing.exporter: unknown exporter %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if c.CORS.MaxAge < 0 {
+		fail("cors.max_age must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Encode writes the configuration as YAML to w.
+func (c Config) Encode(w io.Writer) error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// listFlag is a flag.Value for comma-separated lists.
+type listFlag []string
+
+func (f *listFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(s string) error {
+	*f = splitList(s)
+	return nil
+}
+
+// inmemFlag is a boolean flag.Value that selects the in-memory storage
+// backend. It exists for compatibility with the -inmem flag.
+type inmemFlag struct {
+	backend *string
+}
+
+func (f inmemFlag) String() string {
+	if f.backend == nil {
+		return "false"
+	}
+	return strconv.FormatBool(*f.backend == StorageInmem)
+}
+
+func (f inmemFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if v {
+		*f.backend = StorageInmem
+	} else if *f.backend == StorageInmem {
+		*f.backend = StorageMongo
+	}
+	return nil
+}
+
+func (f inmemFlag) IsBoolFlag() bool { return true }
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
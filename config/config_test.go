@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(m map[string]string) func(string) string {
+	return func(key string) string { return m[key] }
+}
+
+func load(args []string, vars map[string]string) (Config, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return Load(fs, args, env(vars))
+}
+
+func writeFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "goddd.yml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("cfg = %#v; want = %#v", cfg, Default())
+	}
+}
+
+func TestLoadLayering(t *testing.T) {
+	path := writeFile(t, `
+http:
+  addr: ":7000"
+storage:
+  backend: inmem
+  mongo:
+    database: fromfile
+routing:
+  url: http://pathfinder:8080
+  circuit_breaker:
+    timeout: 500ms
+logging:
+  format: json
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	vars := map[string]string{
+		"GODDD_CONFIG": path,
+		"DB_NAME":      "fromenv",
+		"NO_PADDING":   "1",
+		"LOG_LEVEL":    "debug",
+	}
+
+	cfg, err := load([]string{"-routing.timeout", "3s", "-cors.origins", "http://a.example.com, http://b.example.com"}, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.HTTP.Addr != ":7000" {
+		t.Errorf("cfg.HTTP.Addr = %q; want = %q", cfg.HTTP.Addr, ":7000")
+	}
+	if cfg.Storage.Backend != StorageInmem {
+		t.Errorf("cfg.Storage.Backend = %q; want = %q", cfg.Storage.Backend, StorageInmem)
+	}
+	if cfg.Storage.Mongo.Database != "fromenv" {
+		t.Errorf("cfg.Storage.Mongo.Database = %q; want = %q", cfg.Storage.Mongo.Database, "fromenv")
+	}
+	if cfg.Storage.Mongo.PaddingBytes != 0 {
+		t.Errorf("cfg.Storage.Mongo.PaddingBytes = %d; want = %d", cfg.Storage.Mongo.PaddingBytes, 0)
+	}
+	if cfg.Routing.URL != "http://pathfinder:8080" {
+		t.Errorf("cfg.Routing.URL = %q; want = %q", cfg.Routing.URL, "http://pathfinder:8080")
+	}
+	if cfg.Routing.CircuitBreaker.Timeout != 3*time.Second {
+		t.Errorf("cfg.Routing.CircuitBreaker.Timeout = %s; want = %s", cfg.Routing.CircuitBreaker.Timeout, 3*time.Second)
+	}
+	if cfg.Logging.Level != "debug" {
+		t.Errorf("cfg.Logging.Level = %q; want = %q", cfg.Logging.Level, "debug")
+	}
+	if cfg.Logging.Format != LogFormatJSON {
+		t.Errorf("cfg.Logging.Format = %q; want = %q", cfg.Logging.Format, LogFormatJSON)
+	}
+
+	wantOrigins := []string{"http://a.example.com", "http://b.example.com"}
+	if !reflect.DeepEqual(cfg.CORS.AllowedOrigins, wantOrigins) {
+		t.Errorf("cfg.CORS.AllowedOrigins = %v; want = %v", cfg.CORS.AllowedOrigins, wantOrigins)
+	}
+}
+
+func TestLoadLegacyEnvironment(t *testing.T) {
+	vars := map[string]string{
+		"PORT":               "9090",
+		"ROUTINGSERVICE_URL": "http://routing:7878",
+		"MONGODB_URL":        "mongodb",
+		"MONGO_MAXPOOLSIZE":  "10",
+		"BOOKING_MAXAGE":     "30",
+	}
+
+	cfg, err := load([]string{"-inmem"}, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.HTTP.Addr != ":9090" {
+		t.Errorf("cfg.HTTP.Addr = %q; want = %q", cfg.HTTP.Addr, ":9090")
+	}
+	if cfg.Routing.URL != "http://routing:7878" {
+		t.Errorf("cfg.Routing.URL = %q; want = %q", cfg.Routing.URL, "http://routing:7878")
+	}
+	if cfg.Storage.Mongo.URL != "mongodb" {
+		t.Errorf("cfg.Storage.Mongo.URL = %q; want = %q", cfg.Storage.Mongo.URL, "mongodb")
+	}
+	if cfg.Storage.Mongo.MaxPoolSize != 10 {
+		t.Errorf("cfg.Storage.Mongo.MaxPoolSize = %d; want = %d", cfg.Storage.Mongo.MaxPoolSize, 10)
+	}
+	if cfg.Storage.Backend != StorageInmem {
+		t.Errorf("cfg.Storage.Backend = %q; want = %q", cfg.Storage.Backend, StorageInmem)
+	}
+	if cfg.Metrics.SummaryMaxAge != 30*time.Second {
+		t.Errorf("cfg.Metrics.SummaryMaxAge = %s; want = %s", cfg.Metrics.SummaryMaxAge, 30*time.Second)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		args []string
+		vars map[string]string
+		want string
+	}{
+		{args: []string{"-storage.backend", "postgres"}, want: "storage.backend"},
+		{args: []string{"-routing.errorthreshold", "101"}, want: "error_percent_threshold"},
+		{args: []string{"-routing.path", "paths"}, want: "routing.path"},
+		{args: []string{"-routing.stalettl", "1m"}, want: "routing.cache.stale_ttl"},
+		{vars: map[string]string{"ROUTING_RETRIES": "-1"}, want: "routing.retries"},
+		{args: []string{"-log.format", "xml"}, want: "logging.format"},
+		{args: []string{"-tracing.exporter", "jaeger"}, want: "tracing.exporter"},
+		{args: []string{"-grpc.addr", ":8080"}, want: "grpc.addr"},
+		{vars: map[string]string{"TRACING_SAMPLE_RATIO": "2"}, want: "tracing.sample_ratio"},
+		{vars: map[string]string{"MONGO_MAXPOOLSIZE": "many"}, want: "MONGO_MAXPOOLSIZE"},
+	}
+
+	for _, tt := range tests {
+		_, err := load(tt.args, tt.vars)
+		if err == nil {
+			t.Errorf("load(%v, %v) should fail", tt.args, tt.vars)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("err = %q; want to contain %q", err, tt.want)
+		}
+	}
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	path := writeFile(t, "storage:\n  backnd: inmem\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	if _, err := load([]string{"-config", path}, nil); err == nil {
+		t.Error("unknown fields should be rejected")
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	want := Default()
+	want.CORS.ExposedHeaders = []string{"X-Request-ID"}
+	want.CORS.MaxAge = 10 * time.Minute
+
+	var buf bytes.Buffer
+	if err := want.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeFile(t, buf.String())
+	defer os.RemoveAll(filepath.Dir(path))
+
+	got, err := load([]string{"-config", path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %#v; want = %#v", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Package cors provides a configurable Cross-Origin Resource Sharing policy
+// for the HTTP APIs.
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Policy describes which cross-origin requests are allowed.
+type Policy struct {
+	// AllowedOrigins is a list of origins that may access the resources. A
+	// single "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods is a list of methods the client is allowed to use. The
+	// methods actually announced in a preflight response are further limited
+	// to the ones registered for the requested route.
+	AllowedMethods []string
+
+	// AllowedHeaders is a list of non-simple headers the client is allowed
+	// to use.
+	AllowedHeaders []string
+
+	// ExposedHeaders is a list of response headers the client is allowed to
+	// read.
+	ExposedHeaders []string
+
+	// AllowCredentials indicates whether the request can include user
+	// credentials like cookies or HTTP authentication.
+	AllowCredentials bool
+
+	// MaxAge indicates how long the results of a preflight request can be
+	// cached. Zero means that no Access-Control-Max-Age header is sent.
+	MaxAge time.Duration
+}
+
+// AllowsOrigin reports whether requests from origin are allowed.
+func (p Policy) AllowsOrigin(origin string) bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteMatcher reports whether a request matches a registered route. It is
+// implemented by *mux.Router.
+type RouteMatcher interface {
+	Match(*http.Request, *mux.RouteMatch) bool
+}
+
+// Routes is a list of route matchers that matches a request if any of its
+// matchers do.
+type Routes []RouteMatcher
+
+// Match implements RouteMatcher.
+func (rs Routes) Match(r *http.Request, m *mux.RouteMatch) bool {
+	for _, rm := range rs {
+		if rm.Match(r, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHandler returns a handler that applies the policy to requests before
+// passing them on to h. Preflight requests are answered directly, using
+// routes to determine which methods are available for the requested
+// resource.
+func NewHandler(p Policy, routes RouteMatcher, h http.Handler) http.Handler {
+	return &handler{policy: p, routes: routes, next: h}
+}
+
+type handler struct {
+	policy Policy
+	routes RouteMatcher
+	next   http.Handler
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+
+	if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
+		h.preflight(w, r, origin)
+		return
+	}
+
+	if origin != "" && h.policy.AllowsOrigin(origin) {
+		h.setOrigin(w, origin)
+		if len(h.policy.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(h.policy.ExposedHeaders, ", "))
+		}
+	}
+
+	h.next.ServeHTTP(w, r)
+}
+
+func (h *handler) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if origin == "" || !h.policy.AllowsOrigin(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	methods := h.routeMethods(r)
+	if len(methods) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	reqMethod := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !contains(methods, reqMethod) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	reqHeaders := parseList(r.Header.Get("Access-Control-Request-Headers"))
+	for _, hdr := range reqHeaders {
+		if !h.headerAllowed(hdr) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	h.setOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(h.policy.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(h.policy.AllowedHeaders, ", "))
+	}
+	if h.policy.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(h.policy.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// routeMethods returns the allowed methods that are registered for the path
+// of the request.
+func (h *handler) routeMethods(r *http.Request) []string {
+	var methods []string
+	for _, m := range h.policy.AllowedMethods {
+		m = strings.ToUpper(m)
+
+		req := *r
+		req.Method = m
+
+		if h.routes.Match(&req, &mux.RouteMatch{}) {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+func (h *handler) setOrigin(w http.ResponseWriter, origin string) {
+	if contains(h.policy.AllowedOrigins, "*") && !h.policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+	if h.policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (h *handler) headerAllowed(header string) bool {
+	for _, hdr := range h.policy.AllowedHeaders {
+		if hdr == "*" || strings.EqualFold(hdr, header) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func parseList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
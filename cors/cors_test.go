@@ -0,0 +1,153 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var anyOrigin = Policy{
+	AllowedOrigins: []string{"*"},
+	AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+	AllowedHeaders: []string{"Origin", "Content-Type"},
+}
+
+func newTestHandler(p Policy) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := mux.NewRouter()
+	r.Handle("/booking/v1/cargos", ok).Methods("GET", "POST")
+	r.Handle("/booking/v1/cargos/{id}", ok).Methods("GET")
+	r.Handle("/booking/v1/cargos/{id}", ok).Methods("DELETE")
+
+	return NewHandler(p, Routes{r}, r)
+}
+
+func preflight(h http.Handler, path, origin, method, headers string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("OPTIONS", "http://example.com"+path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestPreflightDelete(t *testing.T) {
+	h := newTestHandler(anyOrigin)
+
+	rec := preflight(h, "/booking/v1/cargos/ABC123", "http://app.example.com", "DELETE", "content-type")
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusNoContent)
+	}
+	if got, want := rec.Header().Get("Access-Control-Allow-Methods"), "GET, DELETE"; got != want {
+		t.Errorf("Access-Control-Allow-Methods = %q; want = %q", got, want)
+	}
+	if got, want := rec.Header().Get("Access-Control-Allow-Origin"), "*"; got != want {
+		t.Errorf("Access-Control-Allow-Origin = %q; want = %q", got, want)
+	}
+}
+
+func TestPreflightMethodNotRegistered(t *testing.T) {
+	h := newTestHandler(anyOrigin)
+
+	rec := preflight(h, "/booking/v1/cargos", "http://app.example.com", "DELETE", "")
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q; want = %q", got, "")
+	}
+}
+
+func TestPreflightUnknownRoute(t *testing.T) {
+	h := newTestHandler(anyOrigin)
+
+	rec := preflight(h, "/no/such/route", "http://app.example.com", "GET", "")
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestPreflightRestrictedOrigin(t *testing.T) {
+	h := newTestHandler(Policy{
+		AllowedOrigins:   []string{"http://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	rec := preflight(h, "/booking/v1/cargos", "http://evil.example.com", "POST", "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = preflight(h, "/booking/v1/cargos", "http://app.example.com", "POST", "X-Custom")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = preflight(h, "/booking/v1/cargos", "http://app.example.com", "POST", "Content-Type")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusNoContent)
+	}
+
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "http://app.example.com",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "Content-Type",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "600",
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q; want = %q", k, got, v)
+		}
+	}
+}
+
+func TestActualRequest(t *testing.T) {
+	h := newTestHandler(Policy{
+		AllowedOrigins: []string{"http://app.example.com"},
+		AllowedMethods: []string{"GET"},
+		ExposedHeaders: []string{"X-Request-ID"},
+	})
+
+	req, _ := http.NewRequest("GET", "http://example.com/booking/v1/cargos", nil)
+	req.Header.Set("Origin", "http://app.example.com")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+	if got, want := rec.Header().Get("Access-Control-Allow-Origin"), "http://app.example.com"; got != want {
+		t.Errorf("Access-Control-Allow-Origin = %q; want = %q", got, want)
+	}
+	if got, want := rec.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID"; got != want {
+		t.Errorf("Access-Control-Expose-Headers = %q; want = %q", got, want)
+	}
+
+	req.Header.Set("Origin", "http://evil.example.com")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q; want = %q", got, "")
+	}
+}
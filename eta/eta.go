@@ -0,0 +1,273 @@
+// Package eta estimates when cargos arrive at their destination, from how
+// they have been handled so far and how late voyages and ports have been in
+// the past.
+package eta
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// z is the standard score bounding 90% confidence intervals, assuming
+// delays are normally distributed.
+const z = 1.645
+
+// minSamples is the number of delays observed for a voyage or port before
+// they are relied on.
+const minSamples = 3
+
+// Estimate is an estimated time of arrival, along with the interval the
+// cargo arrives within with 90% confidence. The zero value means there is no
+// estimate, e.g. for cargos that are not on track.
+type Estimate struct {
+	ETA      time.Time
+	Earliest time.Time
+	Latest   time.Time
+}
+
+// delays keeps the mean and variance of delays, in seconds, as they are
+// added, using Welford's algorithm.
+type delays struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (d *delays) add(x float64) {
+	d.n++
+	delta := x - d.mean
+	d.mean += delta / float64(d.n)
+	d.m2 += delta * (x - d.mean)
+}
+
+func (d *delays) variance() float64 {
+	if d.n < 2 {
+		return 0
+	}
+	return d.m2 / float64(d.n-1)
+}
+
+type voyageKey struct {
+	voyage   voyage.Number
+	activity cargo.HandlingEventType
+}
+
+type portKey struct {
+	port     location.UNLocode
+	activity cargo.HandlingEventType
+}
+
+// Estimator learns how late voyages and ports tend to be from the handling
+// events of all cargos, comparing when cargos were loaded and unloaded with
+// the times planned in their itineraries.
+//
+// Estimator implements handling.EventHandler.
+type Estimator struct {
+	cargos cargo.Repository
+
+	mtx     sync.RWMutex
+	voyages map[voyageKey]*delays
+	ports   map[portKey]*delays
+}
+
+// NewEstimator returns an estimator looking up the itineraries of handled
+// cargos in cargos.
+func NewEstimator(cargos cargo.Repository) *Estimator {
+	return &Estimator{
+		cargos:  cargos,
+		voyages: make(map[voyageKey]*delays),
+		ports:   make(map[portKey]*delays),
+	}
+}
+
+// Seed learns from the handling histories of existing cargos, e.g. those
+// already stored when the application starts.
+func (e *Estimator) Seed(cs []*cargo.Cargo, histories map[cargo.TrackingID]cargo.HandlingHistory) {
+	for _, c := range cs {
+		for _, ev := range histories[c.TrackingID].HandlingEvents {
+			e.observe(c.Itinerary, ev)
+		}
+	}
+}
+
+// CargoWasHandled learns from the delay of the event, if it loads or unloads
+// the cargo as planned in its itinerary. Events of legs without a planned
+// time are ignored, as they have no delay to learn from.
+func (e *Estimator) CargoWasHandled(ctx context.Context, ev cargo.HandlingEvent) {
+	c, err := e.cargos.Find(ctx, ev.TrackingID)
+	if err != nil {
+		return
+	}
+	e.observe(c.Itinerary, ev)
+}
+
+func (e *Estimator) observe(itinerary cargo.Itinerary, ev cargo.HandlingEvent) {
+	i, ok := legOf(itinerary, ev)
+	if !ok || ev.CompletionTime.IsZero() {
+		return
+	}
+
+	p := planned(itinerary.Legs[i], ev.Activity.Type)
+	if p.IsZero() {
+		return
+	}
+
+	delay := ev.CompletionTime.Sub(p).Seconds()
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	vk := voyageKey{voyage: ev.Activity.VoyageNumber, activity: ev.Activity.Type}
+	if e.voyages[vk] == nil {
+		e.voyages[vk] = &delays{}
+	}
+	e.voyages[vk].add(delay)
+
+	pk := portKey{port: ev.Activity.Location, activity: ev.Activity.Type}
+	if e.ports[pk] == nil {
+		e.ports[pk] = &delays{}
+	}
+	e.ports[pk].add(delay)
+}
+
+// expected returns the mean and variance of the delay of an activity, in
+// seconds. The delays of its voyage are relied on over those of its port, as
+// a voyage running late tends to be late in every port.
+func (e *Estimator) expected(a cargo.HandlingActivity) (mean, variance float64) {
+	if e == nil {
+		return 0, 0
+	}
+
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+
+	if d := e.voyages[voyageKey{voyage: a.VoyageNumber, activity: a.Type}]; d != nil && d.n >= minSamples {
+		return d.mean, d.variance()
+	}
+	if d := e.ports[portKey{port: a.Location, activity: a.Type}]; d != nil && d.n >= minSamples {
+		return d.mean, d.variance()
+	}
+	return 0, 0
+}
+
+// Estimate estimates when the cargo arrives at its destination, from its
+// handling history. The loads and unloads yet to happen are expected to be
+// as late as is usual for their voyage or port, but no earlier than the
+// cargo can make them: a cargo loaded late is unloaded late, and is not
+// loaded onto the next voyage before being unloaded off the last.
+//
+// There is no estimate if any of the loads and unloads yet to happen has no
+// planned time, as for legs routed without a schedule.
+//
+// A nil Estimator has learned nothing, and expects what is yet to happen to
+// happen as planned.
+func (e *Estimator) Estimate(c *cargo.Cargo, h cargo.HandlingHistory) Estimate {
+	if !c.Delivery.IsOnTrack() {
+		return Estimate{}
+	}
+
+	legs := c.Itinerary.Legs
+
+	// The activities of the itinerary are numbered in order, the load of
+	// leg i being 2i and the unload 2i+1. Find the last one that has
+	// happened, and when.
+	var (
+		last = -1
+		at   time.Time
+	)
+	for _, ev := range h.HandlingEvents {
+		i, ok := legOf(c.Itinerary, ev)
+		if !ok {
+			continue
+		}
+		k := 2 * i
+		if ev.Activity.Type == cargo.Unload {
+			k++
+		}
+		if k >= last {
+			last, at = k, ev.CompletionTime
+		}
+	}
+
+	t := at
+	var variance float64
+	for k := last + 1; k < 2*len(legs); k++ {
+		l := legs[k/2]
+
+		a := cargo.HandlingActivity{Type: cargo.Load, Location: l.LoadLocation, VoyageNumber: l.VoyageNumber}
+		if k%2 == 1 {
+			a = cargo.HandlingActivity{Type: cargo.Unload, Location: l.UnloadLocation, VoyageNumber: l.VoyageNumber}
+		}
+
+		p := planned(l, a.Type)
+		if p.IsZero() {
+			return Estimate{}
+		}
+
+		mean, v := e.expected(a)
+
+		next := p.Add(seconds(mean))
+		if !t.IsZero() {
+			earliest := t
+			if a.Type == cargo.Unload && !l.LoadTime.IsZero() {
+				earliest = t.Add(l.UnloadTime.Sub(l.LoadTime))
+			}
+			if next.Before(earliest) {
+				next = earliest
+			}
+		}
+
+		t = next
+		variance += v
+	}
+
+	margin := seconds(z * math.Sqrt(variance))
+
+	est := Estimate{
+		ETA:      t.Round(time.Second),
+		Earliest: t.Add(-margin).Round(time.Second),
+		Latest:   t.Add(margin).Round(time.Second),
+	}
+
+	// The cargo cannot arrive before it was last handled.
+	if est.Earliest.Before(at) {
+		est.Earliest = at
+	}
+
+	return est
+}
+
+// legOf returns the index of the leg of the itinerary that the event loads
+// the cargo onto or unloads it off of.
+func legOf(itinerary cargo.Itinerary, ev cargo.HandlingEvent) (int, bool) {
+	for i, l := range itinerary.Legs {
+		if l.VoyageNumber != ev.Activity.VoyageNumber {
+			continue
+		}
+		switch {
+		case ev.Activity.Type == cargo.Load && l.LoadLocation == ev.Activity.Location:
+			return i, true
+		case ev.Activity.Type == cargo.Unload && l.UnloadLocation == ev.Activity.Location:
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// planned returns the planned time of the load or unload of the leg.
+func planned(l cargo.Leg, t cargo.HandlingEventType) time.Time {
+	if t == cargo.Load {
+		return l.LoadTime
+	}
+	return l.UnloadTime
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,222 @@
+package eta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+var day = time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// newCargo returns a cargo routed from Stockholm to Hong Kong by way of
+// Helsinki, arriving on the sixth day.
+func newCargo(id cargo.TrackingID) *cargo.Cargo {
+	c := cargo.New(id, cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.CNHKG,
+	})
+	c.AssignToRoute(cargo.Itinerary{Legs: []cargo.Leg{
+		cargo.NewLeg(voyage.V100.Number, location.SESTO, location.FIHEL, day, day.Add(48*time.Hour)),
+		cargo.NewLeg(voyage.V300.Number, location.FIHEL, location.CNHKG, day.Add(72*time.Hour), day.Add(120*time.Hour)),
+	}})
+	return c
+}
+
+func event(t cargo.HandlingEventType, l location.UNLocode, v voyage.Number, completed time.Time) cargo.HandlingEvent {
+	return cargo.HandlingEvent{
+		Activity:       cargo.HandlingActivity{Type: t, Location: l, VoyageNumber: v},
+		CompletionTime: completed,
+	}
+}
+
+func history(events ...cargo.HandlingEvent) cargo.HandlingHistory {
+	return cargo.HandlingHistory{HandlingEvents: events}
+}
+
+func TestEstimateAsPlanned(t *testing.T) {
+	c := newCargo("TEST")
+
+	var e *Estimator
+
+	got := e.Estimate(c, cargo.HandlingHistory{})
+
+	want := Estimate{ETA: day.Add(120 * time.Hour), Earliest: day.Add(120 * time.Hour), Latest: day.Add(120 * time.Hour)}
+	if got != want {
+		t.Errorf("Estimate = %+v; want = %+v", got, want)
+	}
+}
+
+func TestEstimateNotOnTrack(t *testing.T) {
+	c := cargo.New("TEST", cargo.RouteSpecification{Origin: location.SESTO, Destination: location.CNHKG})
+
+	if got := NewEstimator(nil).Estimate(c, cargo.HandlingHistory{}); got != (Estimate{}) {
+		t.Errorf("Estimate = %+v; want = %+v", got, Estimate{})
+	}
+}
+
+func TestEstimateFromHandlingHistory(t *testing.T) {
+	c := newCargo("TEST")
+
+	var e *Estimator
+
+	tests := []struct {
+		name string
+		h    cargo.HandlingHistory
+		want time.Time
+	}{
+		{
+			name: "delay absorbed in port",
+			h: history(
+				event(cargo.Load, location.SESTO, voyage.V100.Number, day.Add(2*time.Hour)),
+			),
+			want: day.Add(120 * time.Hour),
+		},
+		{
+			name: "delay missing the planned departure",
+			h: history(
+				event(cargo.Load, location.SESTO, voyage.V100.Number, day.Add(30*time.Hour)),
+			),
+			want: day.Add(126 * time.Hour),
+		},
+		{
+			name: "unloaded late",
+			h: history(
+				event(cargo.Load, location.SESTO, voyage.V100.Number, day),
+				event(cargo.Unload, location.FIHEL, voyage.V100.Number, day.Add(48*time.Hour)),
+				event(cargo.Load, location.FIHEL, voyage.V300.Number, day.Add(72*time.Hour)),
+				event(cargo.Unload, location.CNHKG, voyage.V300.Number, day.Add(121*time.Hour)),
+			),
+			want: day.Add(121 * time.Hour),
+		},
+		{
+			name: "unexpected events ignored",
+			h: history(
+				event(cargo.Receive, location.SESTO, "", day.Add(-time.Hour)),
+				event(cargo.Load, location.SESTO, voyage.V400.Number, day.Add(30*time.Hour)),
+			),
+			want: day.Add(120 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		got := e.Estimate(c, tt.h)
+		if !got.ETA.Equal(tt.want) {
+			t.Errorf("%s: ETA = %v; want = %v", tt.name, got.ETA, tt.want)
+		}
+		if !got.Earliest.Equal(tt.want) || !got.Latest.Equal(tt.want) {
+			t.Errorf("%s: interval = [%v, %v]; want = [%v, %v]", tt.name, got.Earliest, got.Latest, tt.want, tt.want)
+		}
+	}
+}
+
+func TestEstimateWithoutPlannedTimes(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+		c := newCargo(id)
+		c.Itinerary.Legs[1].LoadTime = time.Time{}
+		c.Itinerary.Legs[1].UnloadTime = time.Time{}
+		return c, nil
+	}
+
+	e := NewEstimator(&cargos)
+
+	for _, id := range []cargo.TrackingID{"A", "B", "C"} {
+		ev := event(cargo.Unload, location.CNHKG, voyage.V300.Number, day.Add(121*time.Hour))
+		ev.TrackingID = id
+		e.CargoWasHandled(context.Background(), ev)
+	}
+
+	if len(e.voyages) != 0 || len(e.ports) != 0 {
+		t.Errorf("learned delays of unplanned legs: voyages = %v, ports = %v", e.voyages, e.ports)
+	}
+
+	c, _ := cargos.Find(context.Background(), "TEST")
+
+	if got := e.Estimate(c, history(
+		event(cargo.Load, location.SESTO, voyage.V100.Number, day),
+	)); got != (Estimate{}) {
+		t.Errorf("Estimate = %+v; want = %+v", got, Estimate{})
+	}
+
+	// Once the unplanned leg has been sailed, the cargo has arrived.
+	arrived := day.Add(121 * time.Hour)
+	got := e.Estimate(c, history(
+		event(cargo.Load, location.FIHEL, voyage.V300.Number, day.Add(72*time.Hour)),
+		event(cargo.Unload, location.CNHKG, voyage.V300.Number, arrived),
+	))
+	if want := (Estimate{ETA: arrived, Earliest: arrived, Latest: arrived}); got != want {
+		t.Errorf("Estimate = %+v; want = %+v", got, want)
+	}
+}
+
+func TestEstimateFromLearnedDelays(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+		return newCargo(id), nil
+	}
+
+	e := NewEstimator(&cargos)
+
+	// Voyage V300 has unloaded 1, 2 and 3 hours late, a mean of 2 hours
+	// with a standard deviation of 1 hour.
+	past := []*cargo.Cargo{newCargo("A"), newCargo("B")}
+	e.Seed(past, map[cargo.TrackingID]cargo.HandlingHistory{
+		"A": history(event(cargo.Unload, location.CNHKG, voyage.V300.Number, day.Add(121*time.Hour))),
+		"B": history(event(cargo.Unload, location.CNHKG, voyage.V300.Number, day.Add(122*time.Hour))),
+	})
+
+	c := newCargo("TEST")
+
+	// Two samples are too few to rely on.
+	if got := e.Estimate(c, cargo.HandlingHistory{}); !got.ETA.Equal(day.Add(120 * time.Hour)) {
+		t.Errorf("ETA = %v; want = %v", got.ETA, day.Add(120*time.Hour))
+	}
+
+	ev := event(cargo.Unload, location.CNHKG, voyage.V300.Number, day.Add(123*time.Hour))
+	ev.TrackingID = "C"
+	e.CargoWasHandled(context.Background(), ev)
+
+	got := e.Estimate(c, history(
+		event(cargo.Load, location.SESTO, voyage.V100.Number, day),
+	))
+
+	margin := time.Duration(z * float64(time.Hour)).Round(time.Second)
+
+	want := Estimate{
+		ETA:      day.Add(122 * time.Hour),
+		Earliest: day.Add(122 * time.Hour).Add(-margin),
+		Latest:   day.Add(122 * time.Hour).Add(margin),
+	}
+	if got != want {
+		t.Errorf("Estimate = %+v; want = %+v", got, want)
+	}
+}
+
+func TestEstimateFallsBackOnPort(t *testing.T) {
+	e := NewEstimator(nil)
+
+	// Loads in Helsinki have been 4 hours late, whatever the voyage.
+	var past []*cargo.Cargo
+	histories := make(map[cargo.TrackingID]cargo.HandlingHistory)
+	for _, id := range []cargo.TrackingID{"A", "B", "C"} {
+		c := cargo.New(id, cargo.RouteSpecification{Origin: location.FIHEL, Destination: location.DEHAM})
+		c.AssignToRoute(cargo.Itinerary{Legs: []cargo.Leg{
+			cargo.NewLeg(voyage.V400.Number, location.FIHEL, location.DEHAM, day, day.Add(24*time.Hour)),
+		}})
+		past = append(past, c)
+		histories[id] = history(event(cargo.Load, location.FIHEL, voyage.V400.Number, day.Add(4*time.Hour)))
+	}
+	e.Seed(past, histories)
+
+	// Leaving Helsinki 4 hours late on the fourth day arrives in Hong Kong
+	// 4 hours late.
+	got := e.Estimate(newCargo("TEST"), cargo.HandlingHistory{})
+	if want := day.Add(124 * time.Hour); !got.ETA.Equal(want) {
+		t.Errorf("ETA = %v; want = %v", got.ETA, want)
+	}
+}
@@ -0,0 +1,118 @@
+// Package graph provides a GraphQL API over the cargos, locations and voyages
+// of the booking, tracking and handling services, so that views can fetch a
+// cargo along with its route, voyages and tracking in a single request.
+// Related objects are loaded in batches, once per request, rather than once
+// for each object referring to them.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/marcusolsson/goddd/httpctx"
+	"github.com/marcusolsson/goddd/requestid"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+// MaxRequestSize is the maximum size of a request body.
+const MaxRequestSize = 1 << 20
+
+// MakeHandler returns a router serving GraphQL requests on /graphql. It
+// panics if the schema is invalid.
+func MakeHandler(ctx context.Context, s Services, logger kitlog.Logger) *mux.Router {
+	schema, err := newSchema(s)
+	if err != nil {
+		panic(err)
+	}
+
+	h := &handler{
+		ctx:    ctx,
+		schema: schema,
+		s:      s,
+		logger: logger,
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/graphql", h).Methods("POST")
+
+	return r
+}
+
+type handler struct {
+	ctx    context.Context
+	schema graphql.Schema
+	s      Services
+	logger kitlog.Logger
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize)).Decode(&req); err != nil {
+		h.logger.Log("err", err)
+		encodeResult(w, http.StatusBadRequest, &graphql.Result{
+			Errors: gqlerrors.FormatErrors(err),
+		})
+		return
+	}
+
+	text, err := tracking.TextFromRequest(r)
+	if err != nil {
+		encodeResult(w, http.StatusBadRequest, &graphql.Result{
+			Errors: gqlerrors.FormatErrors(err),
+		})
+		return
+	}
+
+	ctx := httpctx.FromRequest(h.ctx, r)
+	ctx = requestid.HTTPToContext(ctx, r)
+	ctx = withLoaders(ctx, h.s.newLoaders())
+	ctx = withText(ctx, text)
+
+	res := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+
+	status := http.StatusOK
+	if !executed(res) {
+		status = http.StatusBadRequest
+	}
+
+	encodeResult(w, status, res)
+}
+
+// executed reports whether the request was executed, as opposed to rejected
+// for being malformed or invalid against the schema. Errors raised while
+// executing have the path of the field they were raised for.
+func executed(res *graphql.Result) bool {
+	if res.Data != nil {
+		return true
+	}
+	for _, err := range res.Errors {
+		if len(err.Path) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeResult(w http.ResponseWriter, status int, res *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,368 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/tracking"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type countingLocationRepository struct {
+	location.Repository
+	findAll int
+}
+
+func (r *countingLocationRepository) FindAll(ctx context.Context) ([]*location.Location, error) {
+	r.findAll++
+	return r.Repository.FindAll(ctx)
+}
+
+type countingVoyageRepository struct {
+	voyage.Repository
+	find map[voyage.Number]int
+}
+
+func (r *countingVoyageRepository) Find(ctx context.Context, n voyage.Number) (*voyage.Voyage, error) {
+	r.find[n]++
+	return r.Repository.Find(ctx, n)
+}
+
+type countingCargoRepository struct {
+	cargo.Repository
+	find, findMany int
+}
+
+func (r *countingCargoRepository) Find(ctx context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+	r.find++
+	return r.Repository.Find(ctx, id)
+}
+
+func (r *countingCargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	r.findMany++
+	return r.Repository.FindMany(ctx, ids)
+}
+
+type nopEventHandler struct{}
+
+func (nopEventHandler) CargoWasHandled(context.Context, cargo.HandlingEvent) {}
+
+type fixture struct {
+	handler   http.Handler
+	cargos    cargo.Repository
+	tracked   *countingCargoRepository
+	locations *countingLocationRepository
+	voyages   *countingVoyageRepository
+}
+
+func newFixture() *fixture {
+	var (
+		cargos         = inmem.NewCargoRepository()
+		tracked        = &countingCargoRepository{Repository: cargos}
+		handlingEvents = inmem.NewHandlingEventRepository()
+		locations      = &countingLocationRepository{Repository: inmem.NewLocationRepository()}
+		voyages        = &countingVoyageRepository{Repository: inmem.NewVoyageRepository(), find: make(map[voyage.Number]int)}
+	)
+
+	ef := cargo.HandlingEventFactory{
+		CargoRepository:    cargos,
+		VoyageRepository:   voyages,
+		LocationRepository: locations,
+	}
+
+	s := Services{
+		Booking:   booking.NewService(cargos, locations, handlingEvents, nil, nil, nil),
+		Tracking:  tracking.NewService(tracked, handlingEvents, locations, nil, nil),
+		Handling:  handling.NewService(handlingEvents, ef, nopEventHandler{}, handling.DefaultRules()),
+		Locations: locations,
+		Voyages:   voyages,
+	}
+
+	return &fixture{
+		handler:   MakeHandler(context.Background(), s, kitlog.NewNopLogger()),
+		cargos:    cargos,
+		tracked:   tracked,
+		locations: locations,
+		voyages:   voyages,
+	}
+}
+
+// do executes the query and decodes the response into v.
+func (f *fixture) do(t *testing.T, query string, variables map[string]interface{}, v interface{}) *httptest.ResponseRecorder {
+	b, err := json.Marshal(request{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(b))))
+
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func (f *fixture) store(t *testing.T, id cargo.TrackingID, legs ...cargo.Leg) {
+	c := cargo.New(id, cargo.RouteSpecification{
+		Origin:          location.SESTO,
+		Destination:     location.CNHKG,
+		ArrivalDeadline: time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC),
+	})
+	c.AssignToRoute(cargo.Itinerary{Legs: legs})
+
+	if err := f.cargos.Store(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryCargosLoadsRelationsInBatches(t *testing.T) {
+	f := newFixture()
+
+	legs := []cargo.Leg{
+		cargo.NewLeg(voyage.V100.Number, location.SESTO, location.AUMEL, time.Time{}, time.Time{}),
+		cargo.NewLeg(voyage.V300.Number, location.AUMEL, location.CNHKG, time.Time{}, time.Time{}),
+	}
+	f.store(t, "ABC123", legs...)
+	f.store(t, "DEF456", legs...)
+	f.store(t, "GHI789", legs[0])
+
+	var resp struct {
+		Data struct {
+			Cargos []struct {
+				TrackingID string `json:"trackingId"`
+				Origin     struct {
+					Name string `json:"name"`
+				} `json:"origin"`
+				Legs []struct {
+					From struct {
+						Locode string `json:"locode"`
+					} `json:"from"`
+					Voyage struct {
+						Number    string `json:"number"`
+						Movements []struct {
+							ArrivalLocation struct {
+								Name string `json:"name"`
+							} `json:"arrivalLocation"`
+						} `json:"movements"`
+					} `json:"voyage"`
+				} `json:"legs"`
+				Tracking struct {
+					StatusText string `json:"statusText"`
+					Status     struct {
+						Transport string `json:"transport"`
+					} `json:"status"`
+					Legs []struct {
+						Status string `json:"status"`
+					} `json:"legs"`
+					ActiveLeg *int `json:"activeLeg"`
+				} `json:"tracking"`
+			} `json:"cargos"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+
+	f.do(t, `{
+		cargos {
+			trackingId
+			origin { name }
+			legs {
+				from { locode }
+				voyage { number movements { arrivalLocation { name } } }
+			}
+			tracking { statusText status { transport } legs { status } activeLeg }
+		}
+	}`, nil, &resp)
+
+	if len(resp.Errors) > 0 {
+		t.Fatalf("resp.Errors = %v", resp.Errors)
+	}
+	if len(resp.Data.Cargos) != 3 {
+		t.Fatalf("len(cargos) = %d; want = 3", len(resp.Data.Cargos))
+	}
+
+	for _, c := range resp.Data.Cargos {
+		if c.Origin.Name != "Stockholm" {
+			t.Errorf("%s: origin.name = %q; want = %q", c.TrackingID, c.Origin.Name, "Stockholm")
+		}
+		if c.Tracking.StatusText == "" {
+			t.Errorf("%s: tracking.statusText is empty", c.TrackingID)
+		}
+		if c.Tracking.Status.Transport != "NOT_RECEIVED" {
+			t.Errorf("%s: tracking.status.transport = %q; want = %q", c.TrackingID, c.Tracking.Status.Transport, "NOT_RECEIVED")
+		}
+		if len(c.Legs) == 0 || c.Legs[0].Voyage.Number != string(voyage.V100.Number) {
+			t.Errorf("%s: legs = %v; want first leg on %s", c.TrackingID, c.Legs, voyage.V100.Number)
+		}
+		if len(c.Tracking.Legs) != len(c.Legs) || c.Tracking.Legs[0].Status != "PENDING" {
+			t.Errorf("%s: tracking.legs = %v; want %d pending legs", c.TrackingID, c.Tracking.Legs, len(c.Legs))
+		}
+		if a := c.Tracking.ActiveLeg; a == nil || *a != 0 {
+			t.Errorf("%s: tracking.activeLeg = %v; want = 0", c.TrackingID, a)
+		}
+	}
+
+	if f.tracked.findMany != 1 || f.tracked.find != 0 {
+		t.Errorf("tracked cargos found with %d calls to FindMany and %d to Find; want a single call to FindMany", f.tracked.findMany, f.tracked.find)
+	}
+	if f.locations.findAll != 1 {
+		t.Errorf("locations.FindAll called %d times; want = 1", f.locations.findAll)
+	}
+	for n, calls := range f.voyages.find {
+		if calls != 1 {
+			t.Errorf("voyages.Find(%s) called %d times; want = 1", n, calls)
+		}
+	}
+}
+
+func TestQueryUnknownCargo(t *testing.T) {
+	f := newFixture()
+
+	var resp struct {
+		Data struct {
+			Cargo *struct{} `json:"cargo"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+
+	f.do(t, `{ cargo(trackingId: "XYZ") { trackingId } }`, nil, &resp)
+
+	if len(resp.Errors) > 0 {
+		t.Errorf("resp.Errors = %v", resp.Errors)
+	}
+	if resp.Data.Cargo != nil {
+		t.Errorf("cargo = %v; want = null", resp.Data.Cargo)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	f := newFixture()
+
+	var booked struct {
+		Data struct {
+			BookCargo struct {
+				TrackingID  string `json:"trackingId"`
+				Destination struct {
+					Locode string `json:"locode"`
+				} `json:"destination"`
+				Routed bool `json:"routed"`
+			} `json:"bookCargo"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+
+	f.do(t, `mutation Book($deadline: DateTime!) {
+		bookCargo(origin: "SESTO", destination: "CNHKG", arrivalDeadline: $deadline) {
+			trackingId
+			destination { locode }
+			routed
+		}
+	}`, map[string]interface{}{"deadline": "2030-03-01T00:00:00Z"}, &booked)
+
+	if len(booked.Errors) > 0 {
+		t.Fatalf("booked.Errors = %v", booked.Errors)
+	}
+
+	c := booked.Data.BookCargo
+	if c.TrackingID == "" {
+		t.Fatal("trackingId is empty")
+	}
+	if c.Destination.Locode != "CNHKG" {
+		t.Errorf("destination.locode = %q; want = %q", c.Destination.Locode, "CNHKG")
+	}
+
+	var registered struct {
+		Data struct {
+			RegisterHandlingEvent struct {
+				Events []struct {
+					Description string `json:"description"`
+					Type        string `json:"type"`
+					Location    struct {
+						Name string `json:"name"`
+					} `json:"location"`
+				} `json:"events"`
+			} `json:"registerHandlingEvent"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+
+	f.do(t, `mutation Register($id: ID!) {
+		registerHandlingEvent(trackingId: $id, location: "SESTO", eventType: RECEIVE, completionTime: "2030-01-01T00:00:00Z") {
+			events { description type location { name } }
+		}
+	}`, map[string]interface{}{"id": c.TrackingID}, &registered)
+
+	if len(registered.Errors) > 0 {
+		t.Fatalf("registered.Errors = %v", registered.Errors)
+	}
+	events := registered.Data.RegisterHandlingEvent.Events
+	if len(events) != 1 {
+		t.Fatalf("events = %v; want one event", events)
+	}
+	if events[0].Type != "RECEIVE" || events[0].Location.Name != "Stockholm" {
+		t.Errorf("events[0] = %+v; want RECEIVE in Stockholm", events[0])
+	}
+}
+
+func TestMutationErrorCodes(t *testing.T) {
+	f := newFixture()
+	f.store(t, "ABC123")
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`mutation { changeDestination(trackingId: "XYZ", destination: "AUMEL") { trackingId } }`, CodeNotFound},
+		{`mutation { registerHandlingEvent(trackingId: "ABC123", location: "SESTO", eventType: CLAIM, completionTime: "2030-01-01T00:00:00Z") { statusText } }`, CodeConflict},
+	}
+
+	for _, tt := range tests {
+		var resp struct {
+			Errors []struct {
+				Message    string `json:"message"`
+				Extensions struct {
+					Code string `json:"code"`
+				} `json:"extensions"`
+			} `json:"errors"`
+		}
+
+		f.do(t, tt.query, nil, &resp)
+
+		if len(resp.Errors) != 1 {
+			t.Errorf("%s: errors = %v; want one error", tt.query, resp.Errors)
+			continue
+		}
+		if got := resp.Errors[0].Extensions.Code; got != tt.want {
+			t.Errorf("%s: code = %q; want = %q (%s)", tt.query, got, tt.want, resp.Errors[0].Message)
+		}
+	}
+}
+
+func TestInvalidQuery(t *testing.T) {
+	f := newFixture()
+
+	var resp struct {
+		Errors []interface{} `json:"errors"`
+	}
+
+	rec := f.do(t, `{ cargos { noSuchField } }`, nil, &resp)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusBadRequest)
+	}
+	if len(resp.Errors) == 0 {
+		t.Errorf("resp.Errors is empty")
+	}
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"sync"
+)
+
+// batchFunc loads the values for keys. It returns a value and an error for
+// each key, at the same index. Keys without a value have a nil value and no
+// error.
+type batchFunc func(ctx context.Context, keys []string) ([]interface{}, []error)
+
+// loader batches the loads requested while resolving one level of a query
+// into a single call to its batch function, and caches the results for the
+// rest of the request.
+//
+// Resolvers return the thunk returned by load. The executor resolves all
+// fields at a level before calling their thunks, so by the time the first
+// thunk is called, the keys of the whole level are pending.
+type loader struct {
+	batch batchFunc
+
+	mu      sync.Mutex
+	pending []string
+	cache   map[string]*result
+}
+
+type result struct {
+	value interface{}
+	err   error
+}
+
+func newLoader(batch batchFunc) *loader {
+	return &loader{
+		batch: batch,
+		cache: make(map[string]*result),
+	}
+}
+
+// load schedules key to be loaded and returns a thunk returning its value.
+func (l *loader) load(ctx context.Context, key string) func() (interface{}, error) {
+	l.mu.Lock()
+	r, ok := l.cache[key]
+	if !ok {
+		r = &result{}
+		l.cache[key] = r
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.dispatch(ctx)
+		return r.value, r.err
+	}
+}
+
+// dispatch loads the pending keys, if any.
+func (l *loader) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) == 0 {
+		return
+	}
+
+	keys := l.pending
+	l.pending = nil
+
+	values, errs := l.batch(ctx, keys)
+	for i, key := range keys {
+		r := l.cache[key]
+		r.value, r.err = values[i], errs[i]
+	}
+}
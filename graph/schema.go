@@ -0,0 +1,872 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/tracking"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// Services holds the services and repositories the schema is resolved
+// against.
+type Services struct {
+	Booking   booking.Service
+	Tracking  tracking.Service
+	Handling  handling.Service
+	Locations location.Repository
+	Voyages   voyage.Repository
+}
+
+// loaders batch the loads of related objects during a single request.
+type loaders struct {
+	locations *loader
+	voyages   *loader
+	tracking  *loader
+}
+
+func (s Services) newLoaders() *loaders {
+	return &loaders{
+		locations: newLoader(s.locationLoader()),
+		voyages:   newLoader(s.loadVoyages),
+		tracking:  newLoader(s.loadTracking),
+	}
+}
+
+// locationLoader returns a batch function that loads all locations once,
+// since there are few of them and the repository can't look up several by
+// locode.
+func (s Services) locationLoader() batchFunc {
+	var byLocode map[location.UNLocode]*location.Location
+
+	return func(ctx context.Context, keys []string) ([]interface{}, []error) {
+		errs := make([]error, len(keys))
+
+		if byLocode == nil {
+			ls, err := s.Locations.FindAll(ctx)
+			if err != nil {
+				for i := range errs {
+					errs[i] = err
+				}
+				return make([]interface{}, len(keys)), errs
+			}
+
+			byLocode = make(map[location.UNLocode]*location.Location)
+			for _, l := range ls {
+				byLocode[l.UNLocode] = l
+			}
+		}
+
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			if l, ok := byLocode[location.UNLocode(key)]; ok {
+				values[i] = l
+			}
+		}
+		return values, errs
+	}
+}
+
+// loadVoyages looks up each voyage once, however many legs refer to it.
+func (s Services) loadVoyages(ctx context.Context, keys []string) ([]interface{}, []error) {
+	values := make([]interface{}, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		v, err := s.Voyages.Find(ctx, voyage.Number(key))
+		switch {
+		case err == voyage.ErrUnknown:
+		case err != nil:
+			errs[i] = err
+		default:
+			values[i] = v
+		}
+	}
+	return values, errs
+}
+
+// loadTracking tracks the cargos in batches of at most tracking.MaxTrackMany.
+func (s Services) loadTracking(ctx context.Context, keys []string) ([]interface{}, []error) {
+	values := make([]interface{}, len(keys))
+	errs := make([]error, len(keys))
+
+	// Leave out empty tracking IDs, so that they do not fail the batches
+	// they would otherwise be part of.
+	var ids []string
+	for i, key := range keys {
+		if key == "" {
+			errs[i] = tracking.ErrInvalidArgument
+			continue
+		}
+		ids = append(ids, key)
+	}
+
+	found := make(map[string]tracking.Cargo, len(ids))
+	failed := make(map[string]error)
+
+	for start := 0; start < len(ids); start += tracking.MaxTrackMany {
+		end := start + tracking.MaxTrackMany
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		cs, err := s.Tracking.TrackMany(ctx, ids[start:end])
+		if err != nil {
+			for _, id := range ids[start:end] {
+				failed[id] = err
+			}
+			continue
+		}
+		for id, c := range cs {
+			found[id] = c
+		}
+	}
+
+	for i, key := range keys {
+		if err, ok := failed[key]; ok {
+			errs[i] = err
+		} else if c, ok := found[key]; ok {
+			values[i] = textFrom(ctx).Describe(c)
+		}
+	}
+
+	return values, errs
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+type textKey struct{}
+
+// withText returns a context describing tracked cargos with t.
+func withText(ctx context.Context, t tracking.Text) context.Context {
+	return context.WithValue(ctx, textKey{}, t)
+}
+
+// textFrom returns the Text of the request, or English if there is none.
+func textFrom(ctx context.Context) tracking.Text {
+	t, _ := ctx.Value(textKey{}).(tracking.Text)
+	return t
+}
+
+// newSchema returns the schema over the cargos, locations and voyages of s.
+func newSchema(s Services) (graphql.Schema, error) {
+	locationType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Location",
+		Description: "A location on the route of a cargo.",
+		Fields: graphql.Fields{
+			"locode": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The UN location code.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(*location.Location).UNLocode), nil
+				},
+			},
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+		},
+	})
+
+	// resolveLocation returns a resolver loading the location returned by f.
+	resolveLocation := func(f func(src interface{}) location.UNLocode) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			return loadersFrom(p.Context).locations.load(p.Context, string(f(p.Source))), nil
+		}
+	}
+
+	movementType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CarrierMovement",
+		Description: "A movement of a carrier from one location to another.",
+		Fields: graphql.Fields{
+			"departureLocation": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveLocation(func(src interface{}) location.UNLocode {
+					return src.(voyage.CarrierMovement).DepartureLocation
+				}),
+			},
+			"arrivalLocation": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveLocation(func(src interface{}) location.UNLocode {
+					return src.(voyage.CarrierMovement).ArrivalLocation
+				}),
+			},
+			"departureTime": &graphql.Field{Type: graphql.DateTime},
+			"arrivalTime":   &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	transportModeValues := graphql.EnumValueConfigMap{}
+	for _, m := range voyage.Modes {
+		transportModeValues[strings.ToUpper(m.String())] = &graphql.EnumValueConfig{Value: m}
+	}
+
+	transportModeEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:   "TransportMode",
+		Values: transportModeValues,
+	})
+
+	voyageType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Voyage",
+		Description: "A series of carrier movements.",
+		Fields: graphql.Fields{
+			"number": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(*voyage.Voyage).Number), nil
+				},
+			},
+			"mode": &graphql.Field{
+				Type: graphql.NewNonNull(transportModeEnum),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*voyage.Voyage).Mode, nil
+				},
+			},
+			"movements": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(movementType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*voyage.Voyage).Schedule.CarrierMovements, nil
+				},
+			},
+		},
+	})
+
+	legType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Leg",
+		Description: "A leg of an itinerary, carried by a single voyage.",
+		Fields: graphql.Fields{
+			"voyageNumber": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(cargo.Leg).VoyageNumber), nil
+				},
+			},
+			"mode": &graphql.Field{
+				Type: graphql.NewNonNull(transportModeEnum),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(cargo.Leg).Mode, nil
+				},
+			},
+			"voyage": &graphql.Field{
+				Type: voyageType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(cargo.Leg).VoyageNumber
+					return loadersFrom(p.Context).voyages.load(p.Context, string(n)), nil
+				},
+			},
+			"from": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveLocation(func(src interface{}) location.UNLocode {
+					return src.(cargo.Leg).LoadLocation
+				}),
+			},
+			"to": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveLocation(func(src interface{}) location.UNLocode {
+					return src.(cargo.Leg).UnloadLocation
+				}),
+			},
+			"loadTime":   &graphql.Field{Type: graphql.DateTime},
+			"unloadTime": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	itineraryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Itinerary",
+		Fields: graphql.Fields{
+			"legs": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(legType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nonNilLegs(p.Source.(cargo.Itinerary).Legs), nil
+				},
+			},
+		},
+	})
+
+	// The values of the enum are the upper-cased codes of the registered
+	// event types, e.g. GATE_IN.
+	handlingEventTypeValues := graphql.EnumValueConfigMap{}
+	for _, t := range cargo.HandlingEventTypes() {
+		s, _ := t.Spec()
+		handlingEventTypeValues[strings.ToUpper(s.Code)] = &graphql.EnumValueConfig{Value: t}
+	}
+
+	handlingEventTypeEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:   "HandlingEventType",
+		Values: handlingEventTypeValues,
+	})
+
+	// resolveTrackedLocation returns a resolver loading the location of a
+	// tracking read model returned by f.
+	resolveTrackedLocation := func(f func(src interface{}) tracking.Location) graphql.FieldResolveFn {
+		return resolveLocation(func(src interface{}) location.UNLocode {
+			return location.UNLocode(f(src).Code)
+		})
+	}
+
+	// resolveVoyage returns a resolver loading the voyage returned by f, if
+	// any.
+	resolveVoyage := func(f func(src interface{}) string) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			n := f(p.Source)
+			if n == "" {
+				return nil, nil
+			}
+			return loadersFrom(p.Context).voyages.load(p.Context, n), nil
+		}
+	}
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TrackingEvent",
+		Description: "A handling event of a cargo.",
+		Fields: graphql.Fields{
+			"type": &graphql.Field{
+				Type: graphql.NewNonNull(handlingEventTypeEnum),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return handlingEventTypes[p.Source.(tracking.Event).Type], nil
+				},
+			},
+			"location": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveTrackedLocation(func(src interface{}) tracking.Location {
+					return src.(tracking.Event).Location
+				}),
+			},
+			"voyage": &graphql.Field{
+				Type: voyageType,
+				Resolve: resolveVoyage(func(src interface{}) string {
+					return src.(tracking.Event).VoyageNumber
+				}),
+			},
+			"completionTime":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"registrationTime": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"expected":         &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"description":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	activityType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "HandlingActivity",
+		Description: "The handling activity expected next.",
+		Fields: graphql.Fields{
+			"type": &graphql.Field{
+				Type: graphql.NewNonNull(handlingEventTypeEnum),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return handlingEventTypes[p.Source.(*tracking.Activity).Type], nil
+				},
+			},
+			"location": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveTrackedLocation(func(src interface{}) tracking.Location {
+					return src.(*tracking.Activity).Location
+				}),
+			},
+			"voyage": &graphql.Field{
+				Type: voyageType,
+				Resolve: resolveVoyage(func(src interface{}) string {
+					return src.(*tracking.Activity).VoyageNumber
+				}),
+			},
+		},
+	})
+
+	transportStatusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TransportStatus",
+		Values: graphql.EnumValueConfigMap{
+			"NOT_RECEIVED":    &graphql.EnumValueConfig{Value: tracking.StatusNotReceived},
+			"IN_PORT":         &graphql.EnumValueConfig{Value: tracking.StatusInPort},
+			"ONBOARD_CARRIER": &graphql.EnumValueConfig{Value: tracking.StatusOnboardCarrier},
+			"CLAIMED":         &graphql.EnumValueConfig{Value: tracking.StatusClaimed},
+			"UNKNOWN":         &graphql.EnumValueConfig{Value: tracking.StatusUnknown},
+		},
+	})
+
+	statusType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TransportProgress",
+		Description: "Where a cargo is on its route.",
+		Fields: graphql.Fields{
+			"transport": &graphql.Field{Type: graphql.NewNonNull(transportStatusEnum)},
+			"lastKnownLocation": &graphql.Field{
+				Type:        locationType,
+				Description: "The last known location, once the cargo has been received.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					l := p.Source.(tracking.Status).LastKnownLocation
+					if l == nil {
+						return nil, nil
+					}
+					return loadersFrom(p.Context).locations.load(p.Context, l.Code), nil
+				},
+			},
+			"currentVoyage": &graphql.Field{
+				Type:        voyageType,
+				Description: "The voyage the cargo is onboard, if any.",
+				Resolve: resolveVoyage(func(src interface{}) string {
+					return src.(tracking.Status).CurrentVoyage
+				}),
+			},
+			"misdirected": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	legStatusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "LegStatus",
+		Values: graphql.EnumValueConfigMap{
+			"PENDING":   &graphql.EnumValueConfig{Value: tracking.LegPending},
+			"LOADED":    &graphql.EnumValueConfig{Value: tracking.LegLoaded},
+			"COMPLETED": &graphql.EnumValueConfig{Value: tracking.LegCompleted},
+			"SKIPPED":   &graphql.EnumValueConfig{Value: tracking.LegSkipped},
+		},
+	})
+
+	trackedLegType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TrackedLeg",
+		Description: "A leg of the itinerary of a cargo, with how far the cargo has come on it.",
+		Fields: graphql.Fields{
+			"voyage": &graphql.Field{
+				Type: voyageType,
+				Resolve: resolveVoyage(func(src interface{}) string {
+					return src.(tracking.Leg).VoyageNumber
+				}),
+			},
+			"mode": &graphql.Field{
+				Type: graphql.NewNonNull(transportModeEnum),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					m, _ := voyage.ParseMode(p.Source.(tracking.Leg).Mode)
+					return m, nil
+				},
+			},
+			"from": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveTrackedLocation(func(src interface{}) tracking.Location {
+					return src.(tracking.Leg).From
+				}),
+			},
+			"to": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveTrackedLocation(func(src interface{}) tracking.Location {
+					return src.(tracking.Leg).To
+				}),
+			},
+			"loadTime":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"unloadTime": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"status":     &graphql.Field{Type: graphql.NewNonNull(legStatusEnum)},
+		},
+	})
+
+	trackingType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Tracking",
+		Description: "The progress of a cargo, as shown to customers.",
+		Fields: graphql.Fields{
+			"status": &graphql.Field{Type: graphql.NewNonNull(statusType)},
+			"nextActivity": &graphql.Field{
+				Type:        activityType,
+				Description: "The handling activity expected next, if any.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if a := p.Source.(tracking.Cargo).NextActivity; a != nil {
+						return a, nil
+					}
+					return nil, nil
+				},
+			},
+			"statusText":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"nextExpectedActivity": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"eta": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "The estimated time of arrival, if the cargo is on track.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return optionalTime(p.Source.(tracking.Cargo).ETA), nil
+				},
+			},
+			"etaEarliest": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "The earliest the cargo is expected to arrive, with 90% confidence.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return optionalTime(p.Source.(tracking.Cargo).ETAEarliest), nil
+				},
+			},
+			"etaLatest": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "The latest the cargo is expected to arrive, with 90% confidence.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return optionalTime(p.Source.(tracking.Cargo).ETALatest), nil
+				},
+			},
+			"events": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if events := p.Source.(tracking.Cargo).Events; events != nil {
+						return events, nil
+					}
+					return []tracking.Event{}, nil
+				},
+			},
+			"legs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(trackedLegType))),
+				Description: "The itinerary, which is empty until the cargo has been routed.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if legs := p.Source.(tracking.Cargo).Legs; legs != nil {
+						return legs, nil
+					}
+					return []tracking.Leg{}, nil
+				},
+			},
+			"progress": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "The percentage of the itinerary travelled.",
+			},
+			"activeLeg": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "The index of the leg the cargo is onboard, or else of the next leg it is to be loaded onto, if any.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if i := p.Source.(tracking.Cargo).ActiveLeg; i != nil {
+						return *i, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	cargoType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Cargo",
+		Description: "A booked cargo.",
+		Fields: graphql.Fields{
+			"trackingId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"origin": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveLocation(func(src interface{}) location.UNLocode {
+					return location.UNLocode(src.(booking.Cargo).Origin)
+				}),
+			},
+			"destination": &graphql.Field{
+				Type: locationType,
+				Resolve: resolveLocation(func(src interface{}) location.UNLocode {
+					return location.UNLocode(src.(booking.Cargo).Destination)
+				}),
+			},
+			"arrivalDeadline": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"misrouted":       &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"routed":          &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"legs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(legType))),
+				Description: "The legs of the itinerary the cargo is assigned to.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nonNilLegs(p.Source.(booking.Cargo).Legs), nil
+				},
+			},
+			"tracking": &graphql.Field{
+				Type: trackingType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Source.(booking.Cargo).TrackingID
+					return loadersFrom(p.Context).tracking.load(p.Context, id), nil
+				},
+			},
+			"routeCandidates": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itineraryType))),
+				Description: "Possible routes for the cargo, from the routing service.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := cargo.TrackingID(p.Source.(booking.Cargo).TrackingID)
+					routes, err := s.Booking.RequestPossibleRoutesForCargo(p.Context, id)
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					return routes, nil
+				},
+			},
+		},
+	})
+
+	legInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "LegInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"voyageNumber": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"mode":         &graphql.InputObjectFieldConfig{Type: transportModeEnum, Description: "Defaults to SEA."},
+			"from":         &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"to":           &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"loadTime":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+			"unloadTime":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	// loadCargo returns the booking view of the cargo after a mutation.
+	loadCargo := func(ctx context.Context, id cargo.TrackingID) (interface{}, error) {
+		c, err := s.Booking.LoadCargo(ctx, id)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		return c, nil
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"cargos": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(cargoType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					cargos, err := s.Booking.Cargos(p.Context)
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					if cargos == nil {
+						cargos = []booking.Cargo{}
+					}
+					return cargos, nil
+				},
+			},
+			"cargo": &graphql.Field{
+				Type: cargoType,
+				Args: graphql.FieldConfigArgument{
+					"trackingId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c, err := s.Booking.LoadCargo(p.Context, cargo.TrackingID(p.Args["trackingId"].(string)))
+					if err == cargo.ErrUnknown {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					return c, nil
+				},
+			},
+			"locations": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(locationType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ls, err := s.Locations.FindAll(p.Context)
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					return ls, nil
+				},
+			},
+			"location": &graphql.Field{
+				Type: locationType,
+				Args: graphql.FieldConfigArgument{
+					"locode": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).locations.load(p.Context, p.Args["locode"].(string)), nil
+				},
+			},
+			"voyage": &graphql.Field{
+				Type: voyageType,
+				Args: graphql.FieldConfigArgument{
+					"number": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).voyages.load(p.Context, p.Args["number"].(string)), nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"bookCargo": &graphql.Field{
+				Type:        graphql.NewNonNull(cargoType),
+				Description: "Books a new cargo, not yet routed.",
+				Args: graphql.FieldConfigArgument{
+					"origin":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"destination":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"arrivalDeadline": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := s.Booking.BookNewCargo(p.Context,
+						location.UNLocode(p.Args["origin"].(string)),
+						location.UNLocode(p.Args["destination"].(string)),
+						p.Args["arrivalDeadline"].(time.Time),
+					)
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					return loadCargo(p.Context, id)
+				},
+			},
+			"unbookCargo": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Removes a cargo that has not yet been handled.",
+				Args: graphql.FieldConfigArgument{
+					"trackingId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.Booking.UnbookCargo(p.Context, cargo.TrackingID(p.Args["trackingId"].(string))); err != nil {
+						return nil, wrapError(err)
+					}
+					return true, nil
+				},
+			},
+			"assignCargoToRoute": &graphql.Field{
+				Type:        graphql.NewNonNull(cargoType),
+				Description: "Assigns a cargo to the route described by the legs, such as one of its route candidates.",
+				Args: graphql.FieldConfigArgument{
+					"trackingId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"legs": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(legInputType))),
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := cargo.TrackingID(p.Args["trackingId"].(string))
+
+					var itinerary cargo.Itinerary
+					for _, v := range p.Args["legs"].([]interface{}) {
+						l := v.(map[string]interface{})
+						leg := cargo.NewLeg(
+							voyage.Number(l["voyageNumber"].(string)),
+							location.UNLocode(l["from"].(string)),
+							location.UNLocode(l["to"].(string)),
+							l["loadTime"].(time.Time),
+							l["unloadTime"].(time.Time),
+						)
+						if m, ok := l["mode"].(voyage.Mode); ok {
+							leg.Mode = m
+						}
+						itinerary.Legs = append(itinerary.Legs, leg)
+					}
+
+					if err := s.Booking.AssignCargoToRoute(p.Context, id, itinerary); err != nil {
+						return nil, wrapError(err)
+					}
+					return loadCargo(p.Context, id)
+				},
+			},
+			"changeDestination": &graphql.Field{
+				Type:        graphql.NewNonNull(cargoType),
+				Description: "Changes the destination of a cargo.",
+				Args: graphql.FieldConfigArgument{
+					"trackingId":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"destination": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := cargo.TrackingID(p.Args["trackingId"].(string))
+					if err := s.Booking.ChangeDestination(p.Context, id, location.UNLocode(p.Args["destination"].(string))); err != nil {
+						return nil, wrapError(err)
+					}
+					return loadCargo(p.Context, id)
+				},
+			},
+			"registerHandlingEvent": &graphql.Field{
+				Type:        graphql.NewNonNull(trackingType),
+				Description: "Registers a handling event, and returns the updated tracking of the cargo.",
+				Args: graphql.FieldConfigArgument{
+					"trackingId":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"location":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"voyage":         &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"eventType":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(handlingEventTypeEnum)},
+					"completionTime": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["trackingId"].(string)
+					voyageNumber, _ := p.Args["voyage"].(string)
+
+					if err := s.Handling.RegisterHandlingEvent(p.Context,
+						p.Args["completionTime"].(time.Time),
+						cargo.TrackingID(id),
+						voyage.Number(voyageNumber),
+						location.UNLocode(p.Args["location"].(string)),
+						p.Args["eventType"].(cargo.HandlingEventType),
+					); err != nil {
+						return nil, wrapError(err)
+					}
+
+					c, err := s.Tracking.Track(p.Context, id)
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					return textFrom(p.Context).Describe(c), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+// nonNilLegs returns legs, or an empty list if legs is nil, since lists are
+// never null in the schema.
+func nonNilLegs(legs []cargo.Leg) []cargo.Leg {
+	if legs == nil {
+		return []cargo.Leg{}
+	}
+	return legs
+}
+
+// handlingEventTypes maps the event types of the tracking read model to the
+// values of the HandlingEventType enum.
+var handlingEventTypes = func() map[string]cargo.HandlingEventType {
+	m := make(map[string]cargo.HandlingEventType)
+	for _, t := range cargo.HandlingEventTypes() {
+		s, _ := t.Spec()
+		m[s.Code] = t
+	}
+	return m
+}()
+
+func optionalTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// Error codes reported in the extensions of errors.
+const (
+	CodeNotFound     = "NOT_FOUND"
+	CodeInvalidInput = "BAD_USER_INPUT"
+	CodeConflict     = "CONFLICT"
+	CodeInternal     = "INTERNAL"
+	CodeUnavailable  = "UNAVAILABLE"
+)
+
+// extendedError adds a code, and for validation errors the invalid fields,
+// to the errors reported to clients.
+type extendedError struct {
+	error
+	extensions map[string]interface{}
+}
+
+func (e extendedError) Extensions() map[string]interface{} { return e.extensions }
+
+// wrapError returns err with the extensions describing it.
+func wrapError(err error) error {
+	var (
+		verr *validation.Error
+		serr *handling.SequenceError
+	)
+
+	ext := map[string]interface{}{"code": CodeInternal}
+	switch {
+	case errors.As(err, &verr):
+		ext["code"] = CodeInvalidInput
+		ext["fields"] = verr.Fields
+	case errors.As(err, &serr):
+		ext["code"] = CodeConflict
+	case err == cargo.ErrUnknown, err == location.ErrUnknown, err == voyage.ErrUnknown:
+		ext["code"] = CodeNotFound
+	case err == booking.ErrInvalidArgument, err == tracking.ErrInvalidArgument, err == handling.ErrInvalidArgument:
+		ext["code"] = CodeInvalidInput
+	case errors.Is(err, routing.ErrUnavailable):
+		ext["code"] = CodeUnavailable
+	}
+
+	return extendedError{error: err, extensions: ext}
+}
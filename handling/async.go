@@ -0,0 +1,105 @@
+package handling
+
+import (
+	"context"
+	"sync"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+// AsyncEventHandler is an EventHandler that notifies another EventHandler on
+// a background goroutine, so that registering an event does not have to wait
+// for e.g. the cargo to be inspected.
+type AsyncEventHandler struct {
+	next    EventHandler
+	events  chan queuedEvent
+	done    chan struct{}
+	closing chan struct{}
+	once    sync.Once
+
+	mtx    sync.RWMutex
+	closed bool
+}
+
+// queuedEvent is an event waiting to be handled, along with the context it
+// was registered in.
+type queuedEvent struct {
+	ctx   context.Context
+	event cargo.HandlingEvent
+}
+
+// NewAsyncEventHandler returns a new AsyncEventHandler forwarding events to
+// next. At most size events are queued before CargoWasHandled blocks.
+func NewAsyncEventHandler(next EventHandler, size int) *AsyncEventHandler {
+	h := &AsyncEventHandler{
+		next:    next,
+		events:  make(chan queuedEvent, size),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+
+	go h.loop()
+
+	return h
+}
+
+func (h *AsyncEventHandler) loop() {
+	defer close(h.done)
+	for e := range h.events {
+		h.next.CargoWasHandled(e.ctx, e.event)
+	}
+}
+
+// CargoWasHandled queues the event. Once the handler is closing, events are
+// handled synchronously, also those waiting for room in a full queue.
+//
+// The event is handled after the request registering it has completed, so
+// only the values of ctx are passed on, not its deadline or cancellation.
+func (h *AsyncEventHandler) CargoWasHandled(ctx context.Context, e cargo.HandlingEvent) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	if h.closed {
+		h.next.CargoWasHandled(ctx, e)
+		return
+	}
+
+	select {
+	case h.events <- queuedEvent{ctx: context.WithoutCancel(ctx), event: e}:
+	case <-h.closing:
+		h.next.CargoWasHandled(ctx, e)
+	}
+}
+
+// Len returns the number of events waiting to be handled.
+func (h *AsyncEventHandler) Len() int {
+	return len(h.events)
+}
+
+// Cap returns the number of events that can be queued.
+func (h *AsyncEventHandler) Cap() int {
+	return cap(h.events)
+}
+
+// Close stops accepting new events and waits for the queued events to be
+// handled, or for the context to be done. The queue is closed once the
+// events being handled synchronously are done, which Close does not wait
+// for beyond the context either.
+func (h *AsyncEventHandler) Close(ctx context.Context) error {
+	h.once.Do(func() {
+		close(h.closing)
+		go func() {
+			h.mtx.Lock()
+			defer h.mtx.Unlock()
+			h.closed = true
+			close(h.events)
+		}()
+	})
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
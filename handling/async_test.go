@@ -0,0 +1,137 @@
+package handling
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+type blockingEventHandler struct {
+	mtx     sync.Mutex
+	release chan struct{}
+	events  []cargo.HandlingEvent
+}
+
+func (h *blockingEventHandler) CargoWasHandled(_ context.Context, e cargo.HandlingEvent) {
+	<-h.release
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.events = append(h.events, e)
+}
+
+func (h *blockingEventHandler) count() int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return len(h.events)
+}
+
+func TestAsyncEventHandlerDrainsOnClose(t *testing.T) {
+	next := &blockingEventHandler{release: make(chan struct{})}
+
+	h := NewAsyncEventHandler(next, 10)
+
+	for i := 0; i < 3; i++ {
+		h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+	}
+
+	if next.count() != 0 {
+		t.Errorf("next.count() = %d; want = %d", next.count(), 0)
+	}
+
+	close(next.release)
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if next.count() != 3 {
+		t.Errorf("next.count() = %d; want = %d", next.count(), 3)
+	}
+	if h.Len() != 0 {
+		t.Errorf("h.Len() = %d; want = %d", h.Len(), 0)
+	}
+
+	// Events after close are handled synchronously.
+	h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+
+	if next.count() != 4 {
+		t.Errorf("next.count() = %d; want = %d", next.count(), 4)
+	}
+}
+
+func TestAsyncEventHandlerCloseTimeout(t *testing.T) {
+	next := &blockingEventHandler{release: make(chan struct{})}
+	defer close(next.release)
+
+	h := NewAsyncEventHandler(next, 10)
+	h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+	h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := h.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err = %v; want = %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestAsyncEventHandlerCloseWithFullQueue(t *testing.T) {
+	next := &blockingEventHandler{release: make(chan struct{})}
+	defer close(next.release)
+
+	h := NewAsyncEventHandler(next, 1)
+
+	// One event being handled, one queued and one waiting for room.
+	h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+	h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+	go h.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: "ABC123"})
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- h.Close(ctx) }()
+
+	select {
+	case err := <-errc:
+		if err != context.DeadlineExceeded {
+			t.Errorf("err = %v; want = %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return when the context was done")
+	}
+}
+
+type contextEventHandler struct {
+	ctxs chan context.Context
+}
+
+func (h *contextEventHandler) CargoWasHandled(ctx context.Context, e cargo.HandlingEvent) {
+	h.ctxs <- ctx
+}
+
+type ctxKey struct{}
+
+func TestAsyncEventHandlerDetachesContext(t *testing.T) {
+	next := &contextEventHandler{ctxs: make(chan context.Context, 1)}
+
+	h := NewAsyncEventHandler(next, 10)
+	defer h.Close(context.Background())
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "abc"))
+	h.CargoWasHandled(ctx, cargo.HandlingEvent{TrackingID: "ABC123"})
+	cancel()
+
+	got := <-next.ctxs
+
+	if v := got.Value(ctxKey{}); v != "abc" {
+		t.Errorf("got.Value() = %v; want = %v", v, "abc")
+	}
+	if got.Err() != nil {
+		t.Errorf("got.Err() = %v; want = %v", got.Err(), nil)
+	}
+}
@@ -28,7 +28,7 @@ func (r registerIncidentResponse) error() error { return r.Err }
 func makeRegisterIncidentEndpoint(hs Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(registerIncidentRequest)
-		err := hs.RegisterHandlingEvent(req.CompletionTime, req.ID, req.Voyage, req.Location, req.EventType)
+		err := hs.RegisterHandlingEvent(ctx, req.CompletionTime, req.ID, req.Voyage, req.Location, req.EventType)
 		return registerIncidentResponse{Err: err}, nil
 	}
 }
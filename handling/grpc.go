@@ -0,0 +1,94 @@
+package handling
+
+import (
+	"context"
+	"errors"
+
+	kitlog "github.com/go-kit/kit/log"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/handling/pb"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// MakeGRPCServer returns a server serving the handling service over gRPC.
+func MakeGRPCServer(ctx context.Context, hs Service, logger kitlog.Logger) pb.HandlingServiceServer {
+	opts := []kitgrpc.ServerOption{
+		kitgrpc.ServerErrorLogger(logger),
+	}
+
+	return &grpcServer{
+		registerHandlingEvent: rpc.NewServer(
+			ctx,
+			makeRegisterIncidentEndpoint(hs),
+			decodeGRPCRegisterIncidentRequest,
+			encodeGRPCRegisterIncidentResponse,
+			opts...,
+		),
+	}
+}
+
+type grpcServer struct {
+	registerHandlingEvent kitgrpc.Handler
+}
+
+func (s *grpcServer) RegisterHandlingEvent(ctx oldcontext.Context, req *pb.RegisterHandlingEventRequest) (*pb.RegisterHandlingEventResponse, error) {
+	_, resp, err := s.registerHandlingEvent.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.RegisterHandlingEventResponse), nil
+}
+
+func decodeGRPCRegisterIncidentRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.RegisterHandlingEventRequest)
+	return registerIncidentRequest{
+		CompletionTime: rpc.Time(req.CompletionTime),
+		ID:             cargo.TrackingID(req.TrackingId),
+		Voyage:         voyage.Number(req.Voyage),
+		Location:       location.UNLocode(req.Location),
+		EventType:      cargo.ParseHandlingEventType(req.EventType),
+	}, nil
+}
+
+func encodeGRPCRegisterIncidentResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(registerIncidentResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return &pb.RegisterHandlingEventResponse{}, nil
+}
+
+// grpcError returns err with the status code of errors from business-logic,
+// so that clients can tell them apart.
+func grpcError(err error) error {
+	return grpc.Errorf(grpcCode(err), "%v", err)
+}
+
+// grpcCode returns the status code of errors from business-logic.
+func grpcCode(err error) codes.Code {
+	var (
+		verr *validation.Error
+		serr *SequenceError
+	)
+
+	switch {
+	case errors.As(err, &verr):
+		return codes.InvalidArgument
+	case errors.As(err, &serr):
+		return codes.FailedPrecondition
+	case err == cargo.ErrUnknown:
+		return codes.NotFound
+	case err == ErrInvalidArgument:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,121 @@
+package handling
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/handling/pb"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+func dialGRPC(t *testing.T, s Service) pb.HandlingServiceClient {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	pb.RegisterHandlingServiceServer(srv, MakeGRPCServer(context.Background(), s, kitlog.NewNopLogger()))
+	go srv.Serve(ln)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+
+	return pb.NewHandlingServiceClient(conn)
+}
+
+func TestGRPCRegisterHandlingEvent(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+		if id != "ABC123" {
+			return nil, cargo.ErrUnknown
+		}
+		return cargo.New(id, cargo.RouteSpecification{}), nil
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(_ context.Context, n voyage.Number) (*voyage.Voyage, error) {
+		return voyage.New(n, voyage.Schedule{}), nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(_ context.Context, l location.UNLocode) (*location.Location, error) {
+		return &location.Location{UNLocode: l}, nil
+	}
+
+	var (
+		history cargo.HandlingHistory
+		events  mock.HandlingEventRepository
+	)
+	events.StoreFn = func(_ context.Context, e cargo.HandlingEvent) error {
+		history.HandlingEvents = append(history.HandlingEvents, e)
+		return nil
+	}
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return history, nil
+	}
+
+	ef := cargo.HandlingEventFactory{
+		CargoRepository:    &cargos,
+		VoyageRepository:   &voyages,
+		LocationRepository: &locations,
+	}
+
+	s := NewService(&events, ef, &stubEventHandler{}, DefaultRules())
+	s = NewValidatingService(&locations, &voyages, s)
+
+	client := dialGRPC(t, s)
+
+	completed := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		req  *pb.RegisterHandlingEventRequest
+		want codes.Code
+	}{
+		{
+			req:  &pb.RegisterHandlingEventRequest{TrackingId: "ABC123", Location: "SESTO", EventType: "Receive", CompletionTime: rpc.Timestamp(completed)},
+			want: codes.OK,
+		},
+		{
+			req:  &pb.RegisterHandlingEventRequest{TrackingId: "ABC123", Location: "SESTO", EventType: "Claim", CompletionTime: rpc.Timestamp(completed.Add(time.Hour))},
+			want: codes.FailedPrecondition,
+		},
+		{
+			req:  &pb.RegisterHandlingEventRequest{TrackingId: "ABC123", Location: "SESTO", EventType: "Load", CompletionTime: rpc.Timestamp(completed.Add(time.Hour))},
+			want: codes.InvalidArgument,
+		},
+		{
+			req:  &pb.RegisterHandlingEventRequest{TrackingId: "XYZ789", Location: "SESTO", EventType: "Receive", CompletionTime: rpc.Timestamp(completed)},
+			want: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := client.RegisterHandlingEvent(context.Background(), tt.req)
+
+		code := grpc.Code(err)
+		if code != tt.want {
+			t.Errorf("%s %s: code = %s; want = %s (err = %v)", tt.req.EventType, tt.req.TrackingId, code, tt.want, err)
+		}
+	}
+
+	if len(history.HandlingEvents) != 1 {
+		t.Errorf("len(history.HandlingEvents) = %d; want = 1", len(history.HandlingEvents))
+	}
+}
@@ -1,6 +1,7 @@
 package handling
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -25,7 +26,7 @@ func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram,
 	}
 }
 
-func (s *instrumentingService) RegisterHandlingEvent(completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
+func (s *instrumentingService) RegisterHandlingEvent(ctx context.Context, completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
 	loc location.UNLocode, eventType cargo.HandlingEventType) error {
 
 	defer func(begin time.Time) {
@@ -33,5 +34,5 @@ func (s *instrumentingService) RegisterHandlingEvent(completed time.Time, id car
 		s.requestLatency.With("method", "register_incident").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.RegisterHandlingEvent(completed, id, voyageNumber, loc, eventType)
+	return s.Service.RegisterHandlingEvent(ctx, completed, id, voyageNumber, loc, eventType)
 }
@@ -1,12 +1,15 @@
 package handling
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
 
 	"github.com/marcusolsson/goddd/cargo"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/requestid"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
@@ -20,10 +23,10 @@ func NewLoggingService(logger log.Logger, s Service) Service {
 	return &loggingService{logger, s}
 }
 
-func (s *loggingService) RegisterHandlingEvent(completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
+func (s *loggingService) RegisterHandlingEvent(ctx context.Context, completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
 	unLocode location.UNLocode, eventType cargo.HandlingEventType) (err error) {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		leveled(requestid.Logger(ctx, s.logger), err).Log(
 			"method", "register_incident",
 			"tracking_id", id,
 			"location", unLocode,
@@ -34,5 +37,14 @@ func (s *loggingService) RegisterHandlingEvent(completed time.Time, id cargo.Tra
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.RegisterHandlingEvent(completed, id, voyageNumber, unLocode, eventType)
+	return s.Service.RegisterHandlingEvent(ctx, completed, id, voyageNumber, unLocode, eventType)
+}
+
+// leveled returns logger at error level if err is set, and at info level
+// otherwise, so that successful calls can be filtered by the log level.
+func leveled(logger log.Logger, err error) log.Logger {
+	if err != nil {
+		return level.Error(logger)
+	}
+	return level.Info(logger)
 }
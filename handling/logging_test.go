@@ -0,0 +1,47 @@
+package handling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type stubService struct {
+	err error
+}
+
+func (s *stubService) RegisterHandlingEvent(context.Context, time.Time, cargo.TrackingID, voyage.Number, location.UNLocode, cargo.HandlingEventType) error {
+	return s.err
+}
+
+func TestLoggingServiceLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := level.New(log.NewLogfmtLogger(&buf), level.Allowed(level.AllowErrorOnly()))
+
+	var stub stubService
+	s := NewLoggingService(logger, &stub)
+
+	if err := s.RegisterHandlingEvent(context.Background(), time.Now(), "ABC123", "V100", "SESTO", cargo.Load); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("logged %q; want successful call suppressed below info level", buf.String())
+	}
+
+	stub.err = errors.New("failed")
+	s.RegisterHandlingEvent(context.Background(), time.Now(), "ABC123", "V100", "SESTO", cargo.Load)
+
+	if got := buf.String(); !strings.Contains(got, "level=error") || !strings.Contains(got, "method=register_incident") {
+		t.Errorf("logged %q; want failed call at error level", got)
+	}
+}
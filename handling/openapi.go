@@ -0,0 +1,13 @@
+package handling
+
+import (
+	_ "embed"
+
+	"github.com/marcusolsson/goddd/openapi"
+)
+
+//go:embed docs/openapi.json
+var spec []byte
+
+// OpenAPI is the OpenAPI 3 specification of the handling API.
+var OpenAPI = openapi.MustParse(spec)
@@ -0,0 +1,200 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: handling.proto
+
+/*
+Package pb is a generated protocol buffer package.
+
+It is generated from these files:
+
+	handling.proto
+
+It has these top-level messages:
+
+	RegisterHandlingEventRequest
+	RegisterHandlingEventResponse
+*/
+package pb
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type RegisterHandlingEventRequest struct {
+	TrackingId string `protobuf:"bytes,1,opt,name=tracking_id,json=trackingId" json:"tracking_id,omitempty"`
+	Location   string `protobuf:"bytes,2,opt,name=location" json:"location,omitempty"`
+	Voyage     string `protobuf:"bytes,3,opt,name=voyage" json:"voyage,omitempty"`
+	// One of Receive, Load, Unload, Customs, Claim, GateIn, GateOut, Stuff,
+	// Strip, RailTransfer, InspectionHold or InspectionRelease.
+	EventType      string                     `protobuf:"bytes,4,opt,name=event_type,json=eventType" json:"event_type,omitempty"`
+	CompletionTime *google_protobuf.Timestamp `protobuf:"bytes,5,opt,name=completion_time,json=completionTime" json:"completion_time,omitempty"`
+}
+
+func (m *RegisterHandlingEventRequest) Reset()                    { *m = RegisterHandlingEventRequest{} }
+func (m *RegisterHandlingEventRequest) String() string            { return proto.CompactTextString(m) }
+func (*RegisterHandlingEventRequest) ProtoMessage()               {}
+func (*RegisterHandlingEventRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *RegisterHandlingEventRequest) GetTrackingId() string {
+	if m != nil {
+		return m.TrackingId
+	}
+	return ""
+}
+
+func (m *RegisterHandlingEventRequest) GetLocation() string {
+	if m != nil {
+		return m.Location
+	}
+	return ""
+}
+
+func (m *RegisterHandlingEventRequest) GetVoyage() string {
+	if m != nil {
+		return m.Voyage
+	}
+	return ""
+}
+
+func (m *RegisterHandlingEventRequest) GetEventType() string {
+	if m != nil {
+		return m.EventType
+	}
+	return ""
+}
+
+func (m *RegisterHandlingEventRequest) GetCompletionTime() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.CompletionTime
+	}
+	return nil
+}
+
+type RegisterHandlingEventResponse struct {
+}
+
+func (m *RegisterHandlingEventResponse) Reset()                    { *m = RegisterHandlingEventResponse{} }
+func (m *RegisterHandlingEventResponse) String() string            { return proto.CompactTextString(m) }
+func (*RegisterHandlingEventResponse) ProtoMessage()               {}
+func (*RegisterHandlingEventResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func init() {
+	proto.RegisterType((*RegisterHandlingEventRequest)(nil), "goddd.handling.v1.RegisterHandlingEventRequest")
+	proto.RegisterType((*RegisterHandlingEventResponse)(nil), "goddd.handling.v1.RegisterHandlingEventResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for HandlingService service
+
+type HandlingServiceClient interface {
+	// RegisterHandlingEvent registers a handling event in the system, and
+	// notifies interested parties that a cargo has been handled.
+	RegisterHandlingEvent(ctx context.Context, in *RegisterHandlingEventRequest, opts ...grpc.CallOption) (*RegisterHandlingEventResponse, error)
+}
+
+type handlingServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewHandlingServiceClient(cc *grpc.ClientConn) HandlingServiceClient {
+	return &handlingServiceClient{cc}
+}
+
+func (c *handlingServiceClient) RegisterHandlingEvent(ctx context.Context, in *RegisterHandlingEventRequest, opts ...grpc.CallOption) (*RegisterHandlingEventResponse, error) {
+	out := new(RegisterHandlingEventResponse)
+	err := grpc.Invoke(ctx, "/goddd.handling.v1.HandlingService/RegisterHandlingEvent", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for HandlingService service
+
+type HandlingServiceServer interface {
+	// RegisterHandlingEvent registers a handling event in the system, and
+	// notifies interested parties that a cargo has been handled.
+	RegisterHandlingEvent(context.Context, *RegisterHandlingEventRequest) (*RegisterHandlingEventResponse, error)
+}
+
+func RegisterHandlingServiceServer(s *grpc.Server, srv HandlingServiceServer) {
+	s.RegisterService(&_HandlingService_serviceDesc, srv)
+}
+
+func _HandlingService_RegisterHandlingEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterHandlingEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HandlingServiceServer).RegisterHandlingEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goddd.handling.v1.HandlingService/RegisterHandlingEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HandlingServiceServer).RegisterHandlingEvent(ctx, req.(*RegisterHandlingEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _HandlingService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "goddd.handling.v1.HandlingService",
+	HandlerType: (*HandlingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterHandlingEvent",
+			Handler:    _HandlingService_RegisterHandlingEvent_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "handling.proto",
+}
+
+func init() { proto.RegisterFile("handling.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 277 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0xdf, 0x4a, 0xc3, 0x30,
+	0x14, 0xc6, 0xe9, 0x9c, 0xc3, 0x9d, 0xc1, 0x86, 0x01, 0xa5, 0x14, 0x47, 0xc7, 0xae, 0x76, 0x95,
+	0xea, 0x7c, 0x03, 0x45, 0xd0, 0xdb, 0xba, 0x2b, 0x6f, 0x4a, 0xdb, 0x1c, 0x63, 0xb0, 0x4d, 0x62,
+	0x93, 0x15, 0xea, 0x3b, 0xf8, 0x7c, 0xbe, 0x8e, 0x34, 0x5d, 0xe7, 0x85, 0x7f, 0xd8, 0xe5, 0xf9,
+	0xbe, 0xfe, 0x9a, 0xf3, 0x3b, 0x30, 0x7d, 0x49, 0x25, 0x2b, 0x84, 0xe4, 0x54, 0x57, 0xca, 0x2a,
+	0x72, 0xca, 0x15, 0x63, 0x8c, 0xee, 0xd3, 0xfa, 0x2a, 0x08, 0xb9, 0x52, 0xbc, 0xc0, 0xc8, 0x7d,
+	0x90, 0x6d, 0x9f, 0x23, 0x2b, 0x4a, 0x34, 0x36, 0x2d, 0x75, 0xc7, 0x2c, 0x3f, 0x3d, 0xb8, 0x88,
+	0x91, 0x0b, 0x63, 0xb1, 0xba, 0xdf, 0x81, 0x77, 0x35, 0x4a, 0x1b, 0xe3, 0xdb, 0x16, 0x8d, 0x25,
+	0x21, 0x4c, 0x6c, 0x95, 0xe6, 0xaf, 0x42, 0xf2, 0x44, 0x30, 0xdf, 0x5b, 0x78, 0xab, 0x71, 0x0c,
+	0x7d, 0xf4, 0xc0, 0x48, 0x00, 0x27, 0x85, 0xca, 0x53, 0x2b, 0x94, 0xf4, 0x07, 0xae, 0xdd, 0xcf,
+	0xe4, 0x1c, 0x46, 0xb5, 0x6a, 0x52, 0x8e, 0xfe, 0x91, 0x6b, 0x76, 0x13, 0x99, 0x03, 0x60, 0xfb,
+	0x48, 0x62, 0x1b, 0x8d, 0xfe, 0xd0, 0x75, 0x63, 0x97, 0x6c, 0x1a, 0x8d, 0xe4, 0x16, 0x66, 0xb9,
+	0x2a, 0x75, 0x81, 0xed, 0x4f, 0x92, 0x76, 0x65, 0xff, 0x78, 0xe1, 0xad, 0x26, 0xeb, 0x80, 0x76,
+	0x3e, 0xb4, 0xf7, 0xa1, 0x9b, 0xde, 0x27, 0x9e, 0x7e, 0x23, 0x6d, 0xb8, 0x0c, 0x61, 0xfe, 0x87,
+	0x98, 0xd1, 0x4a, 0x1a, 0x5c, 0x7f, 0x78, 0x30, 0xeb, 0x9b, 0x47, 0xac, 0x6a, 0x91, 0x23, 0x79,
+	0x87, 0xb3, 0x5f, 0x21, 0x12, 0xd1, 0x1f, 0xc7, 0xa5, 0xff, 0xdd, 0x2d, 0xb8, 0x3c, 0x1c, 0xe8,
+	0xf6, 0xb9, 0x19, 0x3e, 0x0d, 0x74, 0x96, 0x8d, 0x9c, 0xda, 0xf5, 0xd7, 0x00, 0x52, 0x81, 0xcb,
+	0x30, 0xdd, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,143 @@
+package handling
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+// ErrDuplicateEvent is returned when an event has already been registered.
+var ErrDuplicateEvent = errors.New("duplicate handling event")
+
+// SequenceError is returned when an event is not consistent with the
+// handling history of the cargo.
+type SequenceError struct {
+	Event  cargo.HandlingActivity
+	Reason string
+}
+
+func (e *SequenceError) Error() string {
+	return fmt.Sprintf("cannot register %s in %s: %s", e.Event.Type, e.Event.Location, e.Reason)
+}
+
+// Rules decide whether a handling event may be registered, given the
+// handling history of the cargo.
+type Rules struct {
+	// Transitions lists the event types that may follow each event type.
+	// The entry for cargo.NotHandled lists the types of the first event.
+	// If nil, any sequence of events is accepted.
+	Transitions map[cargo.HandlingEventType][]cargo.HandlingEventType
+
+	// DuplicateWindow is the time within which events completed for the
+	// same activity are considered duplicates.
+	DuplicateWindow time.Duration
+}
+
+// DefaultTransitions describes a cargo being received, carried on any number
+// of voyages, possibly passing customs in between, and finally claimed. In
+// port, it may pass through the terminal gates, be stuffed into and stripped
+// from containers, transferred by rail, and held for inspection until
+// released.
+var DefaultTransitions = map[cargo.HandlingEventType][]cargo.HandlingEventType{
+	cargo.NotHandled:        {cargo.Receive, cargo.GateIn, cargo.Stuff, cargo.Load, cargo.Customs},
+	cargo.Receive:           {cargo.GateIn, cargo.Stuff, cargo.RailTransfer, cargo.Load, cargo.Customs, cargo.InspectionHold},
+	cargo.GateIn:            {cargo.Receive, cargo.Stuff, cargo.RailTransfer, cargo.Load, cargo.Customs, cargo.InspectionHold},
+	cargo.Stuff:             {cargo.GateIn, cargo.RailTransfer, cargo.Load, cargo.Customs, cargo.InspectionHold},
+	cargo.Load:              {cargo.Unload},
+	cargo.Unload:            {cargo.Load, cargo.Strip, cargo.RailTransfer, cargo.Customs, cargo.InspectionHold, cargo.GateOut, cargo.Claim},
+	cargo.Strip:             {cargo.Stuff, cargo.Customs, cargo.InspectionHold, cargo.GateOut, cargo.Claim},
+	cargo.RailTransfer:      {cargo.GateIn, cargo.Stuff, cargo.Strip, cargo.Load, cargo.Customs, cargo.InspectionHold, cargo.GateOut, cargo.Claim},
+	cargo.Customs:           {cargo.Load, cargo.Stuff, cargo.Strip, cargo.RailTransfer, cargo.InspectionHold, cargo.GateOut, cargo.Claim},
+	cargo.InspectionHold:    {cargo.InspectionRelease},
+	cargo.InspectionRelease: {cargo.Load, cargo.Stuff, cargo.Strip, cargo.RailTransfer, cargo.Customs, cargo.GateOut, cargo.Claim},
+	cargo.GateOut:           {cargo.Claim},
+	cargo.Claim:             {cargo.GateOut},
+}
+
+// DefaultRules returns the rules using DefaultTransitions.
+func DefaultRules() Rules {
+	return Rules{
+		Transitions:     DefaultTransitions,
+		DuplicateWindow: 5 * time.Minute,
+	}
+}
+
+// Check returns ErrDuplicateEvent if the history holds the same event, and
+// a *SequenceError if the event cannot take place where it falls in the
+// history by completion time. Events completed earlier than the last event
+// are thus accepted as long as they fit in with the events around them.
+func (r Rules) Check(h cargo.HandlingHistory, e cargo.HandlingEvent) error {
+	events := h.HandlingEvents
+
+	for _, prev := range events {
+		if prev.Activity == e.Activity && abs(prev.CompletionTime.Sub(e.CompletionTime)) <= r.DuplicateWindow {
+			return ErrDuplicateEvent
+		}
+	}
+
+	if r.Transitions == nil {
+		return nil
+	}
+
+	// Find where the event falls among those completed before and after it.
+	i := 0
+	for i < len(events) && !events[i].CompletionTime.After(e.CompletionTime) {
+		i++
+	}
+
+	var prev cargo.HandlingEvent
+	if i > 0 {
+		prev = events[i-1]
+	}
+
+	if reason := r.reason(prev.Activity, e.Activity); reason != "" {
+		return &SequenceError{Event: e.Activity, Reason: reason}
+	}
+	if i < len(events) {
+		next := events[i].Activity
+		if reason := r.reason(e.Activity, next); reason != "" {
+			return &SequenceError{
+				Event:  e.Activity,
+				Reason: fmt.Sprintf("conflicts with later %s in %s", next.Type, next.Location),
+			}
+		}
+	}
+
+	return nil
+}
+
+// reason returns why next may not directly follow prev, or the empty string
+// if it may.
+func (r Rules) reason(prev, next cargo.HandlingActivity) string {
+	allowed := false
+	for _, t := range r.Transitions[prev.Type] {
+		if t == next.Type {
+			allowed = true
+			break
+		}
+	}
+
+	switch {
+	case !allowed && prev.Type == cargo.NotHandled:
+		return "cargo has not been handled"
+	case !allowed:
+		return fmt.Sprintf("cannot follow %s in %s", prev.Type, prev.Location)
+	case prev.Type == cargo.NotHandled:
+		return ""
+	case next.Type == cargo.Unload && next.VoyageNumber != prev.VoyageNumber:
+		return fmt.Sprintf("cargo is onboard voyage %s", prev.VoyageNumber)
+	case next.Type != cargo.Unload && prev.Type != cargo.Load && next.Location != prev.Location:
+		return fmt.Sprintf("cargo is in %s", prev.Location)
+	}
+
+	return ""
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package handling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+func event(day int, typ cargo.HandlingEventType, loc location.UNLocode, v voyage.Number) cargo.HandlingEvent {
+	return cargo.HandlingEvent{
+		TrackingID: "ABC123",
+		Activity: cargo.HandlingActivity{
+			Type:         typ,
+			Location:     loc,
+			VoyageNumber: v,
+		},
+		CompletionTime: time.Date(2009, time.March, day, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRulesCheck(t *testing.T) {
+	history := []cargo.HandlingEvent{
+		event(1, cargo.Receive, location.CNHKG, ""),
+		event(3, cargo.Load, location.CNHKG, "V100"),
+		event(5, cargo.Unload, location.JNTKO, "V100"),
+	}
+
+	tests := []struct {
+		name    string
+		history []cargo.HandlingEvent
+		event   cargo.HandlingEvent
+		err     string
+	}{
+		{name: "first receive", event: event(1, cargo.Receive, location.CNHKG, "")},
+		{name: "first unload", event: event(1, cargo.Unload, location.CNHKG, "V100"), err: "cannot register Unload in CNHKG: cargo has not been handled"},
+		{name: "next load", history: history, event: event(6, cargo.Load, location.JNTKO, "V300")},
+		{name: "claim", history: history, event: event(6, cargo.Claim, location.JNTKO, "")},
+		{name: "load elsewhere", history: history, event: event(6, cargo.Load, location.DEHAM, "V300"), err: "cannot register Load in DEHAM: cargo is in JNTKO"},
+		{name: "load twice", history: history[:2], event: event(4, cargo.Load, location.CNHKG, "V100"), err: "cannot register Load in CNHKG: cannot follow Load in CNHKG"},
+		{name: "unload other voyage", history: history[:2], event: event(4, cargo.Unload, location.JNTKO, "V300"), err: "cannot register Unload in JNTKO: cargo is onboard voyage V100"},
+		{name: "after claim", history: append(history[:3:3], event(6, cargo.Claim, location.JNTKO, "")), event: event(7, cargo.Load, location.JNTKO, "V300"), err: "cannot register Load in JNTKO: cannot follow Claim in JNTKO"},
+		{name: "strip", history: history, event: event(6, cargo.Strip, location.JNTKO, "")},
+		{name: "load on hold", history: append(history[:3:3], event(6, cargo.InspectionHold, location.JNTKO, "")), event: event(7, cargo.Load, location.JNTKO, "V300"), err: "cannot register Load in JNTKO: cannot follow InspectionHold in JNTKO"},
+		{name: "gate out after release", history: append(history[:3:3], event(6, cargo.InspectionHold, location.JNTKO, ""), event(7, cargo.InspectionRelease, location.JNTKO, "")), event: event(8, cargo.GateOut, location.JNTKO, "")},
+		{name: "late customs", history: history, event: event(2, cargo.Customs, location.CNHKG, "")},
+		{name: "late unload", history: history, event: event(2, cargo.Unload, location.CNHKG, "V100"), err: "cannot register Unload in CNHKG: cannot follow Receive in CNHKG"},
+		{name: "late event breaking next", history: history[:2], event: event(2, cargo.Load, location.CNHKG, "V100"), err: "cannot register Load in CNHKG: conflicts with later Load in CNHKG"},
+	}
+
+	rules := DefaultRules()
+
+	for _, tt := range tests {
+		var got string
+		if err := rules.Check(cargo.HandlingHistory{HandlingEvents: tt.history}, tt.event); err != nil {
+			got = err.Error()
+		}
+		if got != tt.err {
+			t.Errorf("%s: err = %q; want = %q", tt.name, got, tt.err)
+		}
+	}
+}
+
+func TestRulesCheckDuplicates(t *testing.T) {
+	h := cargo.HandlingHistory{HandlingEvents: []cargo.HandlingEvent{
+		event(1, cargo.Receive, location.CNHKG, ""),
+	}}
+
+	rules := Rules{DuplicateWindow: time.Hour}
+
+	dup := event(1, cargo.Receive, location.CNHKG, "")
+	dup.CompletionTime = dup.CompletionTime.Add(30 * time.Minute)
+
+	if err := rules.Check(h, dup); err != ErrDuplicateEvent {
+		t.Errorf("err = %v; want = %v", err, ErrDuplicateEvent)
+	}
+
+	// Without transitions, any sequence is accepted.
+	if err := rules.Check(h, event(2, cargo.Unload, location.SESTO, "V100")); err != nil {
+		t.Errorf("err = %v; want = nil", err)
+	}
+}
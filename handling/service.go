@@ -3,7 +3,9 @@
 package handling
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
@@ -17,14 +19,16 @@ var ErrInvalidArgument = errors.New("invalid argument")
 
 // EventHandler provides a means of subscribing to registered handling events.
 type EventHandler interface {
-	CargoWasHandled(cargo.HandlingEvent)
+	CargoWasHandled(ctx context.Context, e cargo.HandlingEvent)
 }
 
 // Service provides handling operations.
 type Service interface {
 	// RegisterHandlingEvent registers a handling event in the system, and
-	// notifies interested parties that a cargo has been handled.
-	RegisterHandlingEvent(completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
+	// notifies interested parties that a cargo has been handled. Events
+	// already registered are ignored. Events for the same cargo are
+	// registered one at a time.
+	RegisterHandlingEvent(ctx context.Context, completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
 		unLocode location.UNLocode, eventType cargo.HandlingEventType) error
 }
 
@@ -32,31 +36,103 @@ type service struct {
 	handlingEventRepository cargo.HandlingEventRepository
 	handlingEventFactory    cargo.HandlingEventFactory
 	handlingEventHandler    EventHandler
+	rules                   Rules
+	locks                   cargoLocks
 }
 
-func (s *service) RegisterHandlingEvent(completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
+func (s *service) RegisterHandlingEvent(ctx context.Context, completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
 	loc location.UNLocode, eventType cargo.HandlingEventType) error {
 	if completed.IsZero() || id == "" || loc == "" || eventType == cargo.NotHandled {
 		return ErrInvalidArgument
 	}
 
-	e, err := s.handlingEventFactory.CreateHandlingEvent(time.Now(), completed, id, voyageNumber, loc, eventType)
+	e, err := s.handlingEventFactory.CreateHandlingEvent(ctx, time.Now(), completed, id, voyageNumber, loc, eventType)
 	if err != nil {
 		return err
 	}
 
-	s.handlingEventRepository.Store(e)
-	s.handlingEventHandler.CargoWasHandled(e)
+	stored, err := s.store(ctx, e)
+	if err != nil || !stored {
+		return err
+	}
+	s.handlingEventHandler.CargoWasHandled(ctx, e)
 
 	return nil
 }
 
+// store stores the event unless it is a duplicate. Events for the same cargo
+// are checked and stored one at a time, so that concurrent registrations
+// are checked against each other.
+func (s *service) store(ctx context.Context, e cargo.HandlingEvent) (bool, error) {
+	unlock := s.locks.lock(e.TrackingID)
+	defer unlock()
+
+	h, err := s.handlingEventRepository.QueryHandlingHistory(ctx, e.TrackingID)
+	if err != nil {
+		return false, err
+	}
+
+	switch err := s.rules.Check(h, e); err {
+	case nil:
+	case ErrDuplicateEvent:
+		return false, nil
+	default:
+		return false, err
+	}
+
+	if err := s.handlingEventRepository.Store(ctx, e); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// cargoLocks holds a lock for each cargo being handled.
+type cargoLocks struct {
+	mtx   sync.Mutex
+	locks map[cargo.TrackingID]*cargoLock
+}
+
+type cargoLock struct {
+	sync.Mutex
+	waiting int
+}
+
+// lock locks the cargo, and returns a function unlocking it again.
+func (l *cargoLocks) lock(id cargo.TrackingID) func() {
+	l.mtx.Lock()
+	if l.locks == nil {
+		l.locks = make(map[cargo.TrackingID]*cargoLock)
+	}
+	cl, ok := l.locks[id]
+	if !ok {
+		cl = &cargoLock{}
+		l.locks[id] = cl
+	}
+	cl.waiting++
+	l.mtx.Unlock()
+
+	cl.Lock()
+
+	return func() {
+		cl.Unlock()
+
+		l.mtx.Lock()
+		cl.waiting--
+		if cl.waiting == 0 {
+			delete(l.locks, id)
+		}
+		l.mtx.Unlock()
+	}
+}
+
 // NewService creates a handling event service with necessary dependencies.
-func NewService(r cargo.HandlingEventRepository, f cargo.HandlingEventFactory, h EventHandler) Service {
+// Events are checked against the handling history of the cargo using rules.
+func NewService(r cargo.HandlingEventRepository, f cargo.HandlingEventFactory, h EventHandler, rules Rules) Service {
 	return &service{
 		handlingEventRepository: r,
 		handlingEventFactory:    f,
 		handlingEventHandler:    h,
+		rules:                   rules,
 	}
 }
 
@@ -64,8 +140,8 @@ type handlingEventHandler struct {
 	InspectionService inspection.Service
 }
 
-func (h *handlingEventHandler) CargoWasHandled(event cargo.HandlingEvent) {
-	h.InspectionService.InspectCargo(event.TrackingID)
+func (h *handlingEventHandler) CargoWasHandled(ctx context.Context, event cargo.HandlingEvent) {
+	h.InspectionService.InspectCargo(ctx, event.TrackingID)
 }
 
 // NewEventHandler returns a new instance of a EventHandler.
@@ -74,3 +150,17 @@ func NewEventHandler(s inspection.Service) EventHandler {
 		InspectionService: s,
 	}
 }
+
+type multiEventHandler []EventHandler
+
+func (hs multiEventHandler) CargoWasHandled(ctx context.Context, event cargo.HandlingEvent) {
+	for _, h := range hs {
+		h.CargoWasHandled(ctx, event)
+	}
+}
+
+// NewMultiEventHandler returns an EventHandler notifying each of the handlers
+// in turn.
+func NewMultiEventHandler(hs ...EventHandler) EventHandler {
+	return multiEventHandler(hs)
+}
@@ -1,10 +1,13 @@
 package handling
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/mock"
 	"github.com/marcusolsson/goddd/voyage"
@@ -14,16 +17,16 @@ type stubEventHandler struct {
 	events []interface{}
 }
 
-func (h *stubEventHandler) CargoWasHandled(e cargo.HandlingEvent) {
+func (h *stubEventHandler) CargoWasHandled(_ context.Context, e cargo.HandlingEvent) {
 	h.events = append(h.events, e)
 }
 
 func TestRegisterHandlingEvent(t *testing.T) {
 	var cargos mock.CargoRepository
-	cargos.StoreFn = func(c *cargo.Cargo) error {
+	cargos.StoreFn = func(_ context.Context, c *cargo.Cargo) error {
 		return nil
 	}
-	cargos.FindFn = func(id cargo.TrackingID) (*cargo.Cargo, error) {
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 		if id == "no_such_id" {
 			return nil, cargo.ErrUnknown
 		}
@@ -31,17 +34,20 @@ func TestRegisterHandlingEvent(t *testing.T) {
 	}
 
 	var voyages mock.VoyageRepository
-	voyages.FindFn = func(n voyage.Number) (*voyage.Voyage, error) {
+	voyages.FindFn = func(_ context.Context, n voyage.Number) (*voyage.Voyage, error) {
 		return new(voyage.Voyage), nil
 	}
 
 	var locations mock.LocationRepository
-	locations.FindFn = func(l location.UNLocode) (*location.Location, error) {
+	locations.FindFn = func(_ context.Context, l location.UNLocode) (*location.Location, error) {
 		return nil, nil
 	}
 
 	var events mock.HandlingEventRepository
-	events.StoreFn = func(e cargo.HandlingEvent) {}
+	events.StoreFn = func(context.Context, cargo.HandlingEvent) error { return nil }
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
+	}
 
 	eh := &stubEventHandler{events: make([]interface{}, 0)}
 	ef := cargo.HandlingEventFactory{
@@ -50,7 +56,7 @@ func TestRegisterHandlingEvent(t *testing.T) {
 		LocationRepository: &locations,
 	}
 
-	s := NewService(&events, ef, eh)
+	s := NewService(&events, ef, eh, DefaultRules())
 
 	var (
 		completed = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
@@ -58,19 +64,22 @@ func TestRegisterHandlingEvent(t *testing.T) {
 		voyage    = voyage.Number("V100")
 	)
 
-	var err error
+	var (
+		ctx = context.Background()
+		err error
+	)
 
-	err = cargos.Store(cargo.New(id, cargo.RouteSpecification{}))
+	err = cargos.Store(ctx, cargo.New(id, cargo.RouteSpecification{}))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = s.RegisterHandlingEvent(completed, id, voyage, location.SESTO, cargo.Load)
+	err = s.RegisterHandlingEvent(ctx, completed, id, voyage, location.SESTO, cargo.Load)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = s.RegisterHandlingEvent(completed, "no_such_id", voyage, location.SESTO, cargo.Load)
+	err = s.RegisterHandlingEvent(ctx, completed, "no_such_id", voyage, location.SESTO, cargo.Load)
 	if err != cargo.ErrUnknown {
 		t.Errorf("err = %s; want = %s", err, cargo.ErrUnknown)
 	}
@@ -79,3 +88,125 @@ func TestRegisterHandlingEvent(t *testing.T) {
 		t.Errorf("len(eh.events) = %d; want = %d", len(eh.events), 1)
 	}
 }
+
+func TestRegisterHandlingEventIgnoresDuplicates(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+		return new(cargo.Cargo), nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(_ context.Context, l location.UNLocode) (*location.Location, error) {
+		return nil, nil
+	}
+
+	completed := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(context.Context, cargo.HandlingEvent) error { return nil }
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{HandlingEvents: []cargo.HandlingEvent{{
+			TrackingID:     "ABC123",
+			Activity:       cargo.HandlingActivity{Type: cargo.Receive, Location: location.SESTO},
+			CompletionTime: completed,
+		}}}, nil
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(_ context.Context, n voyage.Number) (*voyage.Voyage, error) {
+		return new(voyage.Voyage), nil
+	}
+
+	eh := &stubEventHandler{events: make([]interface{}, 0)}
+	ef := cargo.HandlingEventFactory{
+		CargoRepository:    &cargos,
+		VoyageRepository:   &voyages,
+		LocationRepository: &locations,
+	}
+
+	s := NewService(&events, ef, eh, DefaultRules())
+
+	if err := s.RegisterHandlingEvent(context.Background(), completed, "ABC123", "", location.SESTO, cargo.Receive); err != nil {
+		t.Fatal(err)
+	}
+	if events.StoreInvoked {
+		t.Errorf("duplicate event was stored")
+	}
+	if len(eh.events) != 0 {
+		t.Errorf("len(eh.events) = %d; want = %d", len(eh.events), 0)
+	}
+
+	err := s.RegisterHandlingEvent(context.Background(), completed.Add(time.Hour), "ABC123", "V100", location.SESTO, cargo.Unload)
+	if _, ok := err.(*SequenceError); !ok {
+		t.Errorf("err = %v; want *SequenceError", err)
+	}
+}
+
+// slowHistoryRepository delays returning the handling history, so that
+// concurrent registrations overlap.
+type slowHistoryRepository struct {
+	cargo.HandlingEventRepository
+}
+
+func (r slowHistoryRepository) QueryHandlingHistory(ctx context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
+	h, err := r.HandlingEventRepository.QueryHandlingHistory(ctx, id)
+	time.Sleep(10 * time.Millisecond)
+	return h, err
+}
+
+type countingEventHandler struct {
+	mtx sync.Mutex
+	n   int
+}
+
+func (h *countingEventHandler) CargoWasHandled(context.Context, cargo.HandlingEvent) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.n++
+}
+
+func TestRegisterHandlingEventConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	cargos := inmem.NewCargoRepository()
+	if err := cargos.Store(ctx, cargo.New("ABC123", cargo.RouteSpecification{})); err != nil {
+		t.Fatal(err)
+	}
+
+	events := inmem.NewHandlingEventRepository()
+
+	ef := cargo.HandlingEventFactory{
+		CargoRepository:    cargos,
+		VoyageRepository:   inmem.NewVoyageRepository(),
+		LocationRepository: inmem.NewLocationRepository(),
+	}
+
+	var eh countingEventHandler
+
+	s := NewService(slowHistoryRepository{events}, ef, &eh, DefaultRules())
+
+	completed := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.RegisterHandlingEvent(ctx, completed, "ABC123", "", location.SESTO, cargo.Receive); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	h, err := events.QueryHandlingHistory(ctx, "ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(h.HandlingEvents); n != 1 {
+		t.Errorf("len(h.HandlingEvents) = %d; want = %d", n, 1)
+	}
+	if eh.n != 1 {
+		t.Errorf("eh.n = %d; want = %d", eh.n, 1)
+	}
+}
@@ -0,0 +1,37 @@
+package handling
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/tracing"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type tracingService struct {
+	tracer *tracing.Tracer
+	Service
+}
+
+// NewTracingService returns an instance of a tracing Service.
+func NewTracingService(tracer *tracing.Tracer, s Service) Service {
+	return &tracingService{tracer, s}
+}
+
+func (s *tracingService) RegisterHandlingEvent(ctx context.Context, completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
+	loc location.UNLocode, eventType cargo.HandlingEventType) (err error) {
+	ctx, span := s.tracer.Start(ctx, "handling.register_incident", tracing.KindInternal)
+	span.SetAttributes(
+		"tracking_id", id,
+		"location", loc,
+		"voyage", voyageNumber,
+		"event_type", eventType,
+	)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.RegisterHandlingEvent(ctx, completed, id, voyageNumber, loc, eventType)
+}
@@ -3,8 +3,8 @@ package handling
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gorilla/mux"
 
@@ -12,15 +12,19 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/httpctx"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/requestid"
+	"github.com/marcusolsson/goddd/validation"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
-// MakeHandler returns a handler for the handling service.
-func MakeHandler(ctx context.Context, hs Service, logger kitlog.Logger) http.Handler {
+// MakeHandler returns a router for the handling service.
+func MakeHandler(ctx context.Context, hs Service, logger kitlog.Logger) *mux.Router {
 	r := mux.NewRouter()
 
 	opts := []kithttp.ServerOption{
+		kithttp.ServerBefore(httpctx.FromRequest, requestid.HTTPToContext),
 		kithttp.ServerErrorLogger(logger),
 		kithttp.ServerErrorEncoder(encodeError),
 	}
@@ -34,44 +38,38 @@ func MakeHandler(ctx context.Context, hs Service, logger kitlog.Logger) http.Han
 	)
 
 	r.Handle("/handling/v1/incidents", registerIncidentHandler).Methods("POST")
-	r.Handle("/handling/v1/docs", http.StripPrefix("/handling/v1/docs", http.FileServer(http.Dir("handling/docs"))))
+	r.Handle("/handling/v1/openapi.json", OpenAPI).Methods("GET")
 
 	return r
 }
 
 func decodeRegisterIncidentRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var body struct {
-		CompletionTime time.Time `json:"completion_time"`
-		TrackingID     string    `json:"tracking_id"`
-		VoyageNumber   string    `json:"voyage"`
-		Location       string    `json:"location"`
-		EventType      string    `json:"event_type"`
+		CompletionTime string `json:"completion_time"`
+		TrackingID     string `json:"tracking_id"`
+		VoyageNumber   string `json:"voyage"`
+		Location       string `json:"location"`
+		EventType      string `json:"event_type"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+	if err := validation.DecodeJSON(r.Body, &body); err != nil {
+		return nil, err
+	}
+
+	completed, err := validation.ParseTime("completion_time", body.CompletionTime)
+	if err != nil {
 		return nil, err
 	}
 
 	return registerIncidentRequest{
-		CompletionTime: body.CompletionTime,
+		CompletionTime: completed,
 		ID:             cargo.TrackingID(body.TrackingID),
 		Voyage:         voyage.Number(body.VoyageNumber),
 		Location:       location.UNLocode(body.Location),
-		EventType:      stringToEventType(body.EventType),
+		EventType:      cargo.ParseHandlingEventType(body.EventType),
 	}, nil
 }
 
-func stringToEventType(s string) cargo.HandlingEventType {
-	types := map[string]cargo.HandlingEventType{
-		cargo.Receive.String(): cargo.Receive,
-		cargo.Load.String():    cargo.Load,
-		cargo.Unload.String():  cargo.Unload,
-		cargo.Customs.String(): cargo.Customs,
-		cargo.Claim.String():   cargo.Claim,
-	}
-	return types[s]
-}
-
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	if e, ok := response.(errorer); ok && e.error() != nil {
 		encodeError(ctx, e.error(), w)
@@ -87,16 +85,28 @@ type errorer interface {
 
 // encode errors from business-logic
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	switch err {
-	case cargo.ErrUnknown:
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	body := map[string]interface{}{
+		"error": err.Error(),
+	}
+
+	var (
+		verr *validation.Error
+		serr *SequenceError
+	)
+
+	switch {
+	case errors.As(err, &verr):
+		body["fields"] = verr.Fields
+		w.WriteHeader(verr.StatusCode())
+	case errors.As(err, &serr):
+		w.WriteHeader(http.StatusConflict)
+	case err == cargo.ErrUnknown:
 		w.WriteHeader(http.StatusNotFound)
-	case ErrInvalidArgument:
+	case err == ErrInvalidArgument:
 		w.WriteHeader(http.StatusBadRequest)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
+	json.NewEncoder(w).Encode(body)
 }
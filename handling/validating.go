@@ -0,0 +1,79 @@
+package handling
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type validatingService struct {
+	locations location.Repository
+	voyages   voyage.Repository
+	Service
+}
+
+// NewValidatingService returns a new instance of a validating Service, which
+// rejects incidents with unknown event types, locations or voyages with a
+// *validation.Error.
+func NewValidatingService(locations location.Repository, voyages voyage.Repository, s Service) Service {
+	return &validatingService{
+		locations: locations,
+		voyages:   voyages,
+		Service:   s,
+	}
+}
+
+func (s *validatingService) RegisterHandlingEvent(ctx context.Context, completed time.Time, id cargo.TrackingID, voyageNumber voyage.Number,
+	loc location.UNLocode, eventType cargo.HandlingEventType) error {
+	var errs validation.Errors
+
+	if completed.IsZero() {
+		errs.Add("completion_time", "is required")
+	}
+	if id == "" {
+		errs.Add("tracking_id", "is required")
+	}
+
+	if loc == "" {
+		errs.Add("location", "is required")
+	} else if _, err := s.locations.Find(ctx, loc); err == location.ErrUnknown {
+		errs.Add("location", "unknown location %q", loc)
+	} else if err != nil {
+		return err
+	}
+
+	spec, ok := eventType.Spec()
+	if !ok {
+		errs.Add("event_type", "must be one of %s", eventTypeNames())
+	}
+
+	if voyageNumber == "" {
+		if spec.RequiresVoyage {
+			errs.Add("voyage", "is required for %s events", eventType)
+		}
+	} else if _, err := s.voyages.Find(ctx, voyageNumber); err == voyage.ErrUnknown {
+		errs.Add("voyage", "unknown voyage %q", voyageNumber)
+	} else if err != nil {
+		return err
+	}
+
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	return s.Service.RegisterHandlingEvent(ctx, completed, id, voyageNumber, loc, eventType)
+}
+
+// eventTypeNames lists the names of the registered event types.
+func eventTypeNames() string {
+	var names []string
+	for _, t := range cargo.HandlingEventTypes() {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,59 @@
+package handling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/validation"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+func TestValidatingService(t *testing.T) {
+	var locations mock.LocationRepository
+	locations.FindFn = func(_ context.Context, l location.UNLocode) (*location.Location, error) {
+		if l == location.SESTO {
+			return &location.Location{UNLocode: l}, nil
+		}
+		return nil, location.ErrUnknown
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(_ context.Context, n voyage.Number) (*voyage.Voyage, error) {
+		if n == "V100" {
+			return new(voyage.Voyage), nil
+		}
+		return nil, voyage.ErrUnknown
+	}
+
+	s := NewValidatingService(&locations, &voyages, nil)
+
+	completed := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		completed time.Time
+		id        cargo.TrackingID
+		voyage    voyage.Number
+		location  location.UNLocode
+		eventType cargo.HandlingEventType
+		err       string
+	}{
+		{
+			err: "invalid request: completion_time: is required; tracking_id: is required; location: is required; " +
+				"event_type: must be one of Receive, Load, Unload, Customs, Claim, GateIn, GateOut, Stuff, Strip, RailTransfer, InspectionHold, InspectionRelease",
+		},
+		{completed, "ABC123", "", location.SESTO, cargo.Load, "invalid request: voyage: is required for Load events"},
+		{completed, "ABC123", "V999", location.SESTO, cargo.Unload, `invalid request: voyage: unknown voyage "V999"`},
+		{completed, "ABC123", "V100", "XXXXX", cargo.Unload, `invalid request: location: unknown location "XXXXX"`},
+	}
+
+	for _, tt := range tests {
+		err := s.RegisterHandlingEvent(context.Background(), tt.completed, tt.id, tt.voyage, tt.location, tt.eventType)
+		if _, ok := err.(*validation.Error); !ok || err.Error() != tt.err {
+			t.Errorf("err = %v; want = %s", err, tt.err)
+		}
+	}
+}
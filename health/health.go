@@ -0,0 +1,238 @@
+// Package health provides liveness and readiness endpoints reporting the
+// state of the application and its dependencies.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Checker checks the health of a dependency. A non-nil error means that the
+// dependency is unavailable.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as
+// checkers.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Status values reported for the application and its dependencies.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+
+	// StatusDegraded is reported for failing dependencies that do not keep
+	// the application from receiving traffic.
+	StatusDegraded = "degraded"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+// Report is the outcome of all checks of an endpoint.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Health keeps track of the checks for the liveness and readiness endpoints.
+type Health struct {
+	timeout time.Duration
+	ready   int32
+
+	mtx       sync.RWMutex
+	liveness  map[string]Checker
+	readiness map[string]Checker
+	optional  map[string]bool
+}
+
+// New returns a new Health, initially ready, where each check must complete
+// within the given timeout.
+func New(timeout time.Duration) *Health {
+	return &Health{
+		timeout:   timeout,
+		ready:     1,
+		liveness:  make(map[string]Checker),
+		readiness: make(map[string]Checker),
+		optional:  make(map[string]bool),
+	}
+}
+
+// AddLivenessCheck adds a check that must pass for the application to be
+// considered alive. Liveness checks are also part of the readiness checks.
+func (h *Health) AddLivenessCheck(name string, c Checker) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.liveness[name] = c
+}
+
+// AddReadinessCheck adds a check that must pass for the application to
+// receive traffic.
+func (h *Health) AddReadinessCheck(name string, c Checker) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.readiness[name] = c
+	delete(h.optional, name)
+}
+
+// AddOptionalReadinessCheck adds a check that is reported by the readiness
+// endpoint, but leaves the application ready when it fails, as for
+// dependencies with a fallback.
+func (h *Health) AddOptionalReadinessCheck(name string, c Checker) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.readiness[name] = c
+	h.optional[name] = true
+}
+
+// SetReady sets whether the application is willing to receive traffic,
+// regardless of the state of its dependencies.
+func (h *Health) SetReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&h.ready, v)
+}
+
+// Live runs the liveness checks.
+func (h *Health) Live(ctx context.Context) Report {
+	h.mtx.RLock()
+	checks := copyChecks(h.liveness)
+	h.mtx.RUnlock()
+
+	return h.run(ctx, checks, nil)
+}
+
+// Ready runs the liveness and readiness checks.
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mtx.RLock()
+	checks := copyChecks(h.liveness)
+	for name, c := range h.readiness {
+		checks[name] = c
+	}
+	optional := make(map[string]bool, len(h.optional))
+	for name := range h.optional {
+		optional[name] = true
+	}
+	h.mtx.RUnlock()
+
+	r := h.run(ctx, checks, optional)
+
+	if atomic.LoadInt32(&h.ready) == 0 {
+		r.Status = StatusUnavailable
+		r.Checks["shutdown"] = Result{Status: StatusUnavailable, Error: "shutting down"}
+	}
+
+	return r
+}
+
+// run runs the checks, reporting the failures of the optional ones as
+// degraded rather than unavailable.
+func (h *Health) run(ctx context.Context, checks map[string]Checker, optional map[string]bool) Report {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var (
+		mtx sync.Mutex
+		wg  sync.WaitGroup
+	)
+
+	r := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(checks)),
+	}
+
+	for name, c := range checks {
+		wg.Add(1)
+		go func(name string, c Checker) {
+			defer wg.Done()
+
+			res := check(ctx, c)
+			if res.Status != StatusOK && optional[name] {
+				res.Status = StatusDegraded
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			r.Checks[name] = res
+			if res.Status == StatusUnavailable {
+				r.Status = StatusUnavailable
+			}
+		}(name, c)
+	}
+
+	wg.Wait()
+
+	return r
+}
+
+func check(ctx context.Context, c Checker) Result {
+	begin := time.Now()
+
+	errc := make(chan error, 1)
+	go func() { errc <- c.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := Result{
+		Status:   StatusOK,
+		Duration: time.Since(begin).Seconds(),
+	}
+	if err != nil {
+		res.Status = StatusUnavailable
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// LiveHandler returns a handler reporting the liveness checks.
+func (h *Health) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodeReport(w, h.Live(r.Context()))
+	})
+}
+
+// ReadyHandler returns a handler reporting the readiness checks.
+func (h *Health) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodeReport(w, h.Ready(r.Context()))
+	})
+}
+
+func encodeReport(w http.ResponseWriter, r Report) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Status != StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(r)
+}
+
+func copyChecks(m map[string]Checker) map[string]Checker {
+	c := make(map[string]Checker, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
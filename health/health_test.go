@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestReady(t *testing.T) {
+	h := New(time.Second)
+	h.AddLivenessCheck("self", CheckerFunc(ok))
+	h.AddReadinessCheck("mongo", CheckerFunc(ok))
+
+	rec := httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	var r Report
+	if err := json.NewDecoder(rec.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Status != StatusOK {
+		t.Errorf("r.Status = %q; want = %q", r.Status, StatusOK)
+	}
+	if len(r.Checks) != 2 {
+		t.Errorf("len(r.Checks) = %d; want = %d", len(r.Checks), 2)
+	}
+}
+
+func TestReadyFailingDependency(t *testing.T) {
+	h := New(time.Second)
+	h.AddReadinessCheck("mongo", CheckerFunc(func(context.Context) error {
+		return errors.New("no reachable servers")
+	}))
+	h.AddReadinessCheck("routing", CheckerFunc(ok))
+
+	rec := httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	var r Report
+	if err := json.NewDecoder(rec.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := r.Checks["mongo"]; got.Status != StatusUnavailable || got.Error != "no reachable servers" {
+		t.Errorf(`r.Checks["mongo"] = %+v`, got)
+	}
+	if got := r.Checks["routing"]; got.Status != StatusOK {
+		t.Errorf(`r.Checks["routing"].Status = %q; want = %q`, got.Status, StatusOK)
+	}
+
+	// Readiness checks do not affect liveness.
+	rec = httptest.NewRecorder()
+	h.LiveHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestReadyOptionalDependency(t *testing.T) {
+	h := New(time.Second)
+	h.AddReadinessCheck("mongo", CheckerFunc(ok))
+	h.AddOptionalReadinessCheck("routing", CheckerFunc(func(context.Context) error {
+		return errors.New("circuit breaker is open")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	var r Report
+	if err := json.NewDecoder(rec.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Status != StatusOK {
+		t.Errorf("r.Status = %q; want = %q", r.Status, StatusOK)
+	}
+	if got := r.Checks["routing"]; got.Status != StatusDegraded || got.Error != "circuit breaker is open" {
+		t.Errorf(`r.Checks["routing"] = %+v`, got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	h := New(10 * time.Millisecond)
+	h.AddLivenessCheck("slow", CheckerFunc(func(context.Context) error {
+		<-block
+		return nil
+	}))
+
+	r := h.Live(context.Background())
+
+	if r.Status != StatusUnavailable {
+		t.Errorf("r.Status = %q; want = %q", r.Status, StatusUnavailable)
+	}
+	if got, want := r.Checks["slow"].Error, context.DeadlineExceeded.Error(); got != want {
+		t.Errorf(`r.Checks["slow"].Error = %q; want = %q`, got, want)
+	}
+}
+
+func TestSetReady(t *testing.T) {
+	h := New(time.Second)
+	h.SetReady(false)
+
+	r := h.Ready(context.Background())
+
+	if r.Status != StatusUnavailable {
+		t.Errorf("r.Status = %q; want = %q", r.Status, StatusUnavailable)
+	}
+
+	h.SetReady(true)
+
+	if r := h.Ready(context.Background()); r.Status != StatusOK {
+		t.Errorf("r.Status = %q; want = %q", r.Status, StatusOK)
+	}
+}
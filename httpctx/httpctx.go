@@ -0,0 +1,63 @@
+// Package httpctx bridges the context of incoming HTTP requests and gRPC
+// calls and the context the go-kit servers are created with, and provides
+// helpers shared by the HTTP middlewares.
+package httpctx
+
+import (
+	"context"
+	"net/http"
+)
+
+// FromRequest returns a context that is canceled when the client goes away,
+// and that carries the values of both ctx and the request context. It is
+// intended to be used as the first kithttp.ServerBefore function, so that
+// cancellations and deadlines reach the services.
+func FromRequest(ctx context.Context, r *http.Request) context.Context {
+	return WithValues(r.Context(), ctx)
+}
+
+// WithValues returns a context that is canceled along with ctx, and that
+// carries the values of both ctx and values. Values carried by ctx take
+// precedence.
+func WithValues(ctx, values context.Context) context.Context {
+	return merged{Context: ctx, values: values}
+}
+
+// merged is canceled along with the embedded context, and falls back to
+// values for values not found in the embedded context.
+type merged struct {
+	context.Context
+	values context.Context
+}
+
+func (c merged) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.values.Value(key)
+}
+
+// StatusWriter records the status code written to a http.ResponseWriter.
+type StatusWriter struct {
+	http.ResponseWriter
+
+	// Code is the status code written, 200 OK unless another was written.
+	Code int
+}
+
+// NewStatusWriter returns a StatusWriter wrapping w.
+func NewStatusWriter(w http.ResponseWriter) *StatusWriter {
+	return &StatusWriter{ResponseWriter: w, Code: http.StatusOK}
+}
+
+// WriteHeader records the code and writes it to the wrapped writer.
+func (w *StatusWriter) WriteHeader(code int) {
+	w.Code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush streamed responses.
+func (w *StatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
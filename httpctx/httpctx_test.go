@@ -0,0 +1,59 @@
+package httpctx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type key string
+
+func TestFromRequest(t *testing.T) {
+	base := context.WithValue(context.Background(), key("base"), "a")
+
+	reqCtx, cancel := context.WithCancel(context.WithValue(context.Background(), key("request"), "b"))
+
+	r := httptest.NewRequest("GET", "/", nil).WithContext(reqCtx)
+
+	ctx := FromRequest(base, r)
+
+	if v := ctx.Value(key("base")); v != "a" {
+		t.Errorf("ctx.Value(%q) = %v; want = %v", "base", v, "a")
+	}
+	if v := ctx.Value(key("request")); v != "b" {
+		t.Errorf("ctx.Value(%q) = %v; want = %v", "request", v, "b")
+	}
+
+	cancel()
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("ctx should be canceled along with the request")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("ctx.Err() = %v; want = %v", ctx.Err(), context.Canceled)
+	}
+}
+
+func TestStatusWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	w := NewStatusWriter(rec)
+	if w.Code != http.StatusOK {
+		t.Errorf("w.Code = %d; want = %d", w.Code, http.StatusOK)
+	}
+
+	w.WriteHeader(http.StatusTeapot)
+	if w.Code != http.StatusTeapot {
+		t.Errorf("w.Code = %d; want = %d", w.Code, http.StatusTeapot)
+	}
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusTeapot)
+	}
+
+	if u := w.Unwrap(); u != rec {
+		t.Errorf("w.Unwrap() = %v; want = %v", u, rec)
+	}
+}
@@ -0,0 +1,222 @@
+// Package idempotency makes it safe for clients to retry requests. The
+// response to a request carrying an Idempotency-Key header is stored, and
+// returned again for any later request with the same key.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	// Header is the request header holding the idempotency key.
+	Header = "Idempotency-Key"
+
+	// ReplayedHeader is set on responses returned from the store.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// MaxKeyLength is the maximum length of an idempotency key.
+	MaxKeyLength = 255
+
+	// MaxBodySize is the largest request body read for the fingerprint.
+	MaxBodySize = 1 << 20
+)
+
+// Record holds the response to a request made with an idempotency key.
+type Record struct {
+	Key string
+
+	// Fingerprint identifies the method, target and body of the request.
+	Fingerprint string
+
+	// Completed is false while the request is being handled.
+	Completed bool
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	Expires time.Time
+}
+
+// Store provides access to a record store. Expired records are treated as if
+// they did not exist.
+type Store interface {
+	// Add stores r, unless a record with the same key exists, in which case
+	// the existing record is returned instead.
+	Add(ctx context.Context, r *Record) (*Record, error)
+
+	// Update replaces the record with the same key as r.
+	Update(ctx context.Context, r *Record) error
+
+	// Remove removes the record for key, if any.
+	Remove(ctx context.Context, key string) error
+}
+
+// Handler returns a handler that replays the stored response when a
+// mutating request reuses an idempotency key. Requests reusing a key with a
+// different method, target or body are rejected with 422 Unprocessable
+// Entity, and requests reusing the key of a request still in progress with
+// 409 Conflict. Bodies larger than MaxBodySize are rejected with 413 Request
+// Entity Too Large. Responses are stored for ttl, except server errors,
+// which are discarded so that the request can be retried.
+func Handler(s Store, ttl time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" || !mutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > MaxKeyLength {
+			encodeError(w, http.StatusBadRequest, "idempotency key must be at most 255 characters")
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				encodeError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+				return
+			}
+			encodeError(w, http.StatusBadRequest, "could not read request body")
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+
+		rec := &Record{
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+			Expires:     time.Now().Add(ttl),
+		}
+
+		existing, err := s.Add(ctx, rec)
+		if err != nil {
+			encodeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		switch {
+		case existing == nil:
+		case existing.Fingerprint != rec.Fingerprint:
+			encodeError(w, http.StatusUnprocessableEntity, "idempotency key was used for a different request")
+			return
+		case !existing.Completed:
+			encodeError(w, http.StatusConflict, "a request with the same idempotency key is in progress")
+			return
+		default:
+			replay(w, existing)
+			return
+		}
+
+		rw := newRecorder(w)
+		next.ServeHTTP(rw, r)
+
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+
+		// The request is done even if the client has gone away.
+		ctx = context.WithoutCancel(ctx)
+
+		if rw.status >= http.StatusInternalServerError {
+			s.Remove(ctx, key)
+			return
+		}
+
+		rec.Completed = true
+		rec.StatusCode = rw.status
+		rec.Header = rw.added()
+		rec.Body = rw.body.Bytes()
+
+		if err := s.Update(ctx, rec); err != nil {
+			// Don't leave the key blocked until it expires.
+			s.Remove(ctx, key)
+		}
+	})
+}
+
+func mutating(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, rec *Record) {
+	for k, vs := range rec.Header {
+		w.Header()[k] = vs
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(rec.StatusCode)
+	w.Write(rec.Body)
+}
+
+func encodeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": msg,
+	})
+}
+
+// recorder passes the response on to the client while keeping a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	before http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newRecorder(w http.ResponseWriter) *recorder {
+	before := make(http.Header)
+	for k, vs := range w.Header() {
+		before[k] = vs
+	}
+	return &recorder{ResponseWriter: w, before: before}
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// added returns the headers set by the wrapped handler, leaving out those
+// that are set for every request, such as the request ID.
+func (r *recorder) added() http.Header {
+	h := make(http.Header)
+	for k, vs := range r.Header() {
+		if _, ok := r.before[k]; !ok {
+			h[k] = vs
+		}
+	}
+	return h
+}
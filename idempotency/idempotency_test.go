@@ -0,0 +1,177 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapStore map[string]*Record
+
+func (s mapStore) Add(_ context.Context, r *Record) (*Record, error) {
+	if rec, ok := s[r.Key]; ok {
+		return rec, nil
+	}
+	s[r.Key] = r
+	return nil, nil
+}
+
+func (s mapStore) Update(_ context.Context, r *Record) error {
+	s[r.Key] = r
+	return nil
+}
+
+func (s mapStore) Remove(_ context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+// counter responds with the number of requests it has handled.
+type counter struct {
+	n      int
+	status int
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.n++
+	w.Header().Set("Content-Type", "application/json")
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+	}
+	fmt.Fprintf(w, `{"n":%d}`, c.n)
+}
+
+func do(h http.Handler, method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/booking/v1/cargos", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	rec := httptest.NewRecorder()
+	// Set for every request by the outer handlers.
+	rec.Header().Set("X-Request-ID", "abc")
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerReplaysResponse(t *testing.T) {
+	var (
+		next  counter
+		store = mapStore{}
+		h     = Handler(store, time.Hour, &next)
+	)
+
+	first := do(h, "POST", "key-1", `{"origin":"SESTO"}`)
+	second := do(h, "POST", "key-1", `{"origin":"SESTO"}`)
+
+	if next.n != 1 {
+		t.Errorf("next.n = %d; want = 1", next.n)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("second.Body = %s; want = %s", second.Body, first.Body)
+	}
+	if got := second.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q; want = %q", got, "application/json")
+	}
+	if got := second.Header().Get(ReplayedHeader); got != "true" {
+		t.Errorf("%s = %q; want = %q", ReplayedHeader, got, "true")
+	}
+	if first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("first response should not be marked as replayed")
+	}
+	if _, ok := store["key-1"].Header["X-Request-Id"]; ok {
+		t.Errorf("X-Request-ID should not be stored")
+	}
+
+	if rec := do(h, "POST", "key-2", `{"origin":"SESTO"}`); rec.Body.String() != `{"n":2}` {
+		t.Errorf("rec.Body = %s; want = %s", rec.Body, `{"n":2}`)
+	}
+}
+
+func TestHandlerRejectsDifferentRequest(t *testing.T) {
+	var next counter
+	h := Handler(mapStore{}, time.Hour, &next)
+
+	do(h, "POST", "key-1", `{"origin":"SESTO"}`)
+	rec := do(h, "POST", "key-1", `{"origin":"AUMEL"}`)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if next.n != 1 {
+		t.Errorf("next.n = %d; want = 1", next.n)
+	}
+}
+
+func TestHandlerRejectsLargeBody(t *testing.T) {
+	store := mapStore{}
+
+	var next counter
+	h := Handler(store, time.Hour, &next)
+
+	rec := do(h, "POST", "key-1", strings.Repeat("a", MaxBodySize+1))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if next.n != 0 {
+		t.Errorf("next.n = %d; want = 0", next.n)
+	}
+	if len(store) != 0 {
+		t.Errorf("len(store) = %d; want = 0", len(store))
+	}
+}
+
+func TestHandlerRejectsRequestInProgress(t *testing.T) {
+	store := mapStore{}
+	store["key-1"] = &Record{Key: "key-1", Fingerprint: "x"}
+
+	var next counter
+	h := Handler(store, time.Hour, &next)
+
+	req := httptest.NewRequest("POST", "/booking/v1/cargos", nil)
+	req.Header.Set(Header, "key-1")
+	store["key-1"].Fingerprint = fingerprint(req, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestHandlerDiscardsServerErrors(t *testing.T) {
+	var (
+		next  = counter{status: http.StatusInternalServerError}
+		store = mapStore{}
+		h     = Handler(store, time.Hour, &next)
+	)
+
+	do(h, "POST", "key-1", `{}`)
+	do(h, "POST", "key-1", `{}`)
+
+	if next.n != 2 {
+		t.Errorf("next.n = %d; want = 2", next.n)
+	}
+	if len(store) != 0 {
+		t.Errorf("len(store) = %d; want = 0", len(store))
+	}
+}
+
+func TestHandlerIgnoresSafeRequests(t *testing.T) {
+	var next counter
+	h := Handler(mapStore{}, time.Hour, &next)
+
+	do(h, "GET", "key-1", "")
+	do(h, "GET", "key-1", "")
+	do(h, "POST", "", `{}`)
+	do(h, "POST", "", `{}`)
+
+	if next.n != 4 {
+		t.Errorf("next.n = %d; want = 4", next.n)
+	}
+}
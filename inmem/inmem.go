@@ -2,10 +2,15 @@
 package inmem
 
 import (
+	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/idempotency"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/token"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
@@ -14,21 +19,21 @@ type cargoRepository struct {
 	cargos map[cargo.TrackingID]*cargo.Cargo
 }
 
-func (r *cargoRepository) Store(c *cargo.Cargo) error {
+func (r *cargoRepository) Store(_ context.Context, c *cargo.Cargo) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	r.cargos[c.TrackingID] = c
 	return nil
 }
 
-func (r *cargoRepository) Remove(c *cargo.Cargo) error {
+func (r *cargoRepository) Remove(_ context.Context, c *cargo.Cargo) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	delete(r.cargos, c.TrackingID)
 	return nil
 }
 
-func (r *cargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
+func (r *cargoRepository) Find(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
 	if val, ok := r.cargos[id]; ok {
@@ -37,14 +42,26 @@ func (r *cargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
 	return nil, cargo.ErrUnknown
 }
 
-func (r *cargoRepository) FindAll() []*cargo.Cargo {
+func (r *cargoRepository) FindMany(_ context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var c []*cargo.Cargo
+	for _, id := range ids {
+		if val, ok := r.cargos[id]; ok {
+			c = append(c, val)
+		}
+	}
+	return c, nil
+}
+
+func (r *cargoRepository) FindAll(_ context.Context) ([]*cargo.Cargo, error) {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
 	c := make([]*cargo.Cargo, 0, len(r.cargos))
 	for _, val := range r.cargos {
 		c = append(c, val)
 	}
-	return c
+	return c, nil
 }
 
 // NewCargoRepository returns a new instance of a in-memory cargo repository.
@@ -58,19 +75,19 @@ type locationRepository struct {
 	locations map[location.UNLocode]*location.Location
 }
 
-func (r *locationRepository) Find(locode location.UNLocode) (*location.Location, error) {
+func (r *locationRepository) Find(_ context.Context, locode location.UNLocode) (*location.Location, error) {
 	if l, ok := r.locations[locode]; ok {
 		return l, nil
 	}
 	return nil, location.ErrUnknown
 }
 
-func (r *locationRepository) FindAll() []*location.Location {
+func (r *locationRepository) FindAll(_ context.Context) ([]*location.Location, error) {
 	l := make([]*location.Location, 0, len(r.locations))
 	for _, val := range r.locations {
 		l = append(l, val)
 	}
-	return l
+	return l, nil
 }
 
 // NewLocationRepository returns a new instance of a in-memory location repository.
@@ -85,6 +102,8 @@ func NewLocationRepository() location.Repository {
 	r.locations[location.JNTKO] = location.Tokyo
 	r.locations[location.NLRTM] = location.Rotterdam
 	r.locations[location.DEHAM] = location.Hamburg
+	r.locations[location.USNYC] = location.NewYork
+	r.locations[location.USCHI] = location.Chicago
 
 	return r
 }
@@ -93,7 +112,7 @@ type voyageRepository struct {
 	voyages map[voyage.Number]*voyage.Voyage
 }
 
-func (r *voyageRepository) Find(voyageNumber voyage.Number) (*voyage.Voyage, error) {
+func (r *voyageRepository) Find(_ context.Context, voyageNumber voyage.Number) (*voyage.Voyage, error) {
 	if v, ok := r.voyages[voyageNumber]; ok {
 		return v, nil
 	}
@@ -101,6 +120,14 @@ func (r *voyageRepository) Find(voyageNumber voyage.Number) (*voyage.Voyage, err
 	return nil, voyage.ErrUnknown
 }
 
+func (r *voyageRepository) FindAll(_ context.Context) ([]*voyage.Voyage, error) {
+	v := make([]*voyage.Voyage, 0, len(r.voyages))
+	for _, val := range r.voyages {
+		v = append(v, val)
+	}
+	return v, nil
+}
+
 // NewVoyageRepository returns a new instance of a in-memory voyage repository.
 func NewVoyageRepository() voyage.Repository {
 	r := &voyageRepository{
@@ -110,6 +137,8 @@ func NewVoyageRepository() voyage.Repository {
 	r.voyages[voyage.V100.Number] = voyage.V100
 	r.voyages[voyage.V300.Number] = voyage.V300
 	r.voyages[voyage.V400.Number] = voyage.V400
+	r.voyages[voyage.R100.Number] = voyage.R100
+	r.voyages[voyage.T100.Number] = voyage.T100
 
 	r.voyages[voyage.V0100S.Number] = voyage.V0100S
 	r.voyages[voyage.V0200T.Number] = voyage.V0200T
@@ -125,20 +154,42 @@ type handlingEventRepository struct {
 	events map[cargo.TrackingID][]cargo.HandlingEvent
 }
 
-func (r *handlingEventRepository) Store(e cargo.HandlingEvent) {
+func (r *handlingEventRepository) Store(_ context.Context, e cargo.HandlingEvent) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
-	// Make array if it's the first event with this tracking ID.
-	if _, ok := r.events[e.TrackingID]; !ok {
-		r.events[e.TrackingID] = make([]cargo.HandlingEvent, 0)
-	}
-	r.events[e.TrackingID] = append(r.events[e.TrackingID], e)
+
+	// Insert the event after all events completed no later than it, into
+	// a new slice since histories already returned share the old one.
+	events := r.events[e.TrackingID]
+	i := sort.Search(len(events), func(i int) bool {
+		return events[i].CompletionTime.After(e.CompletionTime)
+	})
+
+	updated := make([]cargo.HandlingEvent, 0, len(events)+1)
+	updated = append(updated, events[:i]...)
+	updated = append(updated, e)
+	updated = append(updated, events[i:]...)
+
+	r.events[e.TrackingID] = updated
+	return nil
 }
 
-func (r *handlingEventRepository) QueryHandlingHistory(id cargo.TrackingID) cargo.HandlingHistory {
+func (r *handlingEventRepository) QueryHandlingHistory(_ context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
-	return cargo.HandlingHistory{HandlingEvents: r.events[id]}
+	return cargo.HandlingHistory{HandlingEvents: r.events[id]}, nil
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(_ context.Context, ids []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	h := make(map[cargo.TrackingID]cargo.HandlingHistory, len(ids))
+	for _, id := range ids {
+		if events, ok := r.events[id]; ok {
+			h[id] = cargo.HandlingHistory{HandlingEvents: events}
+		}
+	}
+	return h, nil
 }
 
 // NewHandlingEventRepository returns a new instance of a in-memory handling event repository.
@@ -147,3 +198,83 @@ func NewHandlingEventRepository() cargo.HandlingEventRepository {
 		events: make(map[cargo.TrackingID][]cargo.HandlingEvent),
 	}
 }
+
+type idempotencyStore struct {
+	mtx     sync.Mutex
+	records map[string]*idempotency.Record
+	pruned  time.Time
+}
+
+func (s *idempotencyStore) Add(_ context.Context, r *idempotency.Record) (*idempotency.Record, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+
+	// Drop expired records now and then to keep the store from growing.
+	if now.Sub(s.pruned) > time.Minute {
+		for k, rec := range s.records {
+			if now.After(rec.Expires) {
+				delete(s.records, k)
+			}
+		}
+		s.pruned = now
+	}
+
+	if rec, ok := s.records[r.Key]; ok && !now.After(rec.Expires) {
+		return rec, nil
+	}
+
+	s.records[r.Key] = r
+
+	return nil, nil
+}
+
+func (s *idempotencyStore) Update(_ context.Context, r *idempotency.Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.records[r.Key] = r
+	return nil
+}
+
+func (s *idempotencyStore) Remove(_ context.Context, key string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// NewIdempotencyStore returns a new instance of a in-memory idempotency store.
+func NewIdempotencyStore() idempotency.Store {
+	return &idempotencyStore{
+		records: make(map[string]*idempotency.Record),
+	}
+}
+
+type revocationStore struct {
+	mtx     sync.RWMutex
+	revoked map[cargo.TrackingID]time.Time
+}
+
+func (s *revocationStore) Revoke(_ context.Context, id cargo.TrackingID, t time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if t.After(s.revoked[id]) {
+		s.revoked[id] = t
+	}
+	return nil
+}
+
+func (s *revocationStore) Revoked(_ context.Context, id cargo.TrackingID) (time.Time, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.revoked[id], nil
+}
+
+// NewRevocationStore returns a new instance of a in-memory store of revoked
+// tracking tokens.
+func NewRevocationStore() token.RevocationStore {
+	return &revocationStore{
+		revoked: make(map[cargo.TrackingID]time.Time),
+	}
+}
@@ -1,12 +1,16 @@
 // Package inspection provides means to inspect cargos.
 package inspection
 
-import "github.com/marcusolsson/goddd/cargo"
+import (
+	"context"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
 
 // EventHandler provides means of subscribing to inspection events.
 type EventHandler interface {
-	CargoWasMisdirected(*cargo.Cargo)
-	CargoHasArrived(*cargo.Cargo)
+	CargoWasMisdirected(ctx context.Context, c *cargo.Cargo)
+	CargoHasArrived(ctx context.Context, c *cargo.Cargo)
 }
 
 // Service provides cargo inspection operations.
@@ -14,7 +18,7 @@ type Service interface {
 	// InspectCargo inspects cargo and send relevant notifications to
 	// interested parties, for example if a cargo has been misdirected, or
 	// unloaded at the final destination.
-	InspectCargo(id cargo.TrackingID)
+	InspectCargo(ctx context.Context, id cargo.TrackingID)
 }
 
 type service struct {
@@ -24,28 +28,51 @@ type service struct {
 }
 
 // TODO: Should be transactional
-func (s *service) InspectCargo(id cargo.TrackingID) {
-	c, err := s.cargos.Find(id)
+func (s *service) InspectCargo(ctx context.Context, id cargo.TrackingID) {
+	c, err := s.cargos.Find(ctx, id)
 	if err != nil {
 		return
 	}
 
-	h := s.events.QueryHandlingHistory(id)
+	h, err := s.events.QueryHandlingHistory(ctx, id)
+	if err != nil {
+		return
+	}
 
 	c.DeriveDeliveryProgress(h)
 
 	if c.Delivery.IsMisdirected {
-		s.handler.CargoWasMisdirected(c)
+		s.handler.CargoWasMisdirected(ctx, c)
 	}
 
 	if c.Delivery.IsUnloadedAtDestination {
-		s.handler.CargoHasArrived(c)
+		s.handler.CargoHasArrived(ctx, c)
 	}
 
-	s.cargos.Store(c)
+	s.cargos.Store(ctx, c)
 }
 
 // NewService creates a inspection service with necessary dependencies.
 func NewService(cargos cargo.Repository, events cargo.HandlingEventRepository, handler EventHandler) Service {
 	return &service{cargos, events, handler}
 }
+
+type multiEventHandler []EventHandler
+
+func (hs multiEventHandler) CargoWasMisdirected(ctx context.Context, c *cargo.Cargo) {
+	for _, h := range hs {
+		h.CargoWasMisdirected(ctx, c)
+	}
+}
+
+func (hs multiEventHandler) CargoHasArrived(ctx context.Context, c *cargo.Cargo) {
+	for _, h := range hs {
+		h.CargoHasArrived(ctx, c)
+	}
+}
+
+// NewMultiEventHandler returns an EventHandler notifying each of the handlers
+// in turn.
+func NewMultiEventHandler(hs ...EventHandler) EventHandler {
+	return multiEventHandler(hs)
+}
@@ -1,6 +1,7 @@
 package inspection
 
 import (
+	"context"
 	"testing"
 
 	"github.com/marcusolsson/goddd/cargo"
@@ -12,11 +13,11 @@ type stubEventHandler struct {
 	events []interface{}
 }
 
-func (h *stubEventHandler) CargoWasMisdirected(c *cargo.Cargo) {
+func (h *stubEventHandler) CargoWasMisdirected(_ context.Context, c *cargo.Cargo) {
 	h.events = append(h.events, c)
 }
 
-func (h *stubEventHandler) CargoHasArrived(c *cargo.Cargo) {
+func (h *stubEventHandler) CargoHasArrived(_ context.Context, c *cargo.Cargo) {
 	h.events = append(h.events, c)
 }
 
@@ -44,7 +45,7 @@ func TestInspectMisdirectedCargo(t *testing.T) {
 		{VoyageNumber: voyage, LoadLocation: location.AUMEL, UnloadLocation: location.CNHKG},
 	}})
 
-	if err := cargos.Store(c); err != nil {
+	if err := cargos.Store(context.Background(), c); err != nil {
 		t.Fatal(err)
 	}
 
@@ -56,13 +57,13 @@ func TestInspectMisdirectedCargo(t *testing.T) {
 		t.Errorf("no events should be handled")
 	}
 
-	s.InspectCargo(id)
+	s.InspectCargo(context.Background(), id)
 
 	if len(handler.events) != 1 {
 		t.Errorf("1 event should be handled")
 	}
 
-	s.InspectCargo("no_such_id")
+	s.InspectCargo(context.Background(), "no_such_id")
 
 	// no events was published
 	if len(handler.events) != 1 {
@@ -98,7 +99,7 @@ func TestInspectUnloadedCargo(t *testing.T) {
 		{VoyageNumber: voyage, LoadLocation: location.AUMEL, UnloadLocation: location.CNHKG},
 	}})
 
-	cargos.Store(unloadedCargo)
+	cargos.Store(context.Background(), unloadedCargo)
 
 	storeEvent(&events, id, voyage, cargo.Receive, location.SESTO)
 	storeEvent(&events, id, voyage, cargo.Load, location.SESTO)
@@ -110,7 +111,7 @@ func TestInspectUnloadedCargo(t *testing.T) {
 		t.Errorf("len(handler.events) = %d; want = %d", len(handler.events), 0)
 	}
 
-	s.InspectCargo(id)
+	s.InspectCargo(context.Background(), id)
 
 	if len(handler.events) != 1 {
 		t.Errorf("len(handler.events) = %d; want = %d", len(handler.events), 1)
@@ -127,40 +128,66 @@ func storeEvent(r cargo.HandlingEventRepository, id cargo.TrackingID, voyageNumb
 		},
 	}
 
-	r.Store(e)
+	r.Store(context.Background(), e)
 }
 
 type mockCargoRepository struct {
 	cargo *cargo.Cargo
 }
 
-func (r *mockCargoRepository) Store(c *cargo.Cargo) error {
+func (r *mockCargoRepository) Store(_ context.Context, c *cargo.Cargo) error {
 	r.cargo = c
 	return nil
 }
 
-func (r *mockCargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
-	if r.cargo != nil {
+func (r *mockCargoRepository) Find(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+	if r.cargo != nil && r.cargo.TrackingID == id {
 		return r.cargo, nil
 	}
 	return nil, cargo.ErrUnknown
 }
 
-func (r *mockCargoRepository) FindAll() []*cargo.Cargo {
-	return []*cargo.Cargo{r.cargo}
+func (r *mockCargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	var cs []*cargo.Cargo
+	for _, id := range ids {
+		if c, err := r.Find(ctx, id); err == nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+func (r *mockCargoRepository) FindAll(_ context.Context) ([]*cargo.Cargo, error) {
+	return []*cargo.Cargo{r.cargo}, nil
+}
+
+func (r *mockCargoRepository) Remove(_ context.Context, c *cargo.Cargo) error {
+	if r.cargo != nil && r.cargo.TrackingID == c.TrackingID {
+		r.cargo = nil
+	}
+	return nil
 }
 
 type mockHandlingEventRepository struct {
 	events map[cargo.TrackingID][]cargo.HandlingEvent
 }
 
-func (r *mockHandlingEventRepository) Store(e cargo.HandlingEvent) {
+func (r *mockHandlingEventRepository) Store(_ context.Context, e cargo.HandlingEvent) error {
 	if _, ok := r.events[e.TrackingID]; !ok {
 		r.events[e.TrackingID] = make([]cargo.HandlingEvent, 0)
 	}
 	r.events[e.TrackingID] = append(r.events[e.TrackingID], e)
+	return nil
 }
 
-func (r *mockHandlingEventRepository) QueryHandlingHistory(id cargo.TrackingID) cargo.HandlingHistory {
-	return cargo.HandlingHistory{HandlingEvents: r.events[id]}
+func (r *mockHandlingEventRepository) QueryHandlingHistory(_ context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
+	return cargo.HandlingHistory{HandlingEvents: r.events[id]}, nil
+}
+
+func (r *mockHandlingEventRepository) QueryHandlingHistories(_ context.Context, ids []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+	h := make(map[cargo.TrackingID]cargo.HandlingHistory)
+	for _, id := range ids {
+		h[id] = cargo.HandlingHistory{HandlingEvents: r.events[id]}
+	}
+	return h, nil
 }
@@ -0,0 +1,210 @@
+// Package instrumenting provides repository decorators recording Prometheus
+// style metrics, regardless of the storage backend.
+package instrumenting
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// RepositoryMetrics are the metrics recorded for each repository call. Each
+// metric is labeled by backend and operation.
+type RepositoryMetrics struct {
+	// Latency observes the duration of each call, in seconds.
+	Latency metrics.Histogram
+
+	// Errors counts the calls returning an error. Lookups of unknown
+	// entities are not counted as errors.
+	Errors metrics.Counter
+
+	// Results observes the number of entities returned by lookups.
+	Results metrics.Histogram
+}
+
+// observer records the metrics for a single backend.
+type observer struct {
+	backend string
+	metrics RepositoryMetrics
+}
+
+func (o observer) observe(op string, begin time.Time, err error) {
+	o.metrics.Latency.With("backend", o.backend, "operation", op).Observe(time.Since(begin).Seconds())
+	if err != nil {
+		o.metrics.Errors.With("backend", o.backend, "operation", op).Add(1)
+	}
+}
+
+func (o observer) results(op string, n int) {
+	o.metrics.Results.With("backend", o.backend, "operation", op).Observe(float64(n))
+}
+
+type cargoRepository struct {
+	observer
+	cargo.Repository
+}
+
+// NewCargoRepository returns a cargo repository recording metrics for every
+// call to r, labeled with the given backend.
+func NewCargoRepository(backend string, m RepositoryMetrics, r cargo.Repository) cargo.Repository {
+	return &cargoRepository{observer{backend, m}, r}
+}
+
+func (r *cargoRepository) Store(ctx context.Context, c *cargo.Cargo) (err error) {
+	defer func(begin time.Time) {
+		r.observe("cargo_store", begin, err)
+	}(time.Now())
+
+	return r.Repository.Store(ctx, c)
+}
+
+func (r *cargoRepository) Remove(ctx context.Context, c *cargo.Cargo) (err error) {
+	defer func(begin time.Time) {
+		r.observe("cargo_remove", begin, err)
+	}(time.Now())
+
+	return r.Repository.Remove(ctx, c)
+}
+
+func (r *cargoRepository) Find(ctx context.Context, id cargo.TrackingID) (c *cargo.Cargo, err error) {
+	defer func(begin time.Time) {
+		r.observe("cargo_find", begin, ignore(err, cargo.ErrUnknown))
+		r.results("cargo_find", found(err))
+	}(time.Now())
+
+	return r.Repository.Find(ctx, id)
+}
+
+func (r *cargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) (cs []*cargo.Cargo, err error) {
+	defer func(begin time.Time) {
+		r.observe("cargo_find_many", begin, err)
+		r.results("cargo_find_many", len(cs))
+	}(time.Now())
+
+	return r.Repository.FindMany(ctx, ids)
+}
+
+func (r *cargoRepository) FindAll(ctx context.Context) (cs []*cargo.Cargo, err error) {
+	defer func(begin time.Time) {
+		r.observe("cargo_find_all", begin, err)
+		r.results("cargo_find_all", len(cs))
+	}(time.Now())
+
+	return r.Repository.FindAll(ctx)
+}
+
+type locationRepository struct {
+	observer
+	location.Repository
+}
+
+// NewLocationRepository returns a location repository recording metrics for
+// every call to r, labeled with the given backend.
+func NewLocationRepository(backend string, m RepositoryMetrics, r location.Repository) location.Repository {
+	return &locationRepository{observer{backend, m}, r}
+}
+
+func (r *locationRepository) Find(ctx context.Context, locode location.UNLocode) (l *location.Location, err error) {
+	defer func(begin time.Time) {
+		r.observe("location_find", begin, ignore(err, location.ErrUnknown))
+		r.results("location_find", found(err))
+	}(time.Now())
+
+	return r.Repository.Find(ctx, locode)
+}
+
+func (r *locationRepository) FindAll(ctx context.Context) (ls []*location.Location, err error) {
+	defer func(begin time.Time) {
+		r.observe("location_find_all", begin, err)
+		r.results("location_find_all", len(ls))
+	}(time.Now())
+
+	return r.Repository.FindAll(ctx)
+}
+
+type voyageRepository struct {
+	observer
+	voyage.Repository
+}
+
+// NewVoyageRepository returns a voyage repository recording metrics for every
+// call to r, labeled with the given backend.
+func NewVoyageRepository(backend string, m RepositoryMetrics, r voyage.Repository) voyage.Repository {
+	return &voyageRepository{observer{backend, m}, r}
+}
+
+func (r *voyageRepository) Find(ctx context.Context, number voyage.Number) (v *voyage.Voyage, err error) {
+	defer func(begin time.Time) {
+		r.observe("voyage_find", begin, ignore(err, voyage.ErrUnknown))
+		r.results("voyage_find", found(err))
+	}(time.Now())
+
+	return r.Repository.Find(ctx, number)
+}
+
+func (r *voyageRepository) FindAll(ctx context.Context) (vs []*voyage.Voyage, err error) {
+	defer func(begin time.Time) {
+		r.observe("voyage_find_all", begin, err)
+		r.results("voyage_find_all", len(vs))
+	}(time.Now())
+
+	return r.Repository.FindAll(ctx)
+}
+
+type handlingEventRepository struct {
+	observer
+	cargo.HandlingEventRepository
+}
+
+// NewHandlingEventRepository returns a handling event repository recording
+// metrics for every call to r, labeled with the given backend.
+func NewHandlingEventRepository(backend string, m RepositoryMetrics, r cargo.HandlingEventRepository) cargo.HandlingEventRepository {
+	return &handlingEventRepository{observer{backend, m}, r}
+}
+
+func (r *handlingEventRepository) Store(ctx context.Context, e cargo.HandlingEvent) (err error) {
+	defer func(begin time.Time) {
+		r.observe("handling_event_store", begin, err)
+	}(time.Now())
+
+	return r.HandlingEventRepository.Store(ctx, e)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistory(ctx context.Context, id cargo.TrackingID) (h cargo.HandlingHistory, err error) {
+	defer func(begin time.Time) {
+		r.observe("handling_event_query_handling_history", begin, err)
+		r.results("handling_event_query_handling_history", len(h.HandlingEvents))
+	}(time.Now())
+
+	return r.HandlingEventRepository.QueryHandlingHistory(ctx, id)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ctx context.Context, ids []cargo.TrackingID) (hs map[cargo.TrackingID]cargo.HandlingHistory, err error) {
+	defer func(begin time.Time) {
+		r.observe("handling_event_query_handling_histories", begin, err)
+		r.results("handling_event_query_handling_histories", len(hs))
+	}(time.Now())
+
+	return r.HandlingEventRepository.QueryHandlingHistories(ctx, ids)
+}
+
+// ignore returns nil if err is the expected error.
+func ignore(err, expected error) error {
+	if err == expected {
+		return nil
+	}
+	return err
+}
+
+// found returns the number of entities returned by a lookup.
+func found(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
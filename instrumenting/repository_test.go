@@ -0,0 +1,141 @@
+package instrumenting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+// recorder records observations by label values.
+type recorder struct {
+	mtx    *sync.Mutex
+	values map[string][]float64
+	labels []string
+}
+
+func newRecorder() *recorder {
+	return &recorder{mtx: &sync.Mutex{}, values: make(map[string][]float64)}
+}
+
+func (r *recorder) With(labelValues ...string) metrics.Histogram {
+	return &recorder{mtx: r.mtx, values: r.values, labels: append(append([]string(nil), r.labels...), labelValues...)}
+}
+
+func (r *recorder) Observe(value float64) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	k := strings.Join(r.labels, ",")
+	r.values[k] = append(r.values[k], value)
+}
+
+func (r *recorder) Add(delta float64) { r.Observe(delta) }
+
+func (r *recorder) get(labelValues ...string) []float64 {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.values[strings.Join(labelValues, ",")]
+}
+
+type counter struct{ *recorder }
+
+func (c counter) With(labelValues ...string) metrics.Counter {
+	return counter{c.recorder.With(labelValues...).(*recorder)}
+}
+
+func TestCargoRepository(t *testing.T) {
+	var (
+		latency = newRecorder()
+		errs    = newRecorder()
+		results = newRecorder()
+	)
+
+	r := NewCargoRepository("inmem", RepositoryMetrics{
+		Latency: latency,
+		Errors:  counter{errs},
+		Results: results,
+	}, inmem.NewCargoRepository())
+
+	ctx := context.Background()
+
+	c := cargo.New("ABC123", cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.AUMEL,
+	})
+
+	if err := r.Store(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Find(ctx, "ABC123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Find(ctx, "no_such_id"); err != cargo.ErrUnknown {
+		t.Fatalf("err = %v; want = %v", err, cargo.ErrUnknown)
+	}
+	if _, err := r.FindAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(latency.get("backend", "inmem", "operation", "cargo_find")); got != 2 {
+		t.Errorf("len(latency) = %d; want = %d", got, 2)
+	}
+	if got := len(latency.get("backend", "inmem", "operation", "cargo_store")); got != 1 {
+		t.Errorf("len(latency) = %d; want = %d", got, 1)
+	}
+	if got := results.get("backend", "inmem", "operation", "cargo_find"); len(got) != 2 || got[0] != 1 || got[1] != 0 {
+		t.Errorf("results = %v; want = %v", got, []float64{1, 0})
+	}
+	if got := results.get("backend", "inmem", "operation", "cargo_find_all"); len(got) != 1 || got[0] != 1 {
+		t.Errorf("results = %v; want = %v", got, []float64{1})
+	}
+	if len(errs.values) != 0 {
+		t.Errorf("errors = %v; want none", errs.values)
+	}
+}
+
+func TestHandlingEventRepositoryErrors(t *testing.T) {
+	errs := newRecorder()
+
+	down := errors.New("down")
+
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(context.Context, cargo.HandlingEvent) error { return down }
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, down
+	}
+	events.QueryHandlingHistoriesFn = func(context.Context, []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+		return nil, down
+	}
+
+	r := NewHandlingEventRepository("mock", RepositoryMetrics{
+		Latency: newRecorder(),
+		Errors:  counter{errs},
+		Results: newRecorder(),
+	}, &events)
+
+	ctx := context.Background()
+
+	if err := r.Store(ctx, cargo.HandlingEvent{TrackingID: "ABC123"}); err != down {
+		t.Errorf("err = %v; want = %v", err, down)
+	}
+	if _, err := r.QueryHandlingHistory(ctx, "ABC123"); err != down {
+		t.Errorf("err = %v; want = %v", err, down)
+	}
+	if _, err := r.QueryHandlingHistories(ctx, []cargo.TrackingID{"ABC123"}); err != down {
+		t.Errorf("err = %v; want = %v", err, down)
+	}
+
+	for _, op := range []string{"handling_event_store", "handling_event_query_handling_history", "handling_event_query_handling_histories"} {
+		if got := errs.get("backend", "mock", "operation", op); len(got) != 1 {
+			t.Errorf("errors(%s) = %v; want = %v", op, got, []float64{1})
+		}
+	}
+}
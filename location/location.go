@@ -1,7 +1,10 @@
 // Package location provides the Location aggregate.
 package location
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // UNLocode is the United Nations location code that uniquely identifies a
 // particular location.
@@ -22,6 +25,6 @@ var ErrUnknown = errors.New("unknown location")
 
 // Repository provides access a location store.
 type Repository interface {
-	Find(locode UNLocode) (*Location, error)
-	FindAll() []*Location
+	Find(ctx context.Context, locode UNLocode) (*Location, error)
+	FindAll(ctx context.Context) ([]*Location, error)
 }
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	cryptorand "crypto/rand"
 	"flag"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,93 +15,234 @@ import (
 	"syscall"
 	"time"
 
+	// Tracking text is formatted in time zones requested by clients, and the
+	// image has no time zone database of its own.
+	_ "time/tzdata"
+
+	"github.com/afex/hystrix-go/hystrix"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
 	"gopkg.in/mgo.v2"
 
 	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 
 	"github.com/marcusolsson/goddd/booking"
+	bookingpb "github.com/marcusolsson/goddd/booking/pb"
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/config"
+	"github.com/marcusolsson/goddd/cors"
+	"github.com/marcusolsson/goddd/eta"
+	"github.com/marcusolsson/goddd/graph"
 	"github.com/marcusolsson/goddd/handling"
+	handlingpb "github.com/marcusolsson/goddd/handling/pb"
+	"github.com/marcusolsson/goddd/health"
+	"github.com/marcusolsson/goddd/httpctx"
+	"github.com/marcusolsson/goddd/idempotency"
 	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/inspection"
+	"github.com/marcusolsson/goddd/instrumenting"
 	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/mongo"
+	"github.com/marcusolsson/goddd/openapi"
+	"github.com/marcusolsson/goddd/requestid"
 	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/statistics"
+	"github.com/marcusolsson/goddd/token"
+	"github.com/marcusolsson/goddd/tracing"
 	"github.com/marcusolsson/goddd/tracking"
+	trackingpb "github.com/marcusolsson/goddd/tracking/pb"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
-const (
-	defaultPort              = "8080"
-	defaultRoutingServiceURL = "http://localhost:7878"
-	defaultMongoDBURL        = "127.0.0.1"
-	defaultDBName            = "dddsample"
-)
-
 func main() {
 	var (
-		addr                 = envString("PORT", defaultPort)
-		rsurl                = envString("ROUTINGSERVICE_URL", defaultRoutingServiceURL)
-		dburl                = envString("MONGODB_URL", defaultMongoDBURL)
-		dbname               = envString("DB_NAME", defaultDBName)
-		mongoMaxPoolSize     = envString("MONGO_MAXPOOLSIZE", "60")
-		bookingSummaryMaxAge = envInt("BOOKING_MAXAGE", 15)
-
-		httpAddr          = flag.String("http.addr", ":"+addr, "HTTP listen address")
-		routingServiceURL = flag.String("service.routing", rsurl, "routing service URL")
-		mongoDBURL        = flag.String("db.url", dburl, "MongoDB URL")
-		databaseName      = flag.String("db.name", dbname, "MongoDB database name")
-		inmemory          = flag.Bool("inmem", false, "use in-memory repositories")
+		fs          = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		printConfig = fs.Bool("print-config", false, "print the effective configuration and exit")
 
 		ctx = context.Background()
 	)
 
-	flag.Parse()
+	cfg, err := config.Load(fs, os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *printConfig {
+		if err := cfg.Encode(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	var logger log.Logger
-	logger = log.NewLogfmtLogger(os.Stderr)
+	switch cfg.Logging.Format {
+	case config.LogFormatJSON:
+		logger = log.NewJSONLogger(os.Stderr)
+	default:
+		logger = log.NewLogfmtLogger(os.Stderr)
+	}
 	logger = &serializedLogger{Logger: logger}
+	logger = level.New(logger, level.Allowed(allowedLevels(cfg.Logging.Level)))
 	logger = log.NewContext(logger).With("ts", log.DefaultTimestampUTC)
 
+	// Setup tracing. A nil tracer disables tracing.
+	var tracer *tracing.Tracer
+	switch cfg.Tracing.Exporter {
+	case config.TracingStdout:
+		tracer = tracing.NewTracer(tracing.NewWriterExporter(os.Stdout, cfg.Tracing.ServiceName), cfg.Tracing.SampleRatio)
+	case config.TracingOTLP:
+		tracer = tracing.NewTracer(tracing.NewOTLPExporter(
+			cfg.Tracing.Endpoint,
+			cfg.Tracing.ServiceName,
+			level.Warn(log.NewContext(logger).With("component", "tracing")),
+		), cfg.Tracing.SampleRatio)
+	}
+
 	// Setup repositories
 	var (
 		cargos         cargo.Repository
 		locations      location.Repository
 		voyages        voyage.Repository
 		handlingEvents cargo.HandlingEventRepository
+
+		idempotencyStore idempotency.Store
+		revocations      token.RevocationStore
+
+		session *mgo.Session
 	)
 
-	if *inmemory {
+	if cfg.Storage.Backend == config.StorageInmem {
 		cargos = inmem.NewCargoRepository()
 		locations = inmem.NewLocationRepository()
 		voyages = inmem.NewVoyageRepository()
 		handlingEvents = inmem.NewHandlingEventRepository()
+		idempotencyStore = inmem.NewIdempotencyStore()
+		revocations = inmem.NewRevocationStore()
 	} else {
-		session, err := mgo.Dial(*mongoDBURL + "?maxPoolSize=" + mongoMaxPoolSize)
+		session, err = mgo.Dial(cfg.Storage.Mongo.URL + "?maxPoolSize=" + strconv.Itoa(cfg.Storage.Mongo.MaxPoolSize))
 		if err != nil {
 			panic(err)
 		}
-		defer session.Close()
 
 		session.SetMode(mgo.Monotonic, true)
 
-		cargos, err = mongo.NewCargoRepository(*databaseName, session)
+		cargos, err = mongo.NewCargoRepository(cfg.Storage.Mongo.Database, session, cfg.Storage.Mongo.PaddingBytes)
 		if err != nil {
 			panic(err)
 		}
-		locations, err = mongo.NewLocationRepository(*databaseName, session)
+		locations, err = mongo.NewLocationRepository(cfg.Storage.Mongo.Database, session)
 		if err != nil {
 			panic(err)
 		}
-		voyages, err = mongo.NewVoyageRepository(*databaseName, session)
+		voyages, err = mongo.NewVoyageRepository(cfg.Storage.Mongo.Database, session)
+		if err != nil {
+			panic(err)
+		}
+		handlingEvents, err = mongo.NewHandlingEventRepository(cfg.Storage.Mongo.Database, session)
+		if err != nil {
+			panic(err)
+		}
+		idempotencyStore, err = mongo.NewIdempotencyStore(cfg.Storage.Mongo.Database, session)
+		if err != nil {
+			panic(err)
+		}
+		revocations, err = mongo.NewRevocationStore(cfg.Storage.Mongo.Database, session)
 		if err != nil {
 			panic(err)
 		}
-		handlingEvents = mongo.NewHandlingEventRepository(*databaseName, session)
 	}
 
+	repositoryKeys := []string{"backend", "operation"}
+	repositoryMetrics := instrumenting.RepositoryMetrics{
+		Latency: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of repository operations in seconds.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, repositoryKeys),
+		Errors: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "operation_errors_total",
+			Help:      "Number of repository operations returning an error.",
+		}, repositoryKeys),
+		Results: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "operation_results",
+			Help:      "Number of entities returned by repository lookups.",
+			Buckets:   []float64{0, 1, 5, 10, 50, 100, 500, 1000},
+		}, repositoryKeys),
+	}
+
+	cargos = instrumenting.NewCargoRepository(cfg.Storage.Backend, repositoryMetrics, cargos)
+	locations = instrumenting.NewLocationRepository(cfg.Storage.Backend, repositoryMetrics, locations)
+	voyages = instrumenting.NewVoyageRepository(cfg.Storage.Backend, repositoryMetrics, voyages)
+	handlingEvents = instrumenting.NewHandlingEventRepository(cfg.Storage.Backend, repositoryMetrics, handlingEvents)
+
+	// Count the stored cargos from the repository whenever metrics are
+	// scraped, so that all instances agree on them.
+	census := statistics.NewCensus(statistics.Gauges{
+		Cargos: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Name: "cargos",
+			Help: "Number of cargos by routing and transport status.",
+		}, []string{"routing_status", "transport_status"}),
+		MisdirectedCargos: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Name: "cargos_misdirected",
+			Help: "Number of misdirected cargos.",
+		}, []string{}),
+		CargosPerLocation: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Name: "cargos_by_location",
+			Help: "Number of cargos by last known location.",
+		}, []string{"location"}),
+	}, cargos)
+
+	// Keep business metrics up to date as cargos are handled.
+	recorder := statistics.NewRecorder(statistics.Metrics{
+		HandlingEvents: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Name: "handling_events_total",
+			Help: "Number of handling events by type and location.",
+		}, []string{"type", "location"}),
+		Deliveries: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Name: "deliveries_total",
+			Help: "Number of cargos unloaded at their destination, on time or late.",
+		}, []string{"outcome"}),
+		DwellTime: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Name:    "port_dwell_time_seconds",
+			Help:    "Time cargos spend in port between being unloaded and loaded or claimed.",
+			Buckets: []float64{60, 600, 3600, 6 * 3600, 24 * 3600, 3 * 24 * 3600, 7 * 24 * 3600},
+		}, []string{"location"}),
+	})
+	existing, err := cargos.FindAll(ctx)
+	if err != nil {
+		panic(err)
+	}
+	recorder.Seed(existing)
+
+	cargos = statistics.NewCargoRepository(recorder, cargos)
+
+	// Learn how late voyages and ports are from past handling events, and
+	// keep learning as cargos are handled.
+	estimator := eta.NewEstimator(cargos)
+	seedEstimator(ctx, estimator, handlingEvents, existing, log.NewContext(logger).With("component", "eta"))
+
+	if tracer != nil {
+		cargos = tracing.NewCargoRepository(tracer, cargos)
+		locations = tracing.NewLocationRepository(tracer, locations)
+		voyages = tracing.NewVoyageRepository(tracer, voyages)
+		handlingEvents = tracing.NewHandlingEventRepository(tracer, handlingEvents)
+	}
+
+	// Push tracking updates to streaming clients as cargos are handled and
+	// inspected.
+	trackingStream := tracking.NewStream(cargos, handlingEvents, locations, estimator, cfg.Tracking.StreamHistory)
+
 	// Configure some questionable dependencies.
 	var (
 		handlingEventFactory = cargo.HandlingEventFactory{
@@ -107,22 +250,77 @@ func main() {
 			VoyageRepository:   voyages,
 			LocationRepository: locations,
 		}
-		handlingEventHandler = handling.NewEventHandler(
-			inspection.NewService(cargos, handlingEvents, nil),
+		handlingEventHandler = handling.NewAsyncEventHandler(
+			handling.NewMultiEventHandler(
+				recorder,
+				estimator,
+				handling.NewEventHandler(inspection.NewService(cargos, handlingEvents,
+					inspection.NewMultiEventHandler(recorder, trackingStream))),
+				// Notified after inspection, once the delivery progress of
+				// the cargo has been updated.
+				trackingStream,
+			),
+			cfg.Inspection.QueueSize,
 		)
 	)
 
 	// Facilitate testing by adding some cargos.
-	storeTestData(cargos)
+	storeTestData(ctx, cargos)
 
 	fieldKeys := []string{"method"}
 
-	var rs routing.Service
-	rs = routing.NewProxyingMiddleware(ctx, *routingServiceURL)(rs)
+	routingLogger := log.NewContext(logger).With("component", "routing")
+
+	pathfinder := routing.NewProxyService(routing.ProxyConfig{
+		URL:     cfg.Routing.URL,
+		Path:    cfg.Routing.Path,
+		Timeout: cfg.Routing.Timeout,
+		Retries: cfg.Routing.Retries,
+		Backoff: cfg.Routing.Backoff,
+		CircuitBreaker: hystrix.CommandConfig{
+			Timeout:                int(cfg.Routing.CircuitBreaker.Timeout / time.Millisecond),
+			MaxConcurrentRequests:  cfg.Routing.CircuitBreaker.MaxConcurrentRequests,
+			RequestVolumeThreshold: cfg.Routing.CircuitBreaker.RequestVolumeThreshold,
+			SleepWindow:            int(cfg.Routing.CircuitBreaker.SleepWindow / time.Millisecond),
+			ErrorPercentThreshold:  cfg.Routing.CircuitBreaker.ErrorPercentThreshold,
+		},
+	}, tracer)
+
+	// Fall back from the pathfinder on routing over the known voyages, and
+	// then on routes the pathfinder found earlier.
+	var routeCache *routing.Cache
+	if cfg.Routing.Cache.TTL > 0 {
+		routeCache = routing.NewCache(cfg.Routing.Cache.TTL, cfg.Routing.Cache.StaleTTL)
+		pathfinder = routeCache.Middleware()(pathfinder)
+	}
+
+	fallbacks := []routing.Fallback{{Name: "pathfinder", Service: pathfinder}}
+	if cfg.Routing.LocalFallback {
+		fallbacks = append(fallbacks, routing.Fallback{
+			Name:    "local",
+			Service: routing.NewLocalService(voyages),
+		})
+	}
+	if routeCache != nil {
+		fallbacks = append(fallbacks, routing.Fallback{Name: "cache", Service: routeCache.Stale()})
+	}
+
+	rs := routing.NewFallbackService(routingLogger, fallbacks...)
+
+	tokenKey := []byte(cfg.Tracking.TokenKey)
+	if len(tokenKey) == 0 {
+		tokenKey = make([]byte, 32)
+		if _, err := cryptorand.Read(tokenKey); err != nil {
+			panic(err)
+		}
+		level.Warn(log.NewContext(logger).With("component", "tracking")).Log("msg", "no tracking token key configured, public tracking links will not survive restarts or work across instances")
+	}
+	tokens := token.NewIssuer(tokenKey, cfg.Tracking.TokenTTL, revocations)
 
 	var bs booking.Service
-	bs = booking.NewService(cargos, locations, handlingEvents, rs)
-	//bs = booking.NewLoggingService(log.NewContext(logger).With("component", "booking"), bs)
+	bs = booking.NewService(cargos, locations, handlingEvents, rs, tokens, estimator)
+	bs = booking.NewValidatingService(locations, voyages, bs)
+	bs = booking.NewLoggingService(log.NewContext(logger).With("component", "booking"), bs)
 	bs = booking.NewInstrumentingService(
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "api",
@@ -135,14 +333,17 @@ func main() {
 			Subsystem: "booking_service",
 			Name:      "request_latency_microseconds",
 			Help:      "Total duration of requests in microseconds.",
-			MaxAge:    time.Second * time.Duration(bookingSummaryMaxAge),
+			MaxAge:    cfg.Metrics.SummaryMaxAge,
 		}, fieldKeys),
 		bs,
 	)
+	if tracer != nil {
+		bs = booking.NewTracingService(tracer, bs)
+	}
 
 	var ts tracking.Service
-	ts = tracking.NewService(cargos, handlingEvents)
-	//ts = tracking.NewLoggingService(log.NewContext(logger).With("component", "tracking"), ts)
+	ts = tracking.NewService(cargos, handlingEvents, locations, tokens, estimator)
+	ts = tracking.NewLoggingService(log.NewContext(logger).With("component", "tracking"), ts)
 	ts = tracking.NewInstrumentingService(
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "api",
@@ -155,13 +356,24 @@ func main() {
 			Subsystem: "tracking_service",
 			Name:      "request_latency_microseconds",
 			Help:      "Total duration of requests in microseconds.",
+			MaxAge:    cfg.Metrics.SummaryMaxAge,
 		}, fieldKeys),
 		ts,
 	)
+	if tracer != nil {
+		ts = tracking.NewTracingService(tracer, ts)
+	}
+
+	handlingRules := handling.DefaultRules()
+	handlingRules.DuplicateWindow = cfg.Handling.DuplicateWindow
+	if !cfg.Handling.CheckSequence {
+		handlingRules.Transitions = nil
+	}
 
 	var hs handling.Service
-	hs = handling.NewService(handlingEvents, handlingEventFactory, handlingEventHandler)
-	//hs = handling.NewLoggingService(log.NewContext(logger).With("component", "handling"), hs)
+	hs = handling.NewService(handlingEvents, handlingEventFactory, handlingEventHandler, handlingRules)
+	hs = handling.NewValidatingService(locations, voyages, hs)
+	hs = handling.NewLoggingService(log.NewContext(logger).With("component", "handling"), hs)
 	hs = handling.NewInstrumentingService(
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "api",
@@ -174,71 +386,222 @@ func main() {
 			Subsystem: "handling_service",
 			Name:      "request_latency_microseconds",
 			Help:      "Total duration of requests in microseconds.",
+			MaxAge:    cfg.Metrics.SummaryMaxAge,
 		}, fieldKeys),
 		hs,
 	)
+	if tracer != nil {
+		hs = handling.NewTracingService(tracer, hs)
+	}
 
 	httpLogger := log.NewContext(logger).With("component", "http")
 
+	corsPolicy := cors.Policy{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+
+	var (
+		bookingHandler  = booking.MakeHandler(ctx, bs, httpLogger)
+		trackingHandler = tracking.MakeHandler(ctx, ts, trackingStream, corsPolicy, httpLogger)
+		handlingHandler = handling.MakeHandler(ctx, hs, httpLogger)
+		graphHandler    = graph.MakeHandler(ctx, graph.Services{
+			Booking:   bs,
+			Tracking:  ts,
+			Handling:  hs,
+			Locations: locations,
+			Voyages:   voyages,
+		}, httpLogger)
+	)
+
+	// Optionally reject requests not matching the OpenAPI specifications.
+	validate := func(spec *openapi.Spec, h http.Handler) http.Handler {
+		if !cfg.HTTP.ValidateRequests {
+			return h
+		}
+		return openapi.Validate(spec, h)
+	}
+
+	// Replay the responses to retried requests carrying an Idempotency-Key.
+	idempotent := func(h http.Handler) http.Handler {
+		return idempotency.Handler(idempotencyStore, cfg.HTTP.IdempotencyTTL, h)
+	}
+
 	mux := http.NewServeMux()
 
-	mux.Handle("/booking/v1/", booking.MakeHandler(ctx, bs, httpLogger))
-	mux.Handle("/tracking/v1/", tracking.MakeHandler(ctx, ts, httpLogger))
-	mux.Handle("/handling/v1/", handling.MakeHandler(ctx, hs, httpLogger))
+	mux.Handle("/booking/v1/", idempotent(validate(booking.OpenAPI, bookingHandler)))
+	mux.Handle("/tracking/v1/", idempotent(validate(tracking.OpenAPI, trackingHandler)))
+	mux.Handle("/handling/v1/", idempotent(validate(handling.OpenAPI, handlingHandler)))
+	mux.Handle("/graphql", idempotent(graphHandler))
+
+	corsRoutes := cors.Routes{
+		bookingHandler,
+		trackingHandler,
+		handlingHandler,
+		graphHandler,
+	}
+
+	checks := health.New(cfg.HTTP.HealthCheckTimeout)
+	// Routes are found by the fallbacks while the routing service is
+	// unavailable, so an open circuit breaker only degrades readiness.
+	checks.AddOptionalReadinessCheck("routing", health.CheckerFunc(routing.CheckCircuitBreaker))
+	checks.AddReadinessCheck("inspection", health.CheckerFunc(func(context.Context) error {
+		if n, c := handlingEventHandler.Len(), handlingEventHandler.Cap(); c > 0 && n >= c {
+			return fmt.Errorf("inspection backlog full: %d events queued", n)
+		}
+		return nil
+	}))
+	if session != nil {
+		checks.AddReadinessCheck("mongo", health.CheckerFunc(func(context.Context) error {
+			sess := session.Copy()
+			defer sess.Close()
+			return sess.Ping()
+		}))
+	}
+
+	http.Handle("/", cors.NewHandler(corsPolicy, corsRoutes, mux))
+	http.Handle("/healthz", checks.LiveHandler())
+	http.Handle("/readyz", checks.ReadyHandler())
+	if cfg.Metrics.Enabled {
+		http.Handle(cfg.Metrics.Path, census.Handler(stdprometheus.Handler(), log.NewContext(logger).With("component", "statistics")))
+	}
+
+	srv := &http.Server{
+		Addr:    cfg.HTTP.Addr,
+		Handler: requestid.Handler(tracing.Handler(tracer, accessLog(httpLogger, http.DefaultServeMux))),
+	}
+
+	// End the streams on shutdown, as they would otherwise keep it waiting.
+	srv.RegisterOnShutdown(trackingStream.Close)
+
+	// Serve the same services over gRPC to internal consumers.
+	var grpcSrv *grpc.Server
+	if cfg.GRPC.Addr != "" {
+		grpcLogger := log.NewContext(logger).With("component", "grpc")
+
+		grpcSrv = grpc.NewServer(grpc.UnaryInterceptor(rpc.ChainUnaryServer(
+			requestid.UnaryServerInterceptor,
+			tracing.UnaryServerInterceptor(tracer),
+		)))
 
-	http.Handle("/", accessControl(mux))
-	http.Handle("/metrics", stdprometheus.Handler())
+		bookingpb.RegisterBookingServiceServer(grpcSrv, booking.MakeGRPCServer(ctx, bs, grpcLogger))
+		trackingpb.RegisterTrackingServiceServer(grpcSrv, tracking.MakeGRPCServer(ctx, ts, grpcLogger))
+		handlingpb.RegisterHandlingServiceServer(grpcSrv, handling.MakeGRPCServer(ctx, hs, grpcLogger))
+	}
 
-	errs := make(chan error, 2)
+	errs := make(chan error, 3)
 	go func() {
-		logger.Log("transport", "http", "address", *httpAddr, "msg", "listening")
-		errs <- http.ListenAndServe(*httpAddr, nil)
+		logger.Log("transport", "http", "address", cfg.HTTP.Addr, "msg", "listening")
+		errs <- srv.ListenAndServe()
 	}()
+	if grpcSrv != nil {
+		go func() {
+			ln, err := net.Listen("tcp", cfg.GRPC.Addr)
+			if err != nil {
+				errs <- err
+				return
+			}
+			logger.Log("transport", "grpc", "address", cfg.GRPC.Addr, "msg", "listening")
+			errs <- grpcSrv.Serve(ln)
+		}()
+	}
 	go func() {
 		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
 	logger.Log("terminated", <-errs)
-}
 
-func accessControl(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type")
+	// Stop receiving new traffic before draining in-flight requests and
+	// queued work, and finally release the repositories.
+	checks.SetReady(false)
+	time.Sleep(cfg.HTTP.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
 
-		if r.Method == "OPTIONS" {
-			return
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Log("transport", "http", "msg", "shutdown", "err", err)
+	}
+	if grpcSrv != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+
+		// Cancel the calls still running when the timeout expires.
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcSrv.Stop()
+			logger.Log("transport", "grpc", "msg", "shutdown", "err", shutdownCtx.Err())
 		}
+	}
+	if err := handlingEventHandler.Close(shutdownCtx); err != nil {
+		logger.Log("component", "inspection", "msg", "shutdown", "queued", handlingEventHandler.Len(), "err", err)
+	}
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		logger.Log("component", "tracing", "msg", "shutdown", "err", err)
+	}
+	if session != nil {
+		session.Close()
+	}
 
-		h.ServeHTTP(w, r)
-	})
+	logger.Log("msg", "shutdown complete")
 }
 
-func envString(env, fallback string) string {
-	e := os.Getenv(env)
-	if e == "" {
-		return fallback
+// allowedLevels returns the log levels enabled by the given minimum level.
+func allowedLevels(min string) []string {
+	switch min {
+	case "debug":
+		return level.AllowDebugAndAbove()
+	case "warn":
+		return level.AllowWarnAndAbove()
+	case "error":
+		return level.AllowErrorOnly()
+	default:
+		return level.AllowInfoAndAbove()
 	}
-	return e
 }
 
-func envInt(env string, fallback int) int {
-	e := os.Getenv(env)
-	if e == "" {
-		return fallback
-	}
-	v, err := strconv.Atoi(e)
-	if err != nil {
-		return fallback
-	}
+// seedBatchSize is the number of cargos whose handling histories are queried
+// at a time when seeding the estimator.
+const seedBatchSize = 500
+
+// seedEstimator lets the estimator learn from the handling histories of the
+// existing cargos. Histories are queried in batches, to keep each query
+// small. Batches that fail are logged and left out, since the estimator
+// keeps learning as cargos are handled.
+func seedEstimator(ctx context.Context, e *eta.Estimator, events cargo.HandlingEventRepository, cs []*cargo.Cargo, logger log.Logger) {
+	for len(cs) > 0 {
+		n := len(cs)
+		if n > seedBatchSize {
+			n = seedBatchSize
+		}
+		batch := cs[:n]
+		cs = cs[n:]
 
-	return v
+		ids := make([]cargo.TrackingID, len(batch))
+		for i, c := range batch {
+			ids[i] = c.TrackingID
+		}
+
+		histories, err := events.QueryHandlingHistories(ctx, ids)
+		if err != nil {
+			level.Error(logger).Log("msg", "seeding estimator", "cargos", len(batch), "err", err)
+			continue
+		}
+		e.Seed(batch, histories)
+	}
 }
 
-func storeTestData(r cargo.Repository) {
+func storeTestData(ctx context.Context, r cargo.Repository) {
 	locationsLength := len(location.SAMPLE_LOCATIONS)
 	for i := 0; i < 200; i++ {
 		cargoId := cargo.TrackingID("FTL456" + strconv.Itoa(i))
@@ -247,7 +610,7 @@ func storeTestData(r cargo.Repository) {
 			Destination:     location.SAMPLE_LOCATIONS[rand.Intn(locationsLength)],
 			ArrivalDeadline: time.Now().AddDate(0, 0, 7),
 		})
-		if err := r.Store(test1); err != nil {
+		if err := r.Store(ctx, test1); err != nil {
 			panic(err)
 		}
 	}
@@ -263,3 +626,29 @@ func (l *serializedLogger) Log(keyvals ...interface{}) error {
 	defer l.mtx.Unlock()
 	return l.Logger.Log(keyvals...)
 }
+
+// accessLog logs every request handled by h, together with its request ID.
+// Health checks are logged at debug level to keep probes out of the logs.
+func accessLog(logger log.Logger, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := httpctx.NewStatusWriter(w)
+
+		defer func(begin time.Time) {
+			l := requestid.Logger(r.Context(), logger)
+			switch r.URL.Path {
+			case "/healthz", "/readyz":
+				l = level.Debug(l)
+			default:
+				l = level.Info(l)
+			}
+			l.Log(
+				"method", r.Method,
+				"path", token.RedactPath(r.URL.Path),
+				"status", sw.Code,
+				"took", time.Since(begin),
+			)
+		}(time.Now())
+
+		h.ServeHTTP(sw, r)
+	})
+}
@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/log"
 	. "gopkg.in/check.v1"
 
 	"github.com/marcusolsson/goddd/booking"
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/eta"
 	"github.com/marcusolsson/goddd/handling"
 	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/inspection"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
@@ -21,9 +27,33 @@ type S struct{}
 
 var _ = Suite(&S{})
 
+func (s *S) TestSeedEstimatorInBatches(chk *C) {
+	var cs []*cargo.Cargo
+	for i := 0; i < 2*seedBatchSize+1; i++ {
+		cs = append(cs, cargo.New(cargo.TrackingID("ABC"+strconv.Itoa(i)), cargo.RouteSpecification{}))
+	}
+
+	var batches []int
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoriesFn = func(_ context.Context, ids []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+		batches = append(batches, len(ids))
+		if len(batches) == 1 {
+			return nil, errors.New("no reachable servers")
+		}
+		return nil, nil
+	}
+
+	// A failing batch is logged rather than stopping the seeding.
+	seedEstimator(context.Background(), eta.NewEstimator(nil), &events, cs, log.NewNopLogger())
+
+	chk.Check(batches, DeepEquals, []int{seedBatchSize, seedBatchSize, 1})
+}
+
 func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	var err error
 
+	ctx := context.Background()
+
 	var (
 		cargoRepository         = inmem.NewCargoRepository()
 		locationRepository      = inmem.NewLocationRepository()
@@ -44,8 +74,8 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	handlingEventHandler := &stubHandlingEventHandler{cargoInspectionService}
 
 	var (
-		bookingService       = booking.NewService(cargoRepository, locationRepository, handlingEventRepository, routingService)
-		handlingEventService = handling.NewService(handlingEventRepository, handlingEventFactory, handlingEventHandler)
+		bookingService       = booking.NewService(cargoRepository, locationRepository, handlingEventRepository, routingService, nil, nil)
+		handlingEventService = handling.NewService(handlingEventRepository, handlingEventFactory, handlingEventHandler, handling.DefaultRules())
 	)
 
 	var (
@@ -58,11 +88,11 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Use case 1: booking
 	//
 
-	id, err := bookingService.BookNewCargo(origin, destination, deadline)
+	id, err := bookingService.BookNewCargo(ctx, origin, destination, deadline)
 
 	chk.Assert(err, IsNil)
 
-	c, err := cargoRepository.Find(id)
+	c, err := cargoRepository.Find(ctx, id)
 
 	chk.Assert(err, IsNil)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.NotReceived)
@@ -76,12 +106,13 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Use case 2: routing
 	//
 
-	itineraries := bookingService.RequestPossibleRoutesForCargo(id)
+	itineraries, err := bookingService.RequestPossibleRoutesForCargo(ctx, id)
+	chk.Check(err, IsNil)
 	itinerary := selectPreferredItinerary(itineraries)
 
 	c.AssignToRoute(itinerary)
 
-	cargoRepository.Store(c)
+	cargoRepository.Store(ctx, c)
 
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.NotReceived)
 	chk.Check(c.Delivery.RoutingStatus, Equals, cargo.Routed)
@@ -94,20 +125,20 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Use case 3: handling
 	//
 
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 1), id, "", location.CNHKG, cargo.Receive)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 1), id, "", location.CNHKG, cargo.Receive)
 	chk.Check(err, IsNil)
 
 	// Ensure we're not working with stale cargo.
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.InPort)
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.CNHKG)
 	chk.Check(c.Delivery.Itinerary.IsEmpty(), Equals, false)
 
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 3), id, voyage.V100.Number, location.CNHKG, cargo.Load)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 3), id, voyage.V100.Number, location.CNHKG, cargo.Load)
 	chk.Check(err, IsNil)
 
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.OnboardCarrier)
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.CNHKG)
@@ -124,17 +155,17 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 
 	noSuchVoyageNumber := voyage.Number("XX000")
 	noSuchUNLocode := location.UNLocode("ZZZZZ")
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 5), id, noSuchVoyageNumber, noSuchUNLocode, cargo.Load)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 5), id, noSuchVoyageNumber, noSuchUNLocode, cargo.Load)
 	chk.Check(err, NotNil)
 
 	//
 	// Cargo is incorrectly unloaded in Tokyo
 	//
 
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 5), id, voyage.V100.Number, location.JNTKO, cargo.Unload)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 5), id, voyage.V100.Number, location.JNTKO, cargo.Unload)
 	chk.Check(err, IsNil)
 
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.JNTKO)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.InPort)
@@ -158,18 +189,19 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Specify a new route, this time from Tokyo (where it was incorrectly unloaded) to Stockholm
 	c.SpecifyNewRoute(rs)
 
-	cargoRepository.Store(c)
+	cargoRepository.Store(ctx, c)
 
 	chk.Check(c.Delivery.RoutingStatus, Equals, cargo.Misrouted)
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, cargo.HandlingActivity{})
 
 	// Repeat procedure of selecting one out of a number of possible routes satisfying the route spec
-	newItineraries := bookingService.RequestPossibleRoutesForCargo(id)
+	newItineraries, err := bookingService.RequestPossibleRoutesForCargo(ctx, id)
+	chk.Check(err, IsNil)
 	newItinerary := selectPreferredItinerary(newItineraries)
 
 	c.AssignToRoute(newItinerary)
 
-	cargoRepository.Store(c)
+	cargoRepository.Store(ctx, c)
 
 	chk.Check(c.Delivery.RoutingStatus, Equals, cargo.Routed)
 
@@ -178,10 +210,10 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	//
 
 	// Load in Tokyo
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 8), id, voyage.V300.Number, location.JNTKO, cargo.Load)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 8), id, voyage.V300.Number, location.JNTKO, cargo.Load)
 	chk.Check(err, IsNil)
 
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.JNTKO)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.OnboardCarrier)
@@ -190,10 +222,10 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, cargo.HandlingActivity{Type: cargo.Unload, Location: location.DEHAM, VoyageNumber: voyage.V300.Number})
 
 	// Unload in Hamburg
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 12), id, voyage.V300.Number, location.DEHAM, cargo.Unload)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 12), id, voyage.V300.Number, location.DEHAM, cargo.Unload)
 	chk.Check(err, IsNil)
 
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.DEHAM)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.InPort)
@@ -202,10 +234,10 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, cargo.HandlingActivity{Type: cargo.Load, Location: location.DEHAM, VoyageNumber: voyage.V400.Number})
 
 	// Load in Hamburg
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 14), id, voyage.V400.Number, location.DEHAM, cargo.Load)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 14), id, voyage.V400.Number, location.DEHAM, cargo.Load)
 	chk.Check(err, IsNil)
 
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.DEHAM)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.OnboardCarrier)
@@ -214,10 +246,10 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, cargo.HandlingActivity{Type: cargo.Unload, Location: location.SESTO, VoyageNumber: voyage.V400.Number})
 
 	// Unload in Stockholm
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 15), id, voyage.V400.Number, location.SESTO, cargo.Unload)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 15), id, voyage.V400.Number, location.SESTO, cargo.Unload)
 	chk.Check(err, IsNil)
 
-	c, err = cargoRepository.Find(id)
+	c, err = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.SESTO)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.InPort)
@@ -226,10 +258,10 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, cargo.HandlingActivity{Type: cargo.Claim, Location: location.SESTO})
 
 	// Finally, cargo is claimed in Stockholm. This ends the cargo lifecycle from our perspective.
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 16), id, voyage.V400.Number, location.SESTO, cargo.Claim)
+	err = handlingEventService.RegisterHandlingEvent(ctx, toDate(2009, time.March, 16), id, voyage.V400.Number, location.SESTO, cargo.Claim)
 	chk.Check(err, IsNil)
 
-	c, _ = cargoRepository.Find(id)
+	c, _ = cargoRepository.Find(ctx, id)
 
 	chk.Check(c.Delivery.LastKnownLocation, Equals, location.SESTO)
 	chk.Check(c.Delivery.TransportStatus, Equals, cargo.Claimed)
@@ -249,7 +281,7 @@ func toDate(year int, month time.Month, day int) time.Time {
 // Stub RoutingService
 type stubRoutingService struct{}
 
-func (s *stubRoutingService) FetchRoutesForSpecification(rs cargo.RouteSpecification) []cargo.Itinerary {
+func (s *stubRoutingService) FetchRoutesForSpecification(_ context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
 	if rs.Origin == location.CNHKG {
 		return []cargo.Itinerary{
 			{Legs: []cargo.Leg{
@@ -257,7 +289,7 @@ func (s *stubRoutingService) FetchRoutesForSpecification(rs cargo.RouteSpecifica
 				cargo.NewLeg("V200", location.USNYC, location.USCHI, toDate(2009, time.March, 10), toDate(2009, time.March, 14)),
 				cargo.NewLeg("V300", location.USCHI, location.SESTO, toDate(2009, time.March, 7), toDate(2009, time.March, 11)),
 			}},
-		}
+		}, nil
 	}
 
 	return []cargo.Itinerary{
@@ -265,7 +297,7 @@ func (s *stubRoutingService) FetchRoutesForSpecification(rs cargo.RouteSpecifica
 			cargo.NewLeg("V300", location.JNTKO, location.DEHAM, toDate(2009, time.March, 8), toDate(2009, time.March, 12)),
 			cargo.NewLeg("V400", location.DEHAM, location.SESTO, toDate(2009, time.March, 14), toDate(2009, time.March, 15)),
 		}},
-	}
+	}, nil
 }
 
 // Stub HandlingEventHandler
@@ -273,16 +305,16 @@ type stubHandlingEventHandler struct {
 	InspectionService inspection.Service
 }
 
-func (h *stubHandlingEventHandler) CargoWasHandled(event cargo.HandlingEvent) {
-	h.InspectionService.InspectCargo(event.TrackingID)
+func (h *stubHandlingEventHandler) CargoWasHandled(ctx context.Context, event cargo.HandlingEvent) {
+	h.InspectionService.InspectCargo(ctx, event.TrackingID)
 }
 
 // Stub CargoEventHandler
 type stubCargoEventHandler struct {
 }
 
-func (h *stubCargoEventHandler) CargoWasMisdirected(c *cargo.Cargo) {
+func (h *stubCargoEventHandler) CargoWasMisdirected(_ context.Context, c *cargo.Cargo) {
 }
 
-func (h *stubCargoEventHandler) CargoHasArrived(c *cargo.Cargo) {
+func (h *stubCargoEventHandler) CargoHasArrived(_ context.Context, c *cargo.Cargo) {
 }
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/marcusolsson/goddd/cargo"
 	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/voyage"
@@ -8,105 +10,132 @@ import (
 
 // CargoRepository is a mock cargo repository.
 type CargoRepository struct {
-	StoreFn      func(c *cargo.Cargo) error
+	StoreFn      func(ctx context.Context, c *cargo.Cargo) error
 	StoreInvoked bool
 
-	FindFn      func(id cargo.TrackingID) (*cargo.Cargo, error)
+	FindFn      func(ctx context.Context, id cargo.TrackingID) (*cargo.Cargo, error)
 	FindInvoked bool
 
-	FindAllFn      func() []*cargo.Cargo
+	FindManyFn      func(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error)
+	FindManyInvoked bool
+
+	FindAllFn      func(ctx context.Context) ([]*cargo.Cargo, error)
 	FindAllInvoked bool
 
-	RemoveFn      func(c *cargo.Cargo) error
+	RemoveFn      func(ctx context.Context, c *cargo.Cargo) error
 	RemoveInvoked bool
 }
 
 // Store calls the StoreFn.
-func (r *CargoRepository) Store(c *cargo.Cargo) error {
+func (r *CargoRepository) Store(ctx context.Context, c *cargo.Cargo) error {
 	r.StoreInvoked = true
-	return r.StoreFn(c)
+	return r.StoreFn(ctx, c)
 }
 
 // Find calls the FindFn.
-func (r *CargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
+func (r *CargoRepository) Find(ctx context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 	r.FindInvoked = true
-	return r.FindFn(id)
+	return r.FindFn(ctx, id)
+}
+
+// FindMany calls the FindManyFn.
+func (r *CargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	r.FindManyInvoked = true
+	return r.FindManyFn(ctx, ids)
 }
 
 // FindAll calls the FindAllFn.
-func (r *CargoRepository) FindAll() []*cargo.Cargo {
+func (r *CargoRepository) FindAll(ctx context.Context) ([]*cargo.Cargo, error) {
 	r.FindAllInvoked = true
-	return r.FindAllFn()
+	return r.FindAllFn(ctx)
 }
 
 // Remove calls the RemoveFn.
-func (r *CargoRepository) Remove(c *cargo.Cargo) error {
+func (r *CargoRepository) Remove(ctx context.Context, c *cargo.Cargo) error {
 	r.RemoveInvoked = true
-	return r.RemoveFn(c)
+	return r.RemoveFn(ctx, c)
 }
 
 // LocationRepository is a mock location repository.
 type LocationRepository struct {
-	FindFn      func(location.UNLocode) (*location.Location, error)
+	FindFn      func(context.Context, location.UNLocode) (*location.Location, error)
 	FindInvoked bool
 
-	FindAllFn      func() []*location.Location
+	FindAllFn      func(context.Context) ([]*location.Location, error)
 	FindAllInvoked bool
 }
 
 // Find calls the FindFn.
-func (r *LocationRepository) Find(locode location.UNLocode) (*location.Location, error) {
+func (r *LocationRepository) Find(ctx context.Context, locode location.UNLocode) (*location.Location, error) {
 	r.FindInvoked = true
-	return r.FindFn(locode)
+	return r.FindFn(ctx, locode)
 }
 
 // FindAll calls the FindAllFn.
-func (r *LocationRepository) FindAll() []*location.Location {
+func (r *LocationRepository) FindAll(ctx context.Context) ([]*location.Location, error) {
 	r.FindAllInvoked = true
-	return r.FindAllFn()
+	return r.FindAllFn(ctx)
 }
 
 // VoyageRepository is a mock voyage repository.
 type VoyageRepository struct {
-	FindFn      func(voyage.Number) (*voyage.Voyage, error)
+	FindFn      func(context.Context, voyage.Number) (*voyage.Voyage, error)
 	FindInvoked bool
+
+	FindAllFn      func(context.Context) ([]*voyage.Voyage, error)
+	FindAllInvoked bool
 }
 
 // Find calls the FindFn.
-func (r *VoyageRepository) Find(number voyage.Number) (*voyage.Voyage, error) {
+func (r *VoyageRepository) Find(ctx context.Context, number voyage.Number) (*voyage.Voyage, error) {
 	r.FindInvoked = true
-	return r.FindFn(number)
+	return r.FindFn(ctx, number)
+}
+
+// FindAll calls the FindAllFn.
+func (r *VoyageRepository) FindAll(ctx context.Context) ([]*voyage.Voyage, error) {
+	r.FindAllInvoked = true
+	return r.FindAllFn(ctx)
 }
 
 // HandlingEventRepository is a mock handling events repository.
 type HandlingEventRepository struct {
-	StoreFn      func(cargo.HandlingEvent)
+	StoreFn      func(context.Context, cargo.HandlingEvent) error
 	StoreInvoked bool
 
-	QueryHandlingHistoryFn      func(cargo.TrackingID) cargo.HandlingHistory
+	QueryHandlingHistoryFn      func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error)
 	QueryHandlingHistoryInvoked bool
+
+	QueryHandlingHistoriesFn      func(context.Context, []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error)
+	QueryHandlingHistoriesInvoked bool
 }
 
 // Store calls the StoreFn.
-func (r *HandlingEventRepository) Store(e cargo.HandlingEvent) {
+func (r *HandlingEventRepository) Store(ctx context.Context, e cargo.HandlingEvent) error {
 	r.StoreInvoked = true
-	r.StoreFn(e)
+	return r.StoreFn(ctx, e)
 }
 
 // QueryHandlingHistory calls the QueryHandlingHistoryFn.
-func (r *HandlingEventRepository) QueryHandlingHistory(id cargo.TrackingID) cargo.HandlingHistory {
+func (r *HandlingEventRepository) QueryHandlingHistory(ctx context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
 	r.QueryHandlingHistoryInvoked = true
-	return r.QueryHandlingHistoryFn(id)
+	return r.QueryHandlingHistoryFn(ctx, id)
+}
+
+// QueryHandlingHistories calls the QueryHandlingHistoriesFn.
+func (r *HandlingEventRepository) QueryHandlingHistories(ctx context.Context, ids []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+	r.QueryHandlingHistoriesInvoked = true
+	return r.QueryHandlingHistoriesFn(ctx, ids)
 }
 
 // RoutingService provides a mock routing service.
 type RoutingService struct {
-	FetchRoutesFn      func(cargo.RouteSpecification) []cargo.Itinerary
+	FetchRoutesFn      func(context.Context, cargo.RouteSpecification) ([]cargo.Itinerary, error)
 	FetchRoutesInvoked bool
 }
 
 // FetchRoutesForSpecification calls the FetchRoutesFn.
-func (s *RoutingService) FetchRoutesForSpecification(rs cargo.RouteSpecification) []cargo.Itinerary {
+func (s *RoutingService) FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
 	s.FetchRoutesInvoked = true
-	return s.FetchRoutesFn(rs)
+	return s.FetchRoutesFn(ctx, rs)
 }
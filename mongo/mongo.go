@@ -1,74 +1,93 @@
 package mongo
 
 import (
-	"fmt"
+	"context"
+	"strings"
+	"time"
+
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
-	"os"
-	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/idempotency"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/token"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
+// Garbage is a padding document stored alongside each cargo to simulate
+// larger documents.
 type Garbage struct {
 	Garbage string
 }
 
-var GARBAGE_CARGO Garbage
+// copySession returns a copy of session for a single operation, unless ctx is
+// already done. mgo does not support contexts, so the deadline of ctx is
+// applied as a socket timeout.
+func copySession(ctx context.Context, session *mgo.Session) (*mgo.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sess := session.Copy()
+	if deadline, ok := ctx.Deadline(); ok {
+		sess.SetSocketTimeout(time.Until(deadline))
+	}
 
-func timed(start time.Time, method string) {
-	elapsed := time.Since(start)
-	fmt.Printf("%s took %s\n", method, elapsed)
+	return sess, nil
 }
 
 type cargoRepository struct {
 	db      string
 	session *mgo.Session
+	padding Garbage
 }
 
-func (r *cargoRepository) Remove(cargo *cargo.Cargo) error {
-	start := time.Now()
-	defer timed(start, "Removing a cargo")
-
-	sess := r.session.Copy()
+func (r *cargoRepository) Remove(ctx context.Context, cargo *cargo.Cargo) error {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("cargo")
 
-	err := c.Remove(bson.M{"trackingid": cargo.TrackingID})
+	err = c.Remove(bson.M{"trackingid": cargo.TrackingID})
 	c.Remove(bson.M{"trackingid_g": cargo.TrackingID})
 
 	return err
 }
 
-func (r *cargoRepository) Store(cargo *cargo.Cargo) error {
-	start := time.Now()
-	defer timed(start, "Storing a cargo")
-
-	sess := r.session.Copy()
+func (r *cargoRepository) Store(ctx context.Context, cargo *cargo.Cargo) error {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("cargo")
 
-	_, err := c.Upsert(bson.M{"trackingid": cargo.TrackingID}, bson.M{"$set": cargo})
+	_, err = c.Upsert(bson.M{"trackingid": cargo.TrackingID}, bson.M{"$set": cargo})
 
-	c.Upsert(bson.M{"trackingid_g": cargo.TrackingID}, bson.M{"$set": GARBAGE_CARGO})
+	if r.padding.Garbage != "" {
+		c.Upsert(bson.M{"trackingid_g": cargo.TrackingID}, bson.M{"$set": r.padding})
+	}
 
 	return err
 }
 
-func (r *cargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
-	start := time.Now()
-	defer timed(start, "Finding a single cargo")
-
-	sess := r.session.Copy()
+func (r *cargoRepository) Find(ctx context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("cargo")
 
-	c.Find(bson.M{"trackingid_g": id}).One(&Garbage{})
+	if r.padding.Garbage != "" {
+		c.Find(bson.M{"trackingid_g": id}).One(&Garbage{})
+	}
 
 	var result cargo.Cargo
 	if err := c.Find(bson.M{"trackingid": id}).One(&result); err != nil {
@@ -81,37 +100,52 @@ func (r *cargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
 	return &result, nil
 }
 
-func (r *cargoRepository) FindAll() []*cargo.Cargo {
-	sess := r.session.Copy()
+func (r *cargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
 	defer sess.Close()
-	sess.SetBatch(300)
 
 	c := sess.DB(r.db).C("cargo")
 
+	if r.padding.Garbage != "" {
+		c.Find(bson.M{"trackingid_g": bson.M{"$in": ids}}).All(&[]Garbage{})
+	}
+
 	var result []*cargo.Cargo
-	start := time.Now()
-	defer timed(start, "Find all cargos")
-	if err := c.Find(bson.M{}).All(&result); err != nil {
-		fmt.Println("Found error finding all cargos:" + err.Error())
-		return []*cargo.Cargo{}
+	if err := c.Find(bson.M{"trackingid": bson.M{"$in": ids}}).All(&result); err != nil {
+		return nil, err
 	}
-	fmt.Printf("Found %d cargos\n", len(result))
 
-	return result
+	return result, nil
 }
 
-// NewCargoRepository returns a new instance of a MongoDB cargo repository.
-func NewCargoRepository(db string, session *mgo.Session) (cargo.Repository, error) {
-	if os.Getenv("NO_PADDING") == "" {
-		// Roughly 10kb
-		for i := 0; i < 60*1024; i++ {
-			GARBAGE_CARGO.Garbage += "a"
-		}
+func (r *cargoRepository) FindAll(ctx context.Context) ([]*cargo.Cargo, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close()
+	sess.SetBatch(300)
+
+	c := sess.DB(r.db).C("cargo")
+
+	var result []*cargo.Cargo
+	if err := c.Find(bson.M{}).All(&result); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
+// NewCargoRepository returns a new instance of a MongoDB cargo repository.
+// Each stored cargo is accompanied by a padding document of paddingBytes
+// bytes, unless paddingBytes is zero.
+func NewCargoRepository(db string, session *mgo.Session, paddingBytes int) (cargo.Repository, error) {
 	r := &cargoRepository{
 		db:      db,
 		session: session,
+		padding: Garbage{Garbage: strings.Repeat("a", paddingBytes)},
 	}
 
 	index := mgo.Index{
@@ -139,11 +173,11 @@ type locationRepository struct {
 	session *mgo.Session
 }
 
-func (r *locationRepository) Find(locode location.UNLocode) (*location.Location, error) {
-	start := time.Now()
-	defer timed(start, "Find a location")
-
-	sess := r.session.Copy()
+func (r *locationRepository) Find(ctx context.Context, locode location.UNLocode) (*location.Location, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("location")
@@ -159,27 +193,24 @@ func (r *locationRepository) Find(locode location.UNLocode) (*location.Location,
 	return &result, nil
 }
 
-func (r *locationRepository) FindAll() []*location.Location {
-	start := time.Now()
-	defer timed(start, "Find all locations")
-
-	sess := r.session.Copy()
+func (r *locationRepository) FindAll(ctx context.Context) ([]*location.Location, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("location")
 
 	var result []*location.Location
 	if err := c.Find(bson.M{}).All(&result); err != nil {
-		return []*location.Location{}
+		return nil, err
 	}
 
-	return result
+	return result, nil
 }
 
 func (r *locationRepository) store(l *location.Location) error {
-	start := time.Now()
-	defer timed(start, "Saving a location")
-
 	sess := r.session.Copy()
 	defer sess.Close()
 
@@ -221,6 +252,8 @@ func NewLocationRepository(db string, session *mgo.Session) (location.Repository
 		location.Tokyo,
 		location.Rotterdam,
 		location.Hamburg,
+		location.NewYork,
+		location.Chicago,
 	}
 
 	for _, l := range initial {
@@ -235,11 +268,11 @@ type voyageRepository struct {
 	session *mgo.Session
 }
 
-func (r *voyageRepository) Find(voyageNumber voyage.Number) (*voyage.Voyage, error) {
-	start := time.Now()
-	defer timed(start, "Find a voyage")
-
-	sess := r.session.Copy()
+func (r *voyageRepository) Find(ctx context.Context, voyageNumber voyage.Number) (*voyage.Voyage, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("voyage")
@@ -255,10 +288,24 @@ func (r *voyageRepository) Find(voyageNumber voyage.Number) (*voyage.Voyage, err
 	return &result, nil
 }
 
-func (r *voyageRepository) store(v *voyage.Voyage) error {
-	start := time.Now()
-	defer timed(start, "Storing a voyage")
+func (r *voyageRepository) FindAll(ctx context.Context) ([]*voyage.Voyage, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("voyage")
+
+	var result []*voyage.Voyage
+	if err := c.Find(bson.M{}).All(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
 
+func (r *voyageRepository) store(v *voyage.Voyage) error {
 	sess := r.session.Copy()
 	defer sess.Close()
 
@@ -297,6 +344,8 @@ func NewVoyageRepository(db string, session *mgo.Session) (voyage.Repository, er
 		voyage.V100,
 		voyage.V300,
 		voyage.V400,
+		voyage.R100,
+		voyage.T100,
 		voyage.V0100S,
 		voyage.V0200T,
 		voyage.V0300A,
@@ -316,37 +365,244 @@ type handlingEventRepository struct {
 	session *mgo.Session
 }
 
-func (r *handlingEventRepository) Store(e cargo.HandlingEvent) {
-	start := time.Now()
-	defer timed(start, "Storing a handle event")
-
-	sess := r.session.Copy()
+func (r *handlingEventRepository) Store(ctx context.Context, e cargo.HandlingEvent) error {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("handling_event")
 
-	_ = c.Insert(e)
+	return c.Insert(e)
 }
 
-func (r *handlingEventRepository) QueryHandlingHistory(id cargo.TrackingID) cargo.HandlingHistory {
-	start := time.Now()
-	defer timed(start, "Querying handle history for single cargo")
+func (r *handlingEventRepository) QueryHandlingHistory(ctx context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return cargo.HandlingHistory{}, err
+	}
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_event")
 
-	sess := r.session.Copy()
+	var result []cargo.HandlingEvent
+	if err := c.Find(bson.M{"trackingid": id}).Sort("completiontime", "_id").All(&result); err != nil {
+		return cargo.HandlingHistory{}, err
+	}
+
+	return cargo.HandlingHistory{HandlingEvents: result}, nil
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ctx context.Context, ids []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+	sess, err := copySession(ctx, r.session)
+	if err != nil {
+		return nil, err
+	}
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("handling_event")
 
 	var result []cargo.HandlingEvent
-	_ = c.Find(bson.M{"trackingid": id}).All(&result)
+	if err := c.Find(bson.M{"trackingid": bson.M{"$in": ids}}).Sort("completiontime", "_id").All(&result); err != nil {
+		return nil, err
+	}
+
+	// The events are sorted across cargos, so they remain sorted within the
+	// history of each.
+	histories := make(map[cargo.TrackingID]cargo.HandlingHistory, len(ids))
+	for _, e := range result {
+		h := histories[e.TrackingID]
+		h.HandlingEvents = append(h.HandlingEvents, e)
+		histories[e.TrackingID] = h
+	}
 
-	return cargo.HandlingHistory{HandlingEvents: result}
+	return histories, nil
 }
 
 // NewHandlingEventRepository returns a new instance of a MongoDB handling event repository.
-func NewHandlingEventRepository(db string, session *mgo.Session) cargo.HandlingEventRepository {
-	return &handlingEventRepository{
+func NewHandlingEventRepository(db string, session *mgo.Session) (cargo.HandlingEventRepository, error) {
+	r := &handlingEventRepository{
 		db:      db,
 		session: session,
 	}
+
+	// Histories are queried by tracking ID, ordered by completion time.
+	index := mgo.Index{
+		Key:        []string{"trackingid", "completiontime"},
+		Background: true,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_event")
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type idempotencyStore struct {
+	db      string
+	session *mgo.Session
+}
+
+func (s *idempotencyStore) Add(ctx context.Context, r *idempotency.Record) (*idempotency.Record, error) {
+	sess, err := copySession(ctx, s.session)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("idempotency")
+
+	// Replace an expired record not yet removed by the server. If an
+	// unexpired record exists, the upsert violates the unique index.
+	_, err = c.Upsert(bson.M{"key": r.Key, "expires": bson.M{"$lt": time.Now()}}, r)
+	if err == nil {
+		return nil, nil
+	}
+	if !mgo.IsDup(err) {
+		return nil, err
+	}
+
+	var existing idempotency.Record
+	if err := c.Find(bson.M{"key": r.Key}).One(&existing); err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (s *idempotencyStore) Update(ctx context.Context, r *idempotency.Record) error {
+	sess, err := copySession(ctx, s.session)
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("idempotency")
+
+	return c.Update(bson.M{"key": r.Key}, r)
+}
+
+func (s *idempotencyStore) Remove(ctx context.Context, key string) error {
+	sess, err := copySession(ctx, s.session)
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("idempotency")
+
+	if err := c.Remove(bson.M{"key": key}); err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// NewIdempotencyStore returns a new instance of a MongoDB idempotency store.
+// Expired records are removed by the server.
+func NewIdempotencyStore(db string, session *mgo.Session) (idempotency.Store, error) {
+	s := &idempotencyStore{
+		db:      db,
+		session: session,
+	}
+
+	sess := s.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("idempotency")
+
+	indexes := []mgo.Index{
+		{Key: []string{"key"}, Unique: true, Background: true},
+		{Key: []string{"expires"}, ExpireAfter: time.Second, Background: true},
+	}
+
+	for _, index := range indexes {
+		if err := c.EnsureIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+type revocationStore struct {
+	db      string
+	session *mgo.Session
+}
+
+type revocation struct {
+	TrackingID cargo.TrackingID `bson:"trackingid"`
+	Revoked    time.Time        `bson:"revoked"`
+}
+
+func (s *revocationStore) Revoke(ctx context.Context, id cargo.TrackingID, t time.Time) error {
+	sess, err := copySession(ctx, s.session)
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("tracking_token_revocation")
+
+	// Times are stored in milliseconds. Round up, so that tokens issued
+	// earlier within the same millisecond are revoked too.
+	if r := t.Truncate(time.Millisecond); !r.Equal(t) {
+		t = r.Add(time.Millisecond)
+	}
+
+	// Keep the latest revocation, should revocations race.
+	_, err = c.Upsert(bson.M{"trackingid": id}, bson.M{"$max": bson.M{"revoked": t}})
+	return err
+}
+
+func (s *revocationStore) Revoked(ctx context.Context, id cargo.TrackingID) (time.Time, error) {
+	sess, err := copySession(ctx, s.session)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("tracking_token_revocation")
+
+	var r revocation
+	if err := c.Find(bson.M{"trackingid": id}).One(&r); err != nil {
+		if err == mgo.ErrNotFound {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return r.Revoked, nil
+}
+
+// NewRevocationStore returns a new instance of a MongoDB store of revoked
+// tracking tokens.
+func NewRevocationStore(db string, session *mgo.Session) (token.RevocationStore, error) {
+	s := &revocationStore{
+		db:      db,
+		session: session,
+	}
+
+	sess := s.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(s.db).C("tracking_token_revocation")
+
+	index := mgo.Index{
+		Key:        []string{"trackingid"},
+		Unique:     true,
+		Background: true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
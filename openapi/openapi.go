@@ -0,0 +1,286 @@
+// Package openapi serves OpenAPI 3 specifications and validates requests
+// against them.
+//
+// Only the parts of the specification needed to describe the APIs of this
+// application are supported: path and query parameters, JSON request bodies,
+// and the common JSON Schema keywords.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Spec is a parsed OpenAPI 3 specification.
+type Spec struct {
+	raw    []byte
+	doc    document
+	routes []route
+}
+
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Parameters []parameter `json:"parameters"`
+	Get        *operation  `json:"get"`
+	Put        *operation  `json:"put"`
+	Post       *operation  `json:"post"`
+	Delete     *operation  `json:"delete"`
+	Patch      *operation  `json:"patch"`
+}
+
+func (p pathItem) operations() map[string]*operation {
+	ops := map[string]*operation{
+		"GET":    p.Get,
+		"PUT":    p.Put,
+		"POST":   p.Post,
+		"DELETE": p.Delete,
+		"PATCH":  p.Patch,
+	}
+	for m, op := range ops {
+		if op == nil {
+			delete(ops, m)
+		}
+	}
+	return ops
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Parameters  []parameter  `json:"parameters"`
+	RequestBody *requestBody `json:"requestBody"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON Schema as used by OpenAPI 3.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// route is an operation along with its path template split into segments.
+type route struct {
+	method   string
+	path     string
+	segments []string
+	op       *operation
+	params   []parameter
+}
+
+// match reports whether the path matches the template of the route, and
+// returns the values of the path parameters.
+func (r route) match(path string) (map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+
+	vars := make(map[string]string)
+	for i, s := range r.segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			vars[s[1:len(s)-1]] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+
+	return vars, true
+}
+
+// Parse parses a JSON encoded OpenAPI 3 specification.
+func Parse(b []byte) (*Spec, error) {
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+
+	s := &Spec{raw: b, doc: doc}
+
+	for name, schema := range doc.Components.Schemas {
+		if err := s.compile(schema); err != nil {
+			return nil, fmt.Errorf("components.schemas.%s: %v", name, err)
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method, op := range item.operations() {
+			params := append(append([]parameter(nil), item.Parameters...), op.Parameters...)
+			for _, p := range params {
+				if err := s.compile(p.Schema); err != nil {
+					return nil, fmt.Errorf("%s %s: parameter %s: %v", method, path, p.Name, err)
+				}
+			}
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					if err := s.compile(mt.Schema); err != nil {
+						return nil, fmt.Errorf("%s %s: request body: %v", method, path, err)
+					}
+				}
+			}
+
+			s.routes = append(s.routes, route{
+				method:   method,
+				path:     path,
+				segments: strings.Split(strings.Trim(path, "/"), "/"),
+				op:       op,
+				params:   params,
+			})
+		}
+	}
+
+	// Prefer routes with literal segments over parameters, e.g.
+	// /cargos/search over /cargos/{id}.
+	sort.Slice(s.routes, func(i, j int) bool {
+		pi, pj := strings.Count(s.routes[i].path, "{"), strings.Count(s.routes[j].path, "{")
+		if pi != pj {
+			return pi < pj
+		}
+		return s.routes[i].path < s.routes[j].path
+	})
+
+	return s, nil
+}
+
+// MustParse is like Parse but panics if the specification cannot be parsed.
+// It is intended for specifications embedded in the binary.
+func MustParse(b []byte) *Spec {
+	s, err := Parse(b)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: %v", err))
+	}
+	return s
+}
+
+// compile checks that the references of the schema can be resolved and
+// compiles its patterns.
+func (s *Spec) compile(schema *Schema) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		_, err := s.resolve(schema)
+		return err
+	}
+	if schema.Pattern != "" && schema.pattern == nil {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return err
+		}
+		schema.pattern = re
+	}
+	for name, p := range schema.Properties {
+		if err := s.compile(p); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return s.compile(schema.Items)
+}
+
+// resolve returns the schema referred to by schema, if it is a reference.
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	const prefix = "#/components/schemas/"
+
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	if !strings.HasPrefix(schema.Ref, prefix) {
+		return nil, fmt.Errorf("unsupported reference %q", schema.Ref)
+	}
+
+	target, ok := s.doc.Components.Schemas[strings.TrimPrefix(schema.Ref, prefix)]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %q", schema.Ref)
+	}
+
+	return target, nil
+}
+
+// Route is an operation described by a specification.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Routes returns the operations of the specification, sorted by path and
+// method.
+func (s *Spec) Routes() []Route {
+	var rs []Route
+	for _, r := range s.routes {
+		rs = append(rs, Route{Method: r.method, Path: r.path})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Path != rs[j].Path {
+			return rs[i].Path < rs[j].Path
+		}
+		return rs[i].Method < rs[j].Method
+	})
+	return rs
+}
+
+// find returns the route matching the method and path.
+func (s *Spec) find(method, path string) (route, map[string]string, bool) {
+	for _, r := range s.routes {
+		if r.method != method {
+			continue
+		}
+		if vars, ok := r.match(path); ok {
+			return r, vars, true
+		}
+	}
+	return route{}, nil, false
+}
+
+// ServeHTTP serves the specification as JSON.
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(s.raw)
+}
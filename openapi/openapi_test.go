@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSpec = `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/v1/cargos/{id}": {
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[A-Z0-9]+$"}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1}}
+        ]
+      }
+    },
+    "/v1/cargos/search": {
+      "get": {}
+    },
+    "/v1/cargos": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {"$ref": "#/components/schemas/Booking"}
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Booking": {
+        "type": "object",
+        "required": ["origin", "arrival_deadline"],
+        "additionalProperties": false,
+        "properties": {
+          "origin": {"type": "string", "minLength": 5, "maxLength": 5},
+          "arrival_deadline": {"type": "string", "format": "date-time"},
+          "legs": {"type": "array", "minItems": 1, "items": {"$ref": "#/components/schemas/Leg"}}
+        }
+      },
+      "Leg": {
+        "type": "object",
+        "properties": {
+          "type": {"type": "string", "enum": ["Load", "Unload"]},
+          "count": {"type": "integer"}
+        }
+      }
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Route{
+		{Method: "POST", Path: "/v1/cargos"},
+		{Method: "GET", Path: "/v1/cargos/search"},
+		{Method: "GET", Path: "/v1/cargos/{id}"},
+	}
+
+	if got := s.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("s.Routes() = %v; want = %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"version":   `{"openapi": "2.0"}`,
+		"reference": `{"openapi": "3.0.3", "paths": {"/": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}`,
+		"pattern":   `{"openapi": "3.0.3", "components": {"schemas": {"Bad": {"type": "string", "pattern": "("}}}}`,
+	}
+
+	for name, spec := range tests {
+		if _, err := Parse([]byte(spec)); err == nil {
+			t.Errorf("%s: err = nil; want error", name)
+		}
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	s := MustParse([]byte(testSpec))
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		err    string
+	}{
+		{method: "GET", target: "/v1/cargos/ABC123"},
+		{method: "GET", target: "/v1/cargos/ABC123?limit=10"},
+		{method: "GET", target: "/v1/cargos/search"},
+		{method: "GET", target: "/v1/cargos/abc", err: "path.id: must match ^[A-Z0-9]+$"},
+		{method: "GET", target: "/v1/cargos/ABC123?limit=0", err: "query.limit: must be at least 1"},
+		{method: "GET", target: "/v1/cargos/ABC123?limit=ten", err: "query.limit: must be a number"},
+		{method: "GET", target: "/v1/unspecified"},
+		{method: "DELETE", target: "/v1/cargos/abc"},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SESTO", "arrival_deadline": "2016-03-24T23:00:00Z", "legs": [{"type": "Load", "count": 2}]}`,
+		},
+		{method: "POST", target: "/v1/cargos", err: "body: is required"},
+		{method: "POST", target: "/v1/cargos", body: `{`, err: "body: must be valid JSON"},
+		{method: "POST", target: "/v1/cargos", body: `[]`, err: "body: must be an object"},
+		{method: "POST", target: "/v1/cargos", body: `{"origin": "SESTO"}`, err: "body.arrival_deadline: is required"},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SE", "arrival_deadline": "2016-03-24T23:00:00Z"}`,
+			err:  "body.origin: must be at least 5 characters",
+		},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": 12345, "arrival_deadline": "2016-03-24T23:00:00Z"}`,
+			err:  "body.origin: must be a string",
+		},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SESTO", "arrival_deadline": "tomorrow"}`,
+			err:  "body.arrival_deadline: must be an RFC 3339 date-time",
+		},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SESTO", "arrival_deadline": "2016-03-24T23:00:00Z", "destination": "AUMEL"}`,
+			err:  "body.destination: is not allowed",
+		},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SESTO", "arrival_deadline": "2016-03-24T23:00:00Z", "legs": []}`,
+			err:  "body.legs: must have at least 1 items",
+		},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SESTO", "arrival_deadline": "2016-03-24T23:00:00Z", "legs": [{"type": "Claim"}]}`,
+			err:  "body.legs[0].type: must be one of [Load Unload]",
+		},
+		{
+			method: "POST", target: "/v1/cargos",
+			body: `{"origin": "SESTO", "arrival_deadline": "2016-03-24T23:00:00Z", "legs": [{"count": 1.5}]}`,
+			err:  "body.legs[0].count: must be an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+
+		var got string
+		if err := s.ValidateRequest(req); err != nil {
+			got = err.Error()
+		}
+
+		if got != tt.err {
+			t.Errorf("%s %s %s: err = %q; want = %q", tt.method, tt.target, tt.body, got, tt.err)
+		}
+
+		// The body must still be readable by the handler.
+		if b, _ := ioutil.ReadAll(req.Body); string(b) != tt.body {
+			t.Errorf("%s %s: body = %q; want = %q", tt.method, tt.target, b, tt.body)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := MustParse([]byte(testSpec))
+
+	tests := []struct {
+		body string
+		code int
+		want string
+	}{
+		{
+			body: `{"origin": "SESTO"}`,
+			code: http.StatusUnprocessableEntity,
+			want: `{"error":"invalid request: body.arrival_deadline: is required","fields":[{"field":"body.arrival_deadline","message":"is required"}]}`,
+		},
+		{
+			body: `{"origin": 12345, "arrival_deadline": "2016-03-24T23:00:00Z"}`,
+			code: http.StatusBadRequest,
+			want: `{"error":"invalid request: body.origin: must be a string","fields":[{"field":"body.origin","message":"must be a string"}]}`,
+		},
+		{
+			body: `{"origin": "` + strings.Repeat("A", maxBodySize) + `"}`,
+			code: http.StatusRequestEntityTooLarge,
+			want: `{"error":"invalid request: body: is too large","fields":[{"field":"body","message":"is too large"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		var called bool
+		h := Validate(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/cargos", strings.NewReader(tt.body)))
+
+		if called {
+			t.Errorf("invalid request was passed on")
+		}
+		if rec.Code != tt.code {
+			t.Errorf("rec.Code = %d; want = %d", rec.Code, tt.code)
+		}
+		if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+			t.Errorf("rec.Body = %q; want = %q", got, tt.want)
+		}
+	}
+}
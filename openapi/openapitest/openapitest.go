@@ -0,0 +1,60 @@
+// Package openapitest provides utilities for testing that handlers implement
+// their OpenAPI specifications.
+package openapitest
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/marcusolsson/goddd/openapi"
+)
+
+var methods = []string{"GET", "PUT", "POST", "DELETE", "PATCH"}
+
+var pathParam = regexp.MustCompile(`{[^}]+}`)
+
+// CheckRoutes reports an error for every operation of the specification that
+// the router does not handle, and for every route of the router that is not
+// in the specification. Routes serving the specification itself are ignored.
+func CheckRoutes(t *testing.T, spec *openapi.Spec, router *mux.Router) {
+	t.Helper()
+
+	specified := make(map[openapi.Route]bool)
+
+	for _, r := range spec.Routes() {
+		specified[r] = true
+
+		req := httptest.NewRequest(r.Method, pathParam.ReplaceAllString(r.Path, "x"), nil)
+
+		var m mux.RouteMatch
+		if !router.Match(req, &m) {
+			t.Errorf("%s %s is specified but not registered", r.Method, r.Path)
+			continue
+		}
+		if tpl, _ := m.Route.GetPathTemplate(); tpl != r.Path {
+			t.Errorf("%s %s is handled by the route for %s", r.Method, r.Path, tpl)
+		}
+	}
+
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil || strings.HasSuffix(tpl, "/openapi.json") {
+			return nil
+		}
+
+		req := httptest.NewRequest("GET", pathParam.ReplaceAllString(tpl, "x"), nil)
+
+		for _, method := range methods {
+			req.Method = method
+			if route.Match(req, &mux.RouteMatch{}) && !specified[openapi.Route{Method: method, Path: tpl}] {
+				t.Errorf("%s %s is registered but not specified", method, tpl)
+			}
+		}
+
+		return nil
+	})
+}
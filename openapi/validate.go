@@ -0,0 +1,350 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/marcusolsson/goddd/validation"
+)
+
+// maxBodySize is the largest request body read for validation.
+const maxBodySize = 1 << 20
+
+// ValidationError describes why a request does not match the specification.
+type ValidationError struct {
+	// Field is where in the request the error was found, e.g.
+	// "body.legs[0].from" or "path.id".
+	Field  string
+	Reason string
+
+	// Malformed is set when the request could not be decoded, or holds
+	// values of the wrong type, as opposed to values breaking the
+	// constraints of the specification.
+	Malformed bool
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return e.Field + ": " + e.Reason
+}
+
+// errBodyTooLarge is returned for request bodies larger than maxBodySize.
+var errBodyTooLarge = &ValidationError{Field: "body", Reason: "is too large"}
+
+// Validate returns a handler rejecting requests to the operations of the
+// specification that do not match it. The errors are encoded the same way as
+// by the services: malformed requests are rejected with 400 Bad Request and
+// the others with 422 Unprocessable Entity, listing the invalid fields.
+// Requests for paths or methods not in the specification are passed on to
+// next.
+func Validate(s *Spec, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := s.ValidateRequest(r)
+		if err == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		e := &validation.Error{
+			Malformed: verr.Malformed,
+			Fields:    []validation.FieldError{{Field: verr.Field, Message: verr.Reason}},
+		}
+
+		if verr == errBodyTooLarge {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(e.StatusCode())
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  e.Error(),
+			"fields": e.Fields,
+		})
+	})
+}
+
+// ValidateRequest checks the parameters and body of the request against the
+// operation it is for. The body is restored so that it can be read again.
+func (s *Spec) ValidateRequest(r *http.Request) error {
+	rt, vars, ok := s.find(r.Method, r.URL.Path)
+	if !ok {
+		return nil
+	}
+
+	query := r.URL.Query()
+
+	for _, p := range rt.params {
+		var (
+			value   string
+			present bool
+		)
+		switch p.In {
+		case "path":
+			value, present = vars[p.Name]
+		case "query":
+			value, present = query.Get(p.Name), query.Get(p.Name) != ""
+		case "header":
+			value, present = r.Header.Get(p.Name), r.Header.Get(p.Name) != ""
+		default:
+			continue
+		}
+
+		field := p.In + "." + p.Name
+
+		if !present {
+			if p.Required {
+				return &ValidationError{Field: field, Reason: "is required"}
+			}
+			continue
+		}
+		if err := s.validateParameter(field, p.Schema, value); err != nil {
+			return err
+		}
+	}
+
+	if rt.op.RequestBody == nil {
+		return nil
+	}
+
+	return s.validateBody(r, rt.op.RequestBody)
+}
+
+func (s *Spec) validateParameter(field string, schema *Schema, value string) error {
+	if schema == nil {
+		return nil
+	}
+
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return err
+	}
+
+	var v interface{} = value
+
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return &ValidationError{Field: field, Reason: "must be a number", Malformed: true}
+		}
+		v = json.Number(value)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ValidationError{Field: field, Reason: "must be a boolean", Malformed: true}
+		}
+		v = b
+	}
+
+	return s.validate(field, schema, v)
+}
+
+func (s *Spec) validateBody(r *http.Request, rb *requestBody) error {
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	if len(b) > maxBodySize {
+		return errBodyTooLarge
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		if rb.Required {
+			return &ValidationError{Field: "body", Reason: "is required", Malformed: true}
+		}
+		return nil
+	}
+
+	// The body is validated as JSON regardless of the Content-Type header,
+	// just like the decoders of the services read it.
+	mt, ok := rb.Content["application/json"]
+	if !ok {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return &ValidationError{Field: "body", Reason: "must be valid JSON", Malformed: true}
+	}
+
+	return s.validate("body", mt.Schema, v)
+}
+
+// validate checks a decoded JSON value against the schema.
+func (s *Spec) validate(field string, schema *Schema, v interface{}) error {
+	if schema == nil {
+		return nil
+	}
+
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return err
+	}
+
+	if v == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return &ValidationError{Field: field, Reason: "must not be null"}
+	}
+
+	if len(schema.Enum) > 0 && !contains(schema.Enum, v) {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be one of %v", schema.Enum)}
+	}
+
+	switch schema.Type {
+	case "object":
+		return s.validateObject(field, schema, v)
+	case "array":
+		return s.validateArray(field, schema, v)
+	case "string":
+		return validateString(field, schema, v)
+	case "integer", "number":
+		return validateNumber(field, schema, v)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return &ValidationError{Field: field, Reason: "must be a boolean", Malformed: true}
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) validateObject(field string, schema *Schema, v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be an object", Malformed: true}
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			return &ValidationError{Field: field + "." + name, Reason: "is required"}
+		}
+	}
+
+	// Check the properties in a stable order, so that the same request
+	// always gives the same error.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				return &ValidationError{Field: field + "." + name, Reason: "is not allowed"}
+			}
+			continue
+		}
+		if err := s.validate(field+"."+name, p, obj[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) validateArray(field string, schema *Schema, v interface{}) error {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be an array", Malformed: true}
+	}
+	if schema.MinItems != nil && len(arr) < *schema.MinItems {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must have at least %d items", *schema.MinItems)}
+	}
+	if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must have at most %d items", *schema.MaxItems)}
+	}
+	for i, item := range arr {
+		if err := s.validate(fmt.Sprintf("%s[%d]", field, i), schema.Items, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(field string, schema *Schema, v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be a string", Malformed: true}
+	}
+	if schema.MinLength != nil && len(str) < *schema.MinLength {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at least %d characters", *schema.MinLength)}
+	}
+	if schema.MaxLength != nil && len(str) > *schema.MaxLength {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %d characters", *schema.MaxLength)}
+	}
+	if schema.pattern != nil && !schema.pattern.MatchString(str) {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must match %s", schema.Pattern)}
+	}
+	if schema.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			return &ValidationError{Field: field, Reason: "must be an RFC 3339 date-time"}
+		}
+	}
+	return nil
+}
+
+func validateNumber(field string, schema *Schema, v interface{}) error {
+	n, ok := v.(json.Number)
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be a number", Malformed: true}
+	}
+	if schema.Type == "integer" {
+		if _, err := n.Int64(); err != nil {
+			return &ValidationError{Field: field, Reason: "must be an integer", Malformed: true}
+		}
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return &ValidationError{Field: field, Reason: "must be a number", Malformed: true}
+	}
+	if schema.Minimum != nil && f < *schema.Minimum {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at least %v", *schema.Minimum)}
+	}
+	if schema.Maximum != nil && f > *schema.Maximum {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %v", *schema.Maximum)}
+	}
+	return nil
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil && reflect.DeepEqual(e, f) {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Package requestid assigns and propagates the request IDs used to correlate
+// log lines belonging to the same request.
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pborman/uuid"
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the HTTP header carrying the request ID.
+const Header = "X-Request-ID"
+
+// MetadataKey is the gRPC metadata key carrying the request ID.
+const MetadataKey = "x-request-id"
+
+// maxLength is the maximum length of a request ID accepted from a client.
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a new request ID.
+func New() string {
+	return uuid.New()
+}
+
+// NewContext returns a new context carrying the request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or an empty string if
+// there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Handler returns a handler that propagates the request ID sent by the
+// client, or assigns a new one, before calling next. The request ID is
+// available from the request context and is returned in the response
+// header.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = New()
+			r.Header.Set(Header, id)
+		}
+
+		w.Header().Set(Header, id)
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// UnaryServerInterceptor propagates the request ID sent by the client in the
+// call metadata, or assigns a new one, before calling the handler. The
+// request ID is available from the call context and is returned in the
+// response header.
+func UnaryServerInterceptor(ctx oldcontext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var id string
+	if md, ok := metadata.FromContext(ctx); ok && len(md[MetadataKey]) > 0 {
+		id = md[MetadataKey][0]
+	}
+	if !valid(id) {
+		id = New()
+	}
+
+	// Fails only for contexts not belonging to a call, such as in tests.
+	grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, id))
+
+	return handler(NewContext(ctx, id), req)
+}
+
+// HTTPToContext moves the request ID from the request header to the context.
+// It is intended to be used as a kithttp.ServerBefore function.
+func HTTPToContext(ctx context.Context, r *http.Request) context.Context {
+	if id := r.Header.Get(Header); valid(id) {
+		return NewContext(ctx, id)
+	}
+	return ctx
+}
+
+// ContextToHTTP moves the request ID from the context to the request header.
+// It is intended to be used as a kithttp.ClientBefore function.
+func ContextToHTTP(ctx context.Context, r *http.Request) context.Context {
+	if id := FromContext(ctx); id != "" {
+		r.Header.Set(Header, id)
+	}
+	return ctx
+}
+
+// Logger returns a logger that includes the request ID carried by ctx, if
+// any, in every log line.
+func Logger(ctx context.Context, logger log.Logger) log.Logger {
+	if id := FromContext(ctx); id != "" {
+		return log.NewContext(logger).With("request_id", id)
+	}
+	return logger
+}
+
+// valid reports whether id is acceptable as a request ID. Since IDs are
+// written to logs and response headers, only printable ASCII is allowed.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHandlerPropagatesRequestID(t *testing.T) {
+	var got string
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(Header, "abc-123")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got != "abc-123" {
+		t.Errorf("FromContext() = %q; want = %q", got, "abc-123")
+	}
+	if rec.Header().Get(Header) != "abc-123" {
+		t.Errorf("rec.Header().Get(%q) = %q; want = %q", Header, rec.Header().Get(Header), "abc-123")
+	}
+}
+
+func TestHandlerAssignsRequestID(t *testing.T) {
+	tests := []string{"", strings.Repeat("a", maxLength+1), "abc\n123"}
+
+	for _, id := range tests {
+		var (
+			got    string
+			header string
+		)
+		h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = FromContext(r.Context())
+			header = r.Header.Get(Header)
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(Header, id)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got == "" || got == id {
+			t.Errorf("FromContext() = %q; want new request ID", got)
+		}
+		if header != got {
+			t.Errorf("r.Header.Get(%q) = %q; want = %q", Header, header, got)
+		}
+		if rec.Header().Get(Header) != got {
+			t.Errorf("rec.Header().Get(%q) = %q; want = %q", Header, rec.Header().Get(Header), got)
+		}
+	}
+}
+
+func TestContextToHTTP(t *testing.T) {
+	ctx := NewContext(context.Background(), "abc-123")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	ContextToHTTP(ctx, req)
+
+	if req.Header.Get(Header) != "abc-123" {
+		t.Errorf("req.Header.Get(%q) = %q; want = %q", Header, req.Header.Get(Header), "abc-123")
+	}
+
+	if got := FromContext(HTTPToContext(context.Background(), req)); got != "abc-123" {
+		t.Errorf("FromContext() = %q; want = %q", got, "abc-123")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		md   metadata.MD
+		want string
+	}{
+		{md: metadata.Pairs(MetadataKey, "abc-123"), want: "abc-123"},
+		{md: metadata.Pairs(MetadataKey, "abc\n123")},
+		{md: metadata.MD{}},
+	}
+
+	for _, tt := range tests {
+		var got string
+		handler := func(ctx oldcontext.Context, req interface{}) (interface{}, error) {
+			got = FromContext(ctx)
+			return req, nil
+		}
+
+		ctx := metadata.NewContext(context.Background(), tt.md)
+		if _, err := UnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.want != "" && got != tt.want {
+			t.Errorf("FromContext() = %q; want = %q", got, tt.want)
+		}
+		if tt.want == "" && !valid(got) {
+			t.Errorf("FromContext() = %q; want new request ID", got)
+		}
+	}
+}
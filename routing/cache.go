@@ -0,0 +1,129 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+)
+
+// ErrNotCached is returned by the stale service of a cache holding no routes
+// for a specification.
+var ErrNotCached = errors.New("no cached routes")
+
+type cacheKey struct {
+	origin      location.UNLocode
+	destination location.UNLocode
+	deadline    time.Time
+}
+
+type cacheEntry struct {
+	itineraries []cargo.Itinerary
+	stored      time.Time
+}
+
+// Cache keeps the routes found for route specifications, by origin,
+// destination and arrival deadline.
+type Cache struct {
+	ttl      time.Duration
+	staleTTL time.Duration
+	now      func() time.Time
+
+	mtx     sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+// NewCache returns a cache serving routes for ttl after they were found, and
+// keeping them for staleTTL to serve as a last resort.
+func NewCache(ttl, staleTTL time.Duration) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		staleTTL: staleTTL,
+		now:      time.Now,
+		entries:  make(map[cacheKey]cacheEntry),
+	}
+}
+
+func keyOf(rs cargo.RouteSpecification) cacheKey {
+	return cacheKey{
+		origin:      rs.Origin,
+		destination: rs.Destination,
+		deadline:    rs.ArrivalDeadline.UTC(),
+	}
+}
+
+// Middleware returns a middleware serving routes from the cache while they
+// are fresh, and caching the routes found by the next service otherwise.
+func (c *Cache) Middleware() ServiceMiddleware {
+	return func(next Service) Service {
+		return &cachingService{cache: c, next: next}
+	}
+}
+
+// Stale returns a service serving cached routes however long ago they were
+// found, as long as they are kept. It fails with ErrNotCached otherwise.
+func (c *Cache) Stale() Service {
+	return staleService{c}
+}
+
+func (c *Cache) get(rs cargo.RouteSpecification, maxAge time.Duration) ([]cargo.Itinerary, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[keyOf(rs)]
+	if !ok || c.now().Sub(e.stored) >= maxAge {
+		return nil, false
+	}
+	return e.itineraries, true
+}
+
+func (c *Cache) put(rs cargo.RouteSpecification, itineraries []cargo.Itinerary) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.now()
+
+	// Drop the routes kept too long, so that the cache does not grow with
+	// every specification ever routed.
+	for k, e := range c.entries {
+		if now.Sub(e.stored) >= c.staleTTL {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[keyOf(rs)] = cacheEntry{itineraries: itineraries, stored: now}
+}
+
+type cachingService struct {
+	cache *Cache
+	next  Service
+}
+
+func (s *cachingService) FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+	if itineraries, ok := s.cache.get(rs, s.cache.ttl); ok {
+		return itineraries, nil
+	}
+
+	itineraries, err := s.next.FetchRoutesForSpecification(ctx, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.put(rs, itineraries)
+
+	return itineraries, nil
+}
+
+type staleService struct {
+	cache *Cache
+}
+
+func (s staleService) FetchRoutesForSpecification(_ context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+	if itineraries, ok := s.cache.get(rs, s.cache.staleTTL); ok {
+		return itineraries, nil
+	}
+	return nil, ErrNotCached
+}
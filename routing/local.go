@@ -0,0 +1,147 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// ErrNoRoutes is returned by the local routing service when the voyages it
+// knows of do not connect the origin with the destination in time.
+var ErrNoRoutes = errors.New("no routes over known voyages")
+
+const (
+	// maxMovements bounds the carrier movements of a route found locally.
+	maxMovements = 6
+
+	// maxRoutes bounds the number of routes found locally.
+	maxRoutes = 5
+)
+
+type localService struct {
+	voyages voyage.Repository
+	now     func() time.Time
+}
+
+// NewLocalService returns a routing service finding routes over the
+// schedules of the voyages in the repository, to fall back on when the
+// routing service is unavailable. Routes with the fewest legs are preferred.
+// Movements with times must depart after the cargo has arrived where they
+// depart from, and arrive by the deadline. Movements without times, as in
+// voyages not yet timetabled, cannot be known to arrive by a deadline and are
+// only taken for specifications without one, giving legs without times.
+func NewLocalService(voyages voyage.Repository) Service {
+	return &localService{voyages: voyages, now: time.Now}
+}
+
+// hop is a carrier movement of a route, by index into the schedule of its
+// voyage.
+type hop struct {
+	voyage *voyage.Voyage
+	index  int
+}
+
+func (h hop) movement() voyage.CarrierMovement {
+	return h.voyage.Schedule.CarrierMovements[h.index]
+}
+
+func (s *localService) FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+	voyages, err := s.voyages.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Search the voyages in a stable order, so that the same specification
+	// always gives the same routes.
+	sort.Slice(voyages, func(i, j int) bool {
+		return voyages[i].Number < voyages[j].Number
+	})
+
+	var routes [][]hop
+
+	visited := map[location.UNLocode]bool{rs.Origin: true}
+
+	var search func(at location.UNLocode, ready time.Time, route []hop)
+	search = func(at location.UNLocode, ready time.Time, route []hop) {
+		if at == rs.Destination {
+			routes = append(routes, append([]hop(nil), route...))
+			return
+		}
+		if len(route) == maxMovements {
+			return
+		}
+
+		for _, v := range voyages {
+			for i, m := range v.Schedule.CarrierMovements {
+				if m.DepartureLocation != at || visited[m.ArrivalLocation] {
+					continue
+				}
+
+				next := ready
+				if !m.DepartureTime.IsZero() {
+					if m.DepartureTime.Before(ready) {
+						continue
+					}
+					if !rs.ArrivalDeadline.IsZero() && m.ArrivalTime.After(rs.ArrivalDeadline) {
+						continue
+					}
+					next = m.ArrivalTime
+				} else if !rs.ArrivalDeadline.IsZero() {
+					continue
+				}
+
+				visited[m.ArrivalLocation] = true
+				search(m.ArrivalLocation, next, append(route, hop{voyage: v, index: i}))
+				visited[m.ArrivalLocation] = false
+			}
+		}
+	}
+	search(rs.Origin, s.now(), nil)
+
+	if len(routes) == 0 {
+		return nil, ErrNoRoutes
+	}
+
+	itineraries := make([]cargo.Itinerary, len(routes))
+	for i, r := range routes {
+		itineraries[i] = itineraryOf(r)
+	}
+
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		return len(itineraries[i].Legs) < len(itineraries[j].Legs)
+	})
+	if len(itineraries) > maxRoutes {
+		itineraries = itineraries[:maxRoutes]
+	}
+
+	return itineraries, nil
+}
+
+// itineraryOf returns the itinerary of a route, staying onboard for
+// consecutive movements of the same voyage.
+func itineraryOf(route []hop) cargo.Itinerary {
+	var legs []cargo.Leg
+	for i, h := range route {
+		m := h.movement()
+		if i > 0 && h.voyage == route[i-1].voyage && h.index == route[i-1].index+1 {
+			l := &legs[len(legs)-1]
+			l.UnloadLocation = m.ArrivalLocation
+			l.UnloadTime = m.ArrivalTime
+			continue
+		}
+		legs = append(legs, cargo.Leg{
+			VoyageNumber:   h.voyage.Number,
+			Mode:           h.voyage.Mode,
+			LoadLocation:   m.DepartureLocation,
+			UnloadLocation: m.ArrivalLocation,
+			LoadTime:       m.DepartureTime,
+			UnloadTime:     m.ArrivalTime,
+		})
+	}
+	return cargo.Itinerary{Legs: legs}
+}
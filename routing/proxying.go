@@ -3,9 +3,11 @@ package routing
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
@@ -15,33 +17,105 @@ import (
 
 	"github.com/marcusolsson/goddd/cargo"
 	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/requestid"
+	"github.com/marcusolsson/goddd/tracing"
 	"github.com/marcusolsson/goddd/voyage"
 )
 
+// fetchRoutesCommand is the name of the circuit breaker protecting the
+// routing service.
+const fetchRoutesCommand = "fetch-routes"
+
+// ErrCircuitOpen is returned when the circuit breaker protecting the routing
+// service is open.
+var ErrCircuitOpen = errors.New("routing service circuit breaker is open")
+
+// CheckCircuitBreaker returns ErrCircuitOpen if the circuit breaker
+// protecting the routing service is open.
+func CheckCircuitBreaker(_ context.Context) error {
+	cb, _, err := hystrix.GetCircuit(fetchRoutesCommand)
+	if err != nil {
+		return err
+	}
+	if cb.IsOpen() {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// ProxyConfig configures the proxy to the routing service.
+type ProxyConfig struct {
+	// URL is the base URL of the routing service, and Path the path of its
+	// routes resource relative to it.
+	URL  string
+	Path string
+
+	// Timeout bounds a request for routes, retries included.
+	Timeout time.Duration
+
+	// Retries is the number of times a failed request is retried, waiting
+	// Backoff before the first retry and twice as long before each one
+	// after.
+	Retries int
+	Backoff time.Duration
+
+	// CircuitBreaker configures the circuit breaker protecting the routing
+	// service. Its timeout bounds each attempt.
+	CircuitBreaker hystrix.CommandConfig
+}
+
 type proxyService struct {
-	context.Context
-	FetchRoutesEndpoint endpoint.Endpoint
-	Service
+	fetchRoutes endpoint.Endpoint
+	timeout     time.Duration
+}
+
+// NewProxyService returns a routing service proxying requests to the
+// routing service configured by cfg. Calls are traced by tracer, which may be
+// nil.
+func NewProxyService(cfg ProxyConfig, tracer *tracing.Tracer) Service {
+	var e endpoint.Endpoint
+	e = makeFetchRoutesEndpoint(cfg.URL, cfg.Path, time.Duration(cfg.CircuitBreaker.Timeout)*time.Millisecond)
+	e = tracing.EndpointMiddleware(tracer, "routing.fetch_routes", tracing.KindClient)(e)
+	hystrix.ConfigureCommand(fetchRoutesCommand, cfg.CircuitBreaker)
+	e = circuitbreaker.Hystrix(fetchRoutesCommand)(e)
+	e = retry(cfg.Retries, cfg.Backoff)(e)
+	return &proxyService{fetchRoutes: e, timeout: cfg.Timeout}
 }
 
-func (s proxyService) FetchRoutesForSpecification(rs cargo.RouteSpecification) []cargo.Itinerary {
-	response, err := s.FetchRoutesEndpoint(s.Context, fetchRoutesRequest{
+func (s *proxyService) FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	response, err := s.fetchRoutes(ctx, fetchRoutesRequest{
 		From: string(rs.Origin),
 		To:   string(rs.Destination),
 	})
+	if err == hystrix.ErrCircuitOpen {
+		return nil, ErrCircuitOpen
+	}
 	if err != nil {
-		fmt.Printf("Unable to fetch routes endpoint for rs: %v, error: %s\n", rs, err.Error())
-		return []cargo.Itinerary{}
+		return nil, err
 	}
 
 	resp := response.(fetchRoutesResponse)
 
-	var itineraries []cargo.Itinerary
+	itineraries := []cargo.Itinerary{}
+paths:
 	for _, r := range resp.Paths {
 		var legs []cargo.Leg
 		for _, e := range r.Edges {
+			mode, err := edgeMode(e.Mode)
+			if err != nil {
+				// Skip paths by modes of transport we do not know of,
+				// rather than failing to route at all.
+				continue paths
+			}
 			legs = append(legs, cargo.Leg{
 				VoyageNumber:   voyage.Number(e.Voyage),
+				Mode:           mode,
 				LoadLocation:   location.UNLocode(e.Origin),
 				UnloadLocation: location.UNLocode(e.Destination),
 				LoadTime:       e.Departure,
@@ -52,24 +126,42 @@ func (s proxyService) FetchRoutesForSpecification(rs cargo.RouteSpecification) [
 		itineraries = append(itineraries, cargo.Itinerary{Legs: legs})
 	}
 
-	return itineraries
+	return itineraries, nil
 }
 
-// ServiceMiddleware defines a middleware for a routing service.
-type ServiceMiddleware func(Service) Service
-
-// NewProxyingMiddleware returns a new instance of a proxying middleware.
-func NewProxyingMiddleware(ctx context.Context, proxyURL string) ServiceMiddleware {
-	return func(next Service) Service {
-		var e endpoint.Endpoint
-		e = makeFetchRoutesEndpoint(ctx, proxyURL)
-		hystrix.ConfigureCommand("fetch-routes", hystrix.CommandConfig{
-			Timeout:               2000,
-			MaxConcurrentRequests: 1000,
-			ErrorPercentThreshold: 50,
-		})
-		e = circuitbreaker.Hystrix("fetch-routes")(e)
-		return proxyService{ctx, e, next}
+// edgeMode returns the mode of transport of an edge. Edges without one are by
+// sea, as they were before the routing service knew of other modes.
+func edgeMode(s string) (voyage.Mode, error) {
+	if s == "" {
+		return voyage.Sea, nil
+	}
+	return voyage.ParseMode(s)
+}
+
+// retry returns a middleware retrying failed requests up to retries times,
+// waiting backoff before the first retry and doubling the wait for each one
+// after. Requests for routes are idempotent GETs, and so safe to retry. An
+// open circuit is not retried, as it stays open for its sleep window.
+func retry(retries int, backoff time.Duration) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			wait := backoff
+			for attempt := 0; ; attempt++ {
+				response, err := next(ctx, request)
+				if err == nil || err == hystrix.ErrCircuitOpen || attempt == retries {
+					return response, err
+				}
+
+				t := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil, err
+				case <-t.C:
+				}
+				wait *= 2
+			}
+		}
 	}
 }
 
@@ -84,28 +176,32 @@ type fetchRoutesResponse struct {
 			Origin      string    `json:"origin"`
 			Destination string    `json:"destination"`
 			Voyage      string    `json:"voyage"`
+			Mode        string    `json:"mode"`
 			Departure   time.Time `json:"departure"`
 			Arrival     time.Time `json:"arrival"`
 		} `json:"edges"`
 	} `json:"paths"`
 }
 
-func makeFetchRoutesEndpoint(ctx context.Context, instance string) endpoint.Endpoint {
+func makeFetchRoutesEndpoint(instance, path string, timeout time.Duration) endpoint.Endpoint {
 	u, err := url.Parse(instance)
 	if err != nil {
 		panic(err)
 	}
-	if u.Path == "" {
-		u.Path = "/paths"
-	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
 	return kithttp.NewClient(
 		"GET", u,
 		encodeFetchRoutesRequest,
 		decodeFetchRoutesResponse,
+		kithttp.SetClient(&http.Client{Timeout: timeout}),
+		kithttp.ClientBefore(requestid.ContextToHTTP, tracing.ContextToHTTP),
 	).Endpoint()
 }
 
 func decodeFetchRoutesResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("routing service responded %s", resp.Status)
+	}
 	var response fetchRoutesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, err
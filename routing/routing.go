@@ -1,13 +1,74 @@
 // Package routing provides the routing domain service. It does not actually
 // implement the routing service but merely acts as a proxy for a separate
-// bounded context.
+// bounded context, falling back on routing over the schedules of known
+// voyages, and on routes found earlier, when it fails.
 package routing
 
-import "github.com/marcusolsson/goddd/cargo"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/requestid"
+)
+
+// ErrUnavailable is returned, wrapped with the reasons, when no routes could
+// be found because the routing service and every fallback failed.
+var ErrUnavailable = errors.New("routing service unavailable")
 
 // Service provides access to an external routing service.
 type Service interface {
 	// FetchRoutesForSpecification finds all possible routes that satisfy a
 	// given specification.
-	FetchRoutesForSpecification(rs cargo.RouteSpecification) []cargo.Itinerary
+	FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error)
+}
+
+// ServiceMiddleware defines a middleware for a routing service.
+type ServiceMiddleware func(Service) Service
+
+// Fallback is a routing service tried when those before it fail.
+type Fallback struct {
+	// Name identifies the service in logs and errors.
+	Name    string
+	Service Service
+}
+
+type fallbackService struct {
+	fallbacks []Fallback
+	logger    log.Logger
+}
+
+// NewFallbackService returns a routing service trying each of the fallbacks
+// in turn, returning the routes of the first one that does not fail. If they
+// all fail, the error wraps ErrUnavailable. Failures are logged to logger.
+func NewFallbackService(logger log.Logger, fallbacks ...Fallback) Service {
+	return &fallbackService{fallbacks: fallbacks, logger: logger}
+}
+
+func (s *fallbackService) FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+	var reasons []string
+	for _, f := range s.fallbacks {
+		itineraries, err := f.Service.FetchRoutesForSpecification(ctx, rs)
+		if err == nil {
+			return itineraries, nil
+		}
+		level.Warn(requestid.Logger(ctx, s.logger)).Log(
+			"method", "fetch_routes",
+			"backend", f.Name,
+			"origin", rs.Origin,
+			"destination", rs.Destination,
+			"err", err,
+		)
+		reasons = append(reasons, fmt.Sprintf("%s: %v", f.Name, err))
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("%w (%s)", ErrUnavailable, strings.Join(reasons, "; "))
 }
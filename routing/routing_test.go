@@ -0,0 +1,332 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type serviceFunc func(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error)
+
+func (f serviceFunc) FetchRoutesForSpecification(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+	return f(ctx, rs)
+}
+
+func routesVia(v voyage.Number) serviceFunc {
+	return func(_ context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+		return []cargo.Itinerary{{Legs: []cargo.Leg{{VoyageNumber: v, LoadLocation: rs.Origin, UnloadLocation: rs.Destination}}}}, nil
+	}
+}
+
+func voyages(vs ...*voyage.Voyage) voyage.Repository {
+	var r mock.VoyageRepository
+	r.FindAllFn = func(context.Context) ([]*voyage.Voyage, error) {
+		return vs, nil
+	}
+	return &r
+}
+
+func failing(err error) serviceFunc {
+	return func(context.Context, cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+		return nil, err
+	}
+}
+
+var spec = cargo.RouteSpecification{
+	Origin:          location.SESTO,
+	Destination:     location.AUMEL,
+	ArrivalDeadline: time.Date(2009, time.March, 10, 0, 0, 0, 0, time.UTC),
+}
+
+func TestFallbackService(t *testing.T) {
+	s := NewFallbackService(log.NewNopLogger(),
+		Fallback{Name: "first", Service: failing(errors.New("down"))},
+		Fallback{Name: "second", Service: routesVia("V200")},
+		Fallback{Name: "third", Service: routesVia("V300")},
+	)
+
+	got, err := s.FetchRoutesForSpecification(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Legs[0].VoyageNumber != "V200" {
+		t.Errorf("got = %v; want routes via V200", got)
+	}
+}
+
+func TestFallbackServiceUnavailable(t *testing.T) {
+	s := NewFallbackService(log.NewNopLogger(),
+		Fallback{Name: "first", Service: failing(errors.New("down"))},
+		Fallback{Name: "second", Service: failing(ErrNotCached)},
+	)
+
+	_, err := s.FetchRoutesForSpecification(context.Background(), spec)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("err = %v; want = %v", err, ErrUnavailable)
+	}
+	if want := "routing service unavailable (first: down; second: no cached routes)"; err.Error() != want {
+		t.Errorf("err = %q; want = %q", err, want)
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2009, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewCache(time.Minute, time.Hour)
+	c.now = func() time.Time { return now }
+
+	var calls int
+	var next Service = serviceFunc(func(ctx context.Context, rs cargo.RouteSpecification) ([]cargo.Itinerary, error) {
+		calls++
+		return routesVia("V100")(ctx, rs)
+	})
+	s := c.Middleware()(next)
+
+	if _, err := c.Stale().FetchRoutesForSpecification(context.Background(), spec); err != ErrNotCached {
+		t.Errorf("err = %v; want = %v", err, ErrNotCached)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.FetchRoutesForSpecification(context.Background(), spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; want = %d", calls, 1)
+	}
+
+	// The same deadline in another time zone is the same specification.
+	other := spec
+	other.ArrivalDeadline = spec.ArrivalDeadline.In(time.FixedZone("CET", 3600))
+	if _, err := s.FetchRoutesForSpecification(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; want = %d", calls, 1)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if _, err := s.FetchRoutesForSpecification(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want = %d", calls, 2)
+	}
+
+	now = now.Add(30 * time.Minute)
+
+	got, err := c.Stale().FetchRoutesForSpecification(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 1)
+	}
+
+	now = now.Add(time.Hour)
+
+	if _, err := c.Stale().FetchRoutesForSpecification(context.Background(), spec); err != ErrNotCached {
+		t.Errorf("err = %v; want = %v", err, ErrNotCached)
+	}
+}
+
+func TestCacheDoesNotStoreFailures(t *testing.T) {
+	c := NewCache(time.Minute, time.Hour)
+	s := c.Middleware()(failing(errors.New("down")))
+
+	if _, err := s.FetchRoutesForSpecification(context.Background(), spec); err == nil {
+		t.Errorf("err = nil; want error")
+	}
+	if _, err := c.Stale().FetchRoutesForSpecification(context.Background(), spec); err != ErrNotCached {
+		t.Errorf("err = %v; want = %v", err, ErrNotCached)
+	}
+}
+
+func TestLocalService(t *testing.T) {
+	s := NewLocalService(voyages(voyage.R100, voyage.V400, voyage.V300, voyage.V100))
+
+	got, err := s.FetchRoutesForSpecification(context.Background(), cargo.RouteSpecification{
+		Origin:      location.CNHKG,
+		Destination: location.USCHI,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := cargo.Itinerary{Legs: []cargo.Leg{
+		{VoyageNumber: "V100", Mode: voyage.Sea, LoadLocation: location.CNHKG, UnloadLocation: location.USNYC},
+		{VoyageNumber: "R100", Mode: voyage.Rail, LoadLocation: location.USNYC, UnloadLocation: location.USCHI},
+	}}
+	if len(got) == 0 || fmt.Sprint(got[0]) != fmt.Sprint(want) {
+		t.Errorf("got = %v; want first = %v", got, want)
+	}
+
+	for _, it := range got {
+		if o, d := it.InitialDepartureLocation(), it.FinalArrivalLocation(); o != location.CNHKG || d != location.USCHI {
+			t.Errorf("itinerary from %s to %s; want from %s to %s", o, d, location.CNHKG, location.USCHI)
+		}
+	}
+}
+
+func TestLocalServiceDeadline(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2009, time.March, d, 0, 0, 0, 0, time.UTC) }
+
+	v := voyage.New("V500", voyage.Schedule{CarrierMovements: []voyage.CarrierMovement{
+		{DepartureLocation: location.SESTO, ArrivalLocation: location.FIHEL, DepartureTime: day(2), ArrivalTime: day(3)},
+		{DepartureLocation: location.FIHEL, ArrivalLocation: location.DEHAM, DepartureTime: day(4), ArrivalTime: day(6)},
+	}})
+
+	// An untimed voyage straight to Hamburg cannot be known to make any
+	// deadline.
+	untimed := voyage.New("V600", voyage.Schedule{CarrierMovements: []voyage.CarrierMovement{
+		{DepartureLocation: location.SESTO, ArrivalLocation: location.DEHAM},
+	}})
+
+	s := &localService{
+		voyages: voyages(untimed, v),
+		now:     func() time.Time { return day(1) },
+	}
+
+	got, err := s.FetchRoutesForSpecification(context.Background(), cargo.RouteSpecification{
+		Origin:          location.SESTO,
+		Destination:     location.DEHAM,
+		ArrivalDeadline: day(7),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].Legs) != 1 {
+		t.Fatalf("got = %v; want a single leg staying onboard V500", got)
+	}
+	if l := got[0].Legs[0]; !l.LoadTime.Equal(day(2)) || !l.UnloadTime.Equal(day(6)) {
+		t.Errorf("leg = %v; want from %v to %v", l, day(2), day(6))
+	}
+
+	_, err = s.FetchRoutesForSpecification(context.Background(), cargo.RouteSpecification{
+		Origin:          location.SESTO,
+		Destination:     location.DEHAM,
+		ArrivalDeadline: day(5),
+	})
+	if err != ErrNoRoutes {
+		t.Errorf("err = %v; want = %v", err, ErrNoRoutes)
+	}
+
+	s.now = func() time.Time { return day(3) }
+
+	got, err = s.FetchRoutesForSpecification(context.Background(), cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.DEHAM,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Legs[0].VoyageNumber != "V600" {
+		t.Errorf("got = %v; want a single route via V600", got)
+	}
+}
+
+func TestLocalServiceRepositoryError(t *testing.T) {
+	down := errors.New("down")
+
+	var r mock.VoyageRepository
+	r.FindAllFn = func(context.Context) ([]*voyage.Voyage, error) {
+		return nil, down
+	}
+
+	if _, err := NewLocalService(&r).FetchRoutesForSpecification(context.Background(), spec); err != down {
+		t.Errorf("err = %v; want = %v", err, down)
+	}
+}
+
+func TestProxyServiceRetries(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/paths" {
+			t.Errorf("path = %q; want = %q", r.URL.Path, "/api/paths")
+		}
+		if from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to"); from != "SESTO" || to != "AUMEL" {
+			t.Errorf("from, to = %s, %s; want = SESTO, AUMEL", from, to)
+		}
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"paths":[{"edges":[
+			{"origin":"SESTO","destination":"DEHAM","voyage":"V400"},
+			{"origin":"DEHAM","destination":"AUMEL","voyage":"V300","mode":"sea"}
+		]},{"edges":[
+			{"origin":"SESTO","destination":"AUMEL","voyage":"Z100","mode":"zeppelin"}
+		]}]}`)
+	}))
+	defer srv.Close()
+
+	s := NewProxyService(ProxyConfig{
+		URL:     srv.URL + "/api/",
+		Path:    "/paths",
+		Timeout: 5 * time.Second,
+		Retries: 2,
+		Backoff: time.Millisecond,
+		CircuitBreaker: hystrix.CommandConfig{
+			Timeout:                1000,
+			RequestVolumeThreshold: 100,
+		},
+	}, nil)
+
+	got, err := s.FetchRoutesForSpecification(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("requests = %d; want = %d", n, 2)
+	}
+
+	// The path by zeppelin is skipped.
+	if len(got) != 1 || len(got[0].Legs) != 2 {
+		t.Fatalf("got = %v; want a single itinerary of two legs", got)
+	}
+	if m := got[0].Legs[0].Mode; m != voyage.Sea {
+		t.Errorf("mode = %v; want = %v", m, voyage.Sea)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var attempts int
+	e := retry(2, time.Millisecond)(func(context.Context, interface{}) (interface{}, error) {
+		attempts++
+		return nil, errors.New("down")
+	})
+
+	if _, err := e(context.Background(), nil); err == nil {
+		t.Errorf("err = nil; want error")
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d; want = %d", attempts, 3)
+	}
+
+	attempts = 0
+	e = retry(2, time.Millisecond)(func(context.Context, interface{}) (interface{}, error) {
+		attempts++
+		return nil, hystrix.ErrCircuitOpen
+	})
+
+	if _, err := e(context.Background(), nil); err != hystrix.ErrCircuitOpen {
+		t.Errorf("err = %v; want = %v", err, hystrix.ErrCircuitOpen)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d; want = %d", attempts, 1)
+	}
+}
@@ -0,0 +1,87 @@
+// Package rpc provides helpers for serving go-kit endpoints over gRPC.
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/marcusolsson/goddd/httpctx"
+)
+
+// NewServer constructs a go-kit gRPC server for the endpoint. Unlike a
+// server constructed by kitgrpc.NewServer alone, which calls the endpoint
+// with ctx, the endpoint is called with a context that is canceled along
+// with the call, and that carries the values of both ctx and the call
+// context, so that deadlines, request IDs and spans reach the services.
+func NewServer(
+	ctx context.Context,
+	e endpoint.Endpoint,
+	dec kitgrpc.DecodeRequestFunc,
+	enc kitgrpc.EncodeResponseFunc,
+	options ...kitgrpc.ServerOption,
+) *kitgrpc.Server {
+	return kitgrpc.NewServer(
+		ctx,
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			c := request.(call)
+			return e(httpctx.WithValues(c.ctx, ctx), c.request)
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			request, err := dec(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return call{ctx: ctx, request: request}, nil
+		},
+		enc,
+		options...,
+	)
+}
+
+// call carries the context of a call from the decoder, which is passed the
+// call context, to the endpoint.
+type call struct {
+	ctx     context.Context
+	request interface{}
+}
+
+// ChainUnaryServer returns an interceptor calling each of the interceptors
+// in turn, the first one being the outermost. It allows for more than one
+// interceptor per server.
+func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx oldcontext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			handler = bind(interceptors[i], info, handler)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// bind returns a handler calling the interceptor with next.
+func bind(interceptor grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) grpc.UnaryHandler {
+	return func(ctx oldcontext.Context, req interface{}) (interface{}, error) {
+		return interceptor(ctx, req, info, next)
+	}
+}
+
+// Timestamp returns t as a protobuf timestamp, or nil if t is the zero time.
+func Timestamp(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// Time returns ts as a time in UTC, or the zero time if ts is nil.
+func Time(ts *timestamp.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type key string
+
+func TestNewServer(t *testing.T) {
+	base := context.WithValue(context.Background(), key("base"), "a")
+
+	var (
+		values []interface{}
+		err    error
+	)
+	s := NewServer(
+		base,
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			values = []interface{}{ctx.Value(key("base")), ctx.Value(key("call")), request}
+			<-ctx.Done()
+			err = ctx.Err()
+			return request, nil
+		},
+		func(_ context.Context, req interface{}) (interface{}, error) { return req, nil },
+		func(_ context.Context, response interface{}) (interface{}, error) { return response, nil },
+	)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key("call"), "b"))
+	cancel()
+
+	if _, resp, err := s.ServeGRPC(ctx, "req"); err != nil || resp != "req" {
+		t.Fatalf("ServeGRPC() = %v, %v; want = %v, %v", resp, err, "req", nil)
+	}
+
+	if want := []interface{}{"a", "b", "req"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v; want = %v", values, want)
+	}
+	if err != context.Canceled {
+		t.Errorf("ctx.Err() = %v; want = %v", err, context.Canceled)
+	}
+}
+
+func TestChainUnaryServer(t *testing.T) {
+	var calls []string
+
+	intercept := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx oldcontext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+" "+info.FullMethod)
+			return handler(ctx, req)
+		}
+	}
+
+	chain := ChainUnaryServer(intercept("first"), intercept("second"))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	resp, err := chain(context.Background(), "req", info, func(_ oldcontext.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	})
+	if err != nil || resp != "req" {
+		t.Fatalf("chain() = %v, %v; want = %v, %v", resp, err, "req", nil)
+	}
+
+	want := []string{"first /test.Service/Method", "second /test.Service/Method", "handler"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v; want = %v", calls, want)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	want := time.Date(2030, time.November, 10, 23, 0, 0, 5, time.UTC)
+
+	if got := Time(Timestamp(want)); !got.Equal(want) {
+		t.Errorf("Time(Timestamp(%v)) = %v; want = %v", want, got, want)
+	}
+	if ts := Timestamp(time.Time{}); ts != nil {
+		t.Errorf("Timestamp(time.Time{}) = %v; want = nil", ts)
+	}
+	if got := Time(nil); !got.IsZero() {
+		t.Errorf("Time(nil) = %v; want zero time", got)
+	}
+}
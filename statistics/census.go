@@ -0,0 +1,121 @@
+package statistics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+// Gauges are the business metrics describing the cargos currently stored.
+type Gauges struct {
+	// Cargos is the number of cargos, labeled by routing_status and
+	// transport_status.
+	Cargos metrics.Gauge
+
+	// MisdirectedCargos is the number of misdirected cargos.
+	MisdirectedCargos metrics.Gauge
+
+	// CargosPerLocation is the number of cargos by last known location,
+	// labeled by location.
+	CargosPerLocation metrics.Gauge
+}
+
+// status is the label values of a cargo in the Cargos gauge.
+type status struct {
+	routing   string
+	transport string
+}
+
+// Census sets the gauges from the cargos in a repository. Since the gauges
+// are counted from the repository rather than from the cargos stored by this
+// process, every instance sharing the repository reports the same values,
+// however long it has been running.
+type Census struct {
+	gauges Gauges
+	cargos cargo.Repository
+
+	mtx       sync.Mutex
+	statuses  map[status]bool
+	locations map[string]bool
+}
+
+// NewCensus returns a new census setting g from the cargos in r.
+func NewCensus(g Gauges, r cargo.Repository) *Census {
+	return &Census{
+		gauges:    g,
+		cargos:    r,
+		statuses:  make(map[status]bool),
+		locations: make(map[string]bool),
+	}
+}
+
+// Count sets the gauges from the cargos currently stored. Label values
+// counted before that no cargo has any longer are set to zero.
+func (c *Census) Count(ctx context.Context) error {
+	cs, err := c.cargos.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		statuses    = make(map[status]float64)
+		locations   = make(map[string]float64)
+		misdirected float64
+	)
+
+	for _, cg := range cs {
+		statuses[status{label(cg.Delivery.RoutingStatus), label(cg.Delivery.TransportStatus)}]++
+		if cg.Delivery.IsMisdirected {
+			misdirected++
+		}
+		if l := cg.Delivery.LastKnownLocation; l != "" {
+			locations[string(l)]++
+		}
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for s := range c.statuses {
+		if _, ok := statuses[s]; !ok {
+			c.gauges.Cargos.With("routing_status", s.routing, "transport_status", s.transport).Set(0)
+		}
+	}
+	for l := range c.locations {
+		if _, ok := locations[l]; !ok {
+			c.gauges.CargosPerLocation.With("location", l).Set(0)
+		}
+	}
+
+	c.statuses = make(map[status]bool)
+	for s, n := range statuses {
+		c.gauges.Cargos.With("routing_status", s.routing, "transport_status", s.transport).Set(n)
+		c.statuses[s] = true
+	}
+	c.locations = make(map[string]bool)
+	for l, n := range locations {
+		c.gauges.CargosPerLocation.With("location", l).Set(n)
+		c.locations[l] = true
+	}
+	c.gauges.MisdirectedCargos.Set(misdirected)
+
+	return nil
+}
+
+// Handler returns a handler counting the cargos before serving next, e.g.
+// before the metrics are scraped. If the cargos cannot be counted, the error
+// is logged and the gauges keep their previous values.
+func (c *Census) Handler(next http.Handler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := c.Count(r.Context()); err != nil {
+			level.Error(logger).Log("msg", "counting cargos", "err", err)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,34 @@
+package statistics
+
+import (
+	"context"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+type cargoRepository struct {
+	recorder *Recorder
+	cargo.Repository
+}
+
+// NewCargoRepository returns a cargo repository telling the recorder about
+// every cargo stored in or removed from r.
+func NewCargoRepository(rec *Recorder, r cargo.Repository) cargo.Repository {
+	return &cargoRepository{rec, r}
+}
+
+func (r *cargoRepository) Store(ctx context.Context, c *cargo.Cargo) error {
+	if err := r.Repository.Store(ctx, c); err != nil {
+		return err
+	}
+	r.recorder.CargoWasStored(c)
+	return nil
+}
+
+func (r *cargoRepository) Remove(ctx context.Context, c *cargo.Cargo) error {
+	if err := r.Repository.Remove(ctx, c); err != nil {
+		return err
+	}
+	r.recorder.CargoWasRemoved(c.TrackingID)
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package statistics keeps business metrics about cargos and their handling.
+// Metrics about handling are recorded as the domain changes, while gauges of
+// the cargos stored are counted from the repository when they are scraped.
+package statistics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+)
+
+// Metrics are the business metrics kept by a Recorder. They are updated as
+// this process handles cargos, so each instance reports only the events it
+// has observed since it started. Sum them across instances to get the
+// totals.
+type Metrics struct {
+	// HandlingEvents counts handling events, labeled by type and location.
+	HandlingEvents metrics.Counter
+
+	// Deliveries counts cargos unloaded at their destination, labeled by
+	// outcome, which is either on_time or late.
+	Deliveries metrics.Counter
+
+	// DwellTime observes the time in seconds between a cargo being unloaded
+	// in a port and being loaded, gated out or claimed there, labeled by
+	// location.
+	DwellTime metrics.Histogram
+}
+
+// unloading is when and where a cargo was last unloaded.
+type unloading struct {
+	location location.UNLocode
+	time     time.Time
+}
+
+// Recorder updates the metrics from domain events. It remembers which cargos
+// have arrived, so that each delivery is counted once.
+//
+// Recorder implements both handling.EventHandler and
+// inspection.EventHandler. Dwell times and delivery outcomes are based on the
+// completion times of the handling events, or on when the events are
+// observed for events without one.
+type Recorder struct {
+	metrics Metrics
+	now     func() time.Time
+
+	mtx     sync.Mutex
+	arrived map[cargo.TrackingID]bool
+	unloads map[cargo.TrackingID]unloading
+}
+
+// NewRecorder returns a new recorder updating m.
+func NewRecorder(m Metrics) *Recorder {
+	return &Recorder{
+		metrics: m,
+		now:     time.Now,
+		arrived: make(map[cargo.TrackingID]bool),
+		unloads: make(map[cargo.TrackingID]unloading),
+	}
+}
+
+// Seed records the existing cargos, e.g. those already stored when the
+// application starts.
+func (r *Recorder) Seed(cs []*cargo.Cargo) {
+	for _, c := range cs {
+		r.CargoWasStored(c)
+	}
+}
+
+// CargoWasStored records whether the cargo has arrived.
+func (r *Recorder) CargoWasStored(c *cargo.Cargo) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.arrived[c.TrackingID] = c.Delivery.IsUnloadedAtDestination
+}
+
+// CargoWasRemoved forgets the cargo.
+func (r *Recorder) CargoWasRemoved(id cargo.TrackingID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	delete(r.arrived, id)
+	delete(r.unloads, id)
+}
+
+// CargoWasHandled counts the handling event and observes the dwell time of
+// cargos leaving a port.
+func (r *Recorder) CargoWasHandled(ctx context.Context, e cargo.HandlingEvent) {
+	loc := e.Activity.Location
+
+	r.metrics.HandlingEvents.With("type", label(e.Activity.Type), "location", string(loc)).Add(1)
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.completed(e)
+
+	// Cargos are unloaded into port off a voyage, and leave it onto another
+	// voyage or to the consignee.
+	s, _ := e.Activity.Type.Spec()
+
+	switch {
+	case s.RequiresVoyage && s.TransportStatus == cargo.InPort:
+		r.unloads[e.TrackingID] = unloading{location: loc, time: now}
+	case s.TransportStatus == cargo.OnboardCarrier || s.TransportStatus == cargo.Claimed:
+		u, ok := r.unloads[e.TrackingID]
+		if !ok {
+			return
+		}
+		delete(r.unloads, e.TrackingID)
+		// Events registered out of order may make the dwell time negative.
+		if d := now.Sub(u.time); u.location == loc && d >= 0 {
+			r.metrics.DwellTime.With("location", string(loc)).Observe(d.Seconds())
+		}
+	}
+}
+
+// CargoWasMisdirected does nothing, since misdirected cargos are counted by
+// the Census.
+func (r *Recorder) CargoWasMisdirected(ctx context.Context, c *cargo.Cargo) {}
+
+// CargoHasArrived counts the delivery as on time or late with regard to the
+// arrival deadline of the cargo. Inspections of a cargo that had already
+// arrived are not counted again.
+func (r *Recorder) CargoHasArrived(ctx context.Context, c *cargo.Cargo) {
+	r.mtx.Lock()
+	arrived := r.arrived[c.TrackingID]
+	now := r.completed(c.Delivery.LastEvent)
+	r.mtx.Unlock()
+
+	if arrived {
+		return
+	}
+
+	outcome := "on_time"
+	if now.After(c.RouteSpecification.ArrivalDeadline) {
+		outcome = "late"
+	}
+
+	r.metrics.Deliveries.With("outcome", outcome).Add(1)
+}
+
+// completed returns the completion time of the event, if known, or else
+// the current time.
+func (r *Recorder) completed(e cargo.HandlingEvent) time.Time {
+	if e.CompletionTime.IsZero() {
+		return r.now()
+	}
+	return e.CompletionTime
+}
+
+// label returns a label value for the status, e.g. "in_port".
+func label(s fmt.Stringer) string {
+	return strings.Replace(strings.ToLower(s.String()), " ", "_", -1)
+}
@@ -0,0 +1,256 @@
+package statistics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+// metric keeps the sum and count of the values added to or observed by a
+// metric, by label values.
+type metric struct {
+	mtx    *sync.Mutex
+	sums   map[string]float64
+	counts map[string]int
+	labels []string
+}
+
+func newMetric() *metric {
+	return &metric{mtx: &sync.Mutex{}, sums: make(map[string]float64), counts: make(map[string]int)}
+}
+
+func (m *metric) with(labelValues ...string) *metric {
+	return &metric{mtx: m.mtx, sums: m.sums, counts: m.counts, labels: append(append([]string(nil), m.labels...), labelValues...)}
+}
+
+func (m *metric) Add(delta float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	k := strings.Join(m.labels, ",")
+	m.sums[k] += delta
+	m.counts[k]++
+}
+
+func (m *metric) Set(value float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	k := strings.Join(m.labels, ",")
+	m.sums[k] = value
+	m.counts[k]++
+}
+
+func (m *metric) Observe(value float64) { m.Add(value) }
+
+func (m *metric) sum(labelValues ...string) float64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.sums[strings.Join(labelValues, ",")]
+}
+
+func (m *metric) count(labelValues ...string) int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.counts[strings.Join(labelValues, ",")]
+}
+
+type gauge struct{ *metric }
+
+func (g gauge) With(lvs ...string) metrics.Gauge { return gauge{g.with(lvs...)} }
+
+type counter struct{ *metric }
+
+func (c counter) With(lvs ...string) metrics.Counter { return counter{c.with(lvs...)} }
+
+type histogram struct{ *metric }
+
+func (h histogram) With(lvs ...string) metrics.Histogram { return histogram{h.with(lvs...)} }
+
+func TestCensusCount(t *testing.T) {
+	var (
+		cargos      = newMetric()
+		misdirected = newMetric()
+		locations   = newMetric()
+	)
+
+	c := cargo.New("ABC123", cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.AUMEL,
+	})
+
+	var stored []*cargo.Cargo
+
+	r := &mock.CargoRepository{
+		FindAllFn: func(context.Context) ([]*cargo.Cargo, error) {
+			return stored, nil
+		},
+	}
+
+	census := NewCensus(Gauges{
+		Cargos:            gauge{cargos},
+		MisdirectedCargos: gauge{misdirected},
+		CargosPerLocation: gauge{locations},
+	}, r)
+
+	ctx := context.Background()
+
+	stored = []*cargo.Cargo{c}
+	if err := census.Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Counting again, e.g. on another scrape, does not count the cargo twice.
+	if err := census.Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cargos.sum("routing_status", "not_routed", "transport_status", "not_received"); got != 1 {
+		t.Errorf("not routed = %v; want = %v", got, 1)
+	}
+
+	c.Delivery.RoutingStatus = cargo.Routed
+	c.Delivery.TransportStatus = cargo.InPort
+	c.Delivery.LastKnownLocation = location.CNHKG
+	c.Delivery.IsMisdirected = true
+
+	if err := census.Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cargos.sum("routing_status", "not_routed", "transport_status", "not_received"); got != 0 {
+		t.Errorf("not routed = %v; want = %v", got, 0)
+	}
+	if got := cargos.sum("routing_status", "routed", "transport_status", "in_port"); got != 1 {
+		t.Errorf("routed = %v; want = %v", got, 1)
+	}
+	if got := misdirected.sum(); got != 1 {
+		t.Errorf("misdirected = %v; want = %v", got, 1)
+	}
+	if got := locations.sum("location", "CNHKG"); got != 1 {
+		t.Errorf("CNHKG = %v; want = %v", got, 1)
+	}
+
+	stored = nil
+	if err := census.Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cargos.sum("routing_status", "routed", "transport_status", "in_port"); got != 0 {
+		t.Errorf("routed = %v; want = %v", got, 0)
+	}
+	if got := misdirected.sum(); got != 0 {
+		t.Errorf("misdirected = %v; want = %v", got, 0)
+	}
+	if got := locations.sum("location", "CNHKG"); got != 0 {
+		t.Errorf("CNHKG = %v; want = %v", got, 0)
+	}
+}
+
+func TestCensusCountFailure(t *testing.T) {
+	cargos := newMetric()
+
+	r := &mock.CargoRepository{
+		FindAllFn: func(context.Context) ([]*cargo.Cargo, error) {
+			return []*cargo.Cargo{cargo.New("ABC123", cargo.RouteSpecification{})}, nil
+		},
+	}
+
+	census := NewCensus(Gauges{
+		Cargos:            gauge{cargos},
+		MisdirectedCargos: gauge{newMetric()},
+		CargosPerLocation: gauge{newMetric()},
+	}, r)
+
+	if err := census.Count(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	r.FindAllFn = func(context.Context) ([]*cargo.Cargo, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	if err := census.Count(context.Background()); err == nil {
+		t.Errorf("err = %v; want error", err)
+	}
+	if got := cargos.sum("routing_status", "not_routed", "transport_status", "not_received"); got != 1 {
+		t.Errorf("not routed = %v; want = %v", got, 1)
+	}
+}
+
+func TestRecorderHandlingEvents(t *testing.T) {
+	var (
+		events = newMetric()
+		dwell  = newMetric()
+	)
+
+	r := NewRecorder(Metrics{
+		HandlingEvents: counter{events},
+		DwellTime:      histogram{dwell},
+	})
+
+	now := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	handle := func(t cargo.HandlingEventType, l location.UNLocode, after time.Duration) {
+		now = now.Add(after)
+		r.CargoWasHandled(context.Background(), cargo.HandlingEvent{
+			TrackingID: "ABC123",
+			Activity:   cargo.HandlingActivity{Type: t, Location: l, VoyageNumber: voyage.V100.Number},
+		})
+	}
+
+	handle(cargo.Unload, location.CNHKG, 0)
+	handle(cargo.Customs, location.CNHKG, time.Hour)
+	handle(cargo.Load, location.CNHKG, time.Hour)
+	handle(cargo.Load, location.CNHKG, time.Hour)
+
+	if got := events.sum("type", "load", "location", "CNHKG"); got != 2 {
+		t.Errorf("load events = %v; want = %v", got, 2)
+	}
+	if got := dwell.count("location", "CNHKG"); got != 1 {
+		t.Fatalf("dwell observations = %v; want = %v", got, 1)
+	}
+	if got := dwell.sum("location", "CNHKG"); got != (2 * time.Hour).Seconds() {
+		t.Errorf("dwell = %v; want = %v", got, (2 * time.Hour).Seconds())
+	}
+}
+
+func TestRecorderDeliveries(t *testing.T) {
+	deliveries := newMetric()
+
+	r := NewRecorder(Metrics{
+		Deliveries: counter{deliveries},
+	})
+
+	now := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	onTime := cargo.New("ONTIME", cargo.RouteSpecification{ArrivalDeadline: now.Add(time.Hour)})
+	late := cargo.New("LATE", cargo.RouteSpecification{ArrivalDeadline: now.Add(-time.Hour)})
+
+	ctx := context.Background()
+
+	for _, c := range []*cargo.Cargo{onTime, late} {
+		r.CargoHasArrived(ctx, c)
+		c.Delivery.IsUnloadedAtDestination = true
+		r.CargoWasStored(c)
+
+		// Inspecting the cargo again does not count as another delivery.
+		r.CargoHasArrived(ctx, c)
+	}
+
+	if got := deliveries.sum("outcome", "on_time"); got != 1 {
+		t.Errorf("on time = %v; want = %v", got, 1)
+	}
+	if got := deliveries.sum("outcome", "late"); got != 1 {
+		t.Errorf("late = %v; want = %v", got, 1)
+	}
+}
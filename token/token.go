@@ -0,0 +1,166 @@
+// Package token issues and verifies the signed tokens of shareable tracking
+// links. A token grants whoever holds it the right to track a single cargo,
+// without an account, until it expires or is revoked.
+package token
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+var (
+	// ErrInvalid is returned for tokens that are malformed or were not
+	// signed with the key of the issuer.
+	ErrInvalid = errors.New("invalid tracking token")
+
+	// ErrExpired is returned for tokens past their expiry.
+	ErrExpired = errors.New("tracking token has expired")
+
+	// ErrRevoked is returned for tokens issued before the tokens of their
+	// cargo were revoked.
+	ErrRevoked = errors.New("tracking token has been revoked")
+)
+
+// PathPrefix is the path prefix of public tracking links, which end in a
+// token.
+const PathPrefix = "/tracking/v1/public/"
+
+// RedactPath returns the path p of a request with the token replaced, if it
+// is that of a public tracking link, for logs and traces to leave tokens out.
+func RedactPath(p string) string {
+	if strings.HasPrefix(p, PathPrefix) && len(p) > len(PathPrefix) {
+		return PathPrefix + "{token}"
+	}
+	return p
+}
+
+// Token is what a tracking token grants.
+type Token struct {
+	TrackingID cargo.TrackingID
+	IssuedAt   time.Time
+	Expires    time.Time
+}
+
+// RevocationStore keeps track of revoked tokens.
+type RevocationStore interface {
+	// Revoke revokes the tokens of the cargo issued no later than t.
+	Revoke(ctx context.Context, id cargo.TrackingID, t time.Time) error
+
+	// Revoked returns the time up to which the tokens of the cargo have
+	// been revoked, or the zero time if none have.
+	Revoked(ctx context.Context, id cargo.TrackingID) (time.Time, error)
+}
+
+// Issuer issues and verifies tokens signed with a secret key.
+type Issuer struct {
+	key         []byte
+	ttl         time.Duration
+	revocations RevocationStore
+
+	// now returns the current time. Replaced in tests.
+	now func() time.Time
+}
+
+// NewIssuer returns an Issuer signing tokens with key, valid for ttl.
+// Tokens are only as secret as the key, which should be at least 32 random
+// bytes and shared by all instances of the application.
+func NewIssuer(key []byte, ttl time.Duration, revocations RevocationStore) *Issuer {
+	return &Issuer{
+		key:         key,
+		ttl:         ttl,
+		revocations: revocations,
+		now:         time.Now,
+	}
+}
+
+// claims is the signed part of a token.
+type claims struct {
+	TrackingID string `json:"sub"`
+	IssuedAt   int64  `json:"iat"`
+	Expires    int64  `json:"exp"`
+}
+
+// Issue returns a new token for the cargo.
+func (i *Issuer) Issue(id cargo.TrackingID) (string, Token) {
+	now := i.now()
+
+	t := Token{
+		TrackingID: id,
+		IssuedAt:   now,
+		Expires:    now.Add(i.ttl).Truncate(time.Second),
+	}
+
+	payload, _ := json.Marshal(claims{
+		TrackingID: string(id),
+		IssuedAt:   t.IssuedAt.UnixNano(),
+		Expires:    t.Expires.Unix(),
+	})
+
+	p := base64.RawURLEncoding.EncodeToString(payload)
+
+	return p + "." + base64.RawURLEncoding.EncodeToString(i.sign(p)), t
+}
+
+// Verify returns what the token grants, if it was issued by i and has
+// neither expired nor been revoked.
+func (i *Issuer) Verify(ctx context.Context, s string) (Token, error) {
+	p, sig, ok := strings.Cut(s, ".")
+	if !ok {
+		return Token{}, ErrInvalid
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, i.sign(p)) {
+		return Token{}, ErrInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return Token{}, ErrInvalid
+	}
+
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.TrackingID == "" {
+		return Token{}, ErrInvalid
+	}
+
+	t := Token{
+		TrackingID: cargo.TrackingID(c.TrackingID),
+		IssuedAt:   time.Unix(0, c.IssuedAt),
+		Expires:    time.Unix(c.Expires, 0),
+	}
+
+	if !i.now().Before(t.Expires) {
+		return Token{}, ErrExpired
+	}
+
+	revoked, err := i.revocations.Revoked(ctx, t.TrackingID)
+	if err != nil {
+		return Token{}, err
+	}
+	if !t.IssuedAt.After(revoked) {
+		return Token{}, ErrRevoked
+	}
+
+	return t, nil
+}
+
+// Revoke revokes all tokens issued for the cargo so far. Tokens issued
+// afterwards are valid.
+func (i *Issuer) Revoke(ctx context.Context, id cargo.TrackingID) error {
+	return i.revocations.Revoke(ctx, id, i.now())
+}
+
+func (i *Issuer) sign(payload string) []byte {
+	h := hmac.New(sha256.New, i.key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
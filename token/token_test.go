@@ -0,0 +1,140 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+type stubRevocationStore map[cargo.TrackingID]time.Time
+
+func (s stubRevocationStore) Revoke(_ context.Context, id cargo.TrackingID, t time.Time) error {
+	s[id] = t
+	return nil
+}
+
+func (s stubRevocationStore) Revoked(_ context.Context, id cargo.TrackingID) (time.Time, error) {
+	return s[id], nil
+}
+
+// newTestIssuer returns an issuer whose clock is advanced by setting *now.
+func newTestIssuer(key string) (*Issuer, *time.Time) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	i := NewIssuer([]byte(key), 24*time.Hour, stubRevocationStore{})
+	i.now = func() time.Time { return now }
+	return i, &now
+}
+
+func TestVerify(t *testing.T) {
+	i, now := newTestIssuer("secret")
+
+	s, issued := i.Issue("ABC123")
+
+	if want := now.Add(24 * time.Hour); !issued.Expires.Equal(want) {
+		t.Errorf("issued.Expires = %v; want = %v", issued.Expires, want)
+	}
+
+	got, err := i.Verify(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.TrackingID != "ABC123" {
+		t.Errorf("got.TrackingID = %s; want = %s", got.TrackingID, "ABC123")
+	}
+	if !got.IssuedAt.Equal(issued.IssuedAt) {
+		t.Errorf("got.IssuedAt = %v; want = %v", got.IssuedAt, issued.IssuedAt)
+	}
+	if !got.Expires.Equal(issued.Expires) {
+		t.Errorf("got.Expires = %v; want = %v", got.Expires, issued.Expires)
+	}
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	i, _ := newTestIssuer("secret")
+	other, _ := newTestIssuer("other secret")
+
+	s, _ := i.Issue("ABC123")
+	forged, _ := other.Issue("ABC123")
+	swapped, _ := i.Issue("DEF456")
+
+	p, sig, _ := strings.Cut(s, ".")
+	q, _, _ := strings.Cut(swapped, ".")
+
+	for _, tt := range []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", p},
+		{"other key", forged},
+		{"other payload", q + "." + sig},
+		{"truncated signature", p + "." + sig[:len(sig)-2]},
+		{"not base64", p + ".!!"},
+	} {
+		if _, err := i.Verify(context.Background(), tt.token); err != ErrInvalid {
+			t.Errorf("%s: err = %v; want = %v", tt.name, err, ErrInvalid)
+		}
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	i, now := newTestIssuer("secret")
+
+	s, _ := i.Issue("ABC123")
+
+	*now = now.Add(24*time.Hour - time.Second)
+	if _, err := i.Verify(context.Background(), s); err != nil {
+		t.Errorf("err = %v; want = %v", err, nil)
+	}
+
+	*now = now.Add(time.Second)
+	if _, err := i.Verify(context.Background(), s); err != ErrExpired {
+		t.Errorf("err = %v; want = %v", err, ErrExpired)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	i, now := newTestIssuer("secret")
+
+	revoked, _ := i.Issue("ABC123")
+	other, _ := i.Issue("DEF456")
+
+	if err := i.Revoke(ctx, "ABC123"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := i.Verify(ctx, revoked); err != ErrRevoked {
+		t.Errorf("err = %v; want = %v", err, ErrRevoked)
+	}
+	if _, err := i.Verify(ctx, other); err != nil {
+		t.Errorf("err = %v; want = %v", err, nil)
+	}
+
+	*now = now.Add(time.Nanosecond)
+
+	reissued, _ := i.Issue("ABC123")
+	if _, err := i.Verify(ctx, reissued); err != nil {
+		t.Errorf("err = %v; want = %v", err, nil)
+	}
+}
+
+func TestRedactPath(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"/tracking/v1/public/eyJzdWIiOiJBQkMxMjMifQ.c2ln", "/tracking/v1/public/{token}"},
+		{"/tracking/v1/public/", "/tracking/v1/public/"},
+		{"/tracking/v1/cargos/ABC123", "/tracking/v1/cargos/ABC123"},
+	} {
+		if got := RedactPath(tt.path); got != tt.want {
+			t.Errorf("RedactPath(%q) = %q; want = %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	// ExportSpan exports a finished span. It must not block for long, since
+	// it is called at the end of every sampled span.
+	ExportSpan(s SpanData)
+
+	// Shutdown exports any buffered spans.
+	Shutdown(ctx context.Context) error
+}
+
+// WriterExporter writes each span as a line of JSON, which is useful during
+// development.
+type WriterExporter struct {
+	mtx     sync.Mutex
+	enc     *json.Encoder
+	service string
+}
+
+// NewWriterExporter returns a new exporter writing spans to w.
+func NewWriterExporter(w io.Writer, service string) *WriterExporter {
+	return &WriterExporter{
+		enc:     json.NewEncoder(w),
+		service: service,
+	}
+}
+
+type writtenSpan struct {
+	Service    string                 `json:"service"`
+	Name       string                 `json:"name"`
+	Kind       string                 `json:"kind"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	Duration   float64                `json:"duration_seconds"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// ExportSpan writes the span.
+func (e *WriterExporter) ExportSpan(s SpanData) {
+	ws := writtenSpan{
+		Service:  e.service,
+		Name:     s.Name,
+		Kind:     s.Kind.String(),
+		TraceID:  s.SpanContext.TraceID.String(),
+		SpanID:   s.SpanContext.SpanID.String(),
+		Start:    s.Start,
+		Duration: s.End.Sub(s.Start).Seconds(),
+		Error:    s.Err,
+	}
+	if s.Parent.IsValid() {
+		ws.ParentID = s.Parent.String()
+	}
+	if len(s.Attributes) > 0 {
+		ws.Attributes = make(map[string]interface{}, len(s.Attributes))
+		for _, a := range s.Attributes {
+			ws.Attributes[a.Key] = attributeValue(a.Value)
+		}
+	}
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.enc.Encode(ws)
+}
+
+// Shutdown does nothing, since spans are written immediately.
+func (e *WriterExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// InMemoryExporter keeps the exported spans in memory, which is useful in
+// tests.
+type InMemoryExporter struct {
+	mtx   sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter returns a new in-memory exporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan stores the span.
+func (e *InMemoryExporter) ExportSpan(s SpanData) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// Spans returns the spans exported so far, in the order they ended.
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset removes all exported spans.
+func (e *InMemoryExporter) Reset() {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.spans = nil
+}
+
+// Shutdown does nothing.
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// attributeValue returns v as a value suitable for encoding, where values of
+// types other than the basic ones are turned into strings.
+func attributeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, string, bool, int, int64, float64:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	case interface {
+		String() string
+	}:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,265 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// OTLP exporter defaults.
+const (
+	otlpQueueSize     = 2048
+	otlpBatchSize     = 512
+	otlpFlushInterval = 5 * time.Second
+)
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector, using
+// the JSON encoding of OTLP over HTTP. Spans are dropped rather than slowing
+// down requests when the collector cannot keep up.
+type OTLPExporter struct {
+	url     string
+	service string
+	client  *http.Client
+	logger  log.Logger
+
+	spans    chan SpanData
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+// NewOTLPExporter returns a new exporter sending spans to the OTLP/HTTP
+// endpoint at url, e.g. http://localhost:4318/v1/traces. Failed exports are
+// logged to logger.
+func NewOTLPExporter(url, service string, logger log.Logger) *OTLPExporter {
+	e := &OTLPExporter{
+		url:      url,
+		service:  service,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+		spans:    make(chan SpanData, otlpQueueSize),
+		shutdown: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go e.loop()
+
+	return e
+}
+
+// ExportSpan queues the span for export.
+func (e *OTLPExporter) ExportSpan(s SpanData) {
+	select {
+	case e.spans <- s:
+	default:
+		e.logger.Log("msg", "span dropped", "err", "export queue full")
+	}
+}
+
+// Shutdown exports the queued spans, or gives up when ctx is done.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	select {
+	case <-e.shutdown:
+	default:
+		close(e.shutdown)
+	}
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OTLPExporter) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	var batch []SpanData
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			e.logger.Log("msg", "export failed", "spans", len(batch), "err", err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) >= otlpBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.shutdown:
+			for {
+				select {
+				case s := <-e.spans:
+					batch = append(batch, s)
+					if len(batch) >= otlpBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []SpanData) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(e.request(spans)); err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", &buf)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// The following types model the JSON encoding of an OTLP
+// ExportTraceServiceRequest. IDs are hex-encoded and 64-bit integers are
+// encoded as decimal strings.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP status codes.
+const (
+	otlpStatusUnset = 0
+	otlpStatusError = 2
+)
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (e *OTLPExporter) request(spans []SpanData) otlpRequest {
+	ss := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		os := otlpSpan{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusUnset},
+		}
+		if s.Parent.IsValid() {
+			os.ParentSpanID = s.Parent.String()
+		}
+		for _, a := range s.Attributes {
+			os.Attributes = append(os.Attributes, otlpAttribute(a.Key, a.Value))
+		}
+		if s.Err != "" {
+			os.Status = otlpStatus{Code: otlpStatusError, Message: s.Err}
+		}
+		ss = append(ss, os)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{otlpAttribute("service.name", e.service)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/marcusolsson/goddd/tracing"},
+				Spans: ss,
+			}},
+		}},
+	}
+}
+
+func otlpAttribute(key string, v interface{}) otlpKeyValue {
+	var av otlpAnyValue
+	switch v := attributeValue(v).(type) {
+	case bool:
+		av.BoolValue = &v
+	case int:
+		s := strconv.Itoa(v)
+		av.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		av.IntValue = &s
+	case float64:
+		av.DoubleValue = &v
+	case string:
+		av.StringValue = &v
+	default:
+		s := fmt.Sprint(v)
+		av.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: av}
+}
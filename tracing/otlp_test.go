@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	reqs := make(chan otlpRequest, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		reqs <- req
+	}))
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(srv.URL, "goddd", log.NewLogfmtLogger(ioutil.Discard))
+	tracer := NewTracer(exporter, 1)
+
+	ctx, parent := tracer.Start(context.Background(), "parent", KindServer)
+	_, child := tracer.Start(ctx, "child", KindClient)
+	child.SetAttributes("count", 3, "ok", true)
+	child.SetError(errors.New("timeout"))
+	child.End()
+	parent.End()
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-reqs
+
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("req = %+v; want one resource and scope", req)
+	}
+
+	service := req.ResourceSpans[0].Resource.Attributes[0]
+	if service.Key != "service.name" || *service.Value.StringValue != "goddd" {
+		t.Errorf("service = %+v; want service.name=goddd", service)
+	}
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("len(spans) = %d; want = %d", len(spans), 2)
+	}
+
+	c, p := spans[0], spans[1]
+
+	if c.ParentSpanID != p.SpanID {
+		t.Errorf("c.ParentSpanID = %s; want = %s", c.ParentSpanID, p.SpanID)
+	}
+	if c.Kind != int(KindClient) {
+		t.Errorf("c.Kind = %d; want = %d", c.Kind, KindClient)
+	}
+	if c.Status.Code != otlpStatusError || c.Status.Message != "timeout" {
+		t.Errorf("c.Status = %+v; want error status", c.Status)
+	}
+	if len(c.Attributes) != 2 || *c.Attributes[0].Value.IntValue != "3" || !*c.Attributes[1].Value.BoolValue {
+		t.Errorf("c.Attributes = %+v; want count=3 ok=true", c.Attributes)
+	}
+}
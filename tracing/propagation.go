@@ -0,0 +1,194 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/endpoint"
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/marcusolsson/goddd/httpctx"
+	"github.com/marcusolsson/goddd/token"
+)
+
+// TraceparentHeader is the W3C Trace Context header carrying the span
+// context between services.
+const TraceparentHeader = "traceparent"
+
+// Inject writes the span context carried by ctx, if any, to the header.
+func Inject(ctx context.Context, h http.Header) {
+	sc, ok := FromContext(ctx)
+	if !ok || !sc.IsValid() {
+		return
+	}
+
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	h.Set(TraceparentHeader, fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags))
+}
+
+// Extract returns a context carrying the span context read from the header.
+// If the header holds no valid span context, ctx is returned unchanged.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, ok := parseTraceparent(h.Get(TraceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return NewContext(ctx, sc)
+}
+
+// ExtractMetadata returns a context carrying the span context read from the
+// gRPC metadata. If the metadata holds no valid span context, ctx is
+// returned unchanged.
+func ExtractMetadata(ctx context.Context, md metadata.MD) context.Context {
+	var s string
+	if v := md[TraceparentHeader]; len(v) > 0 {
+		s = v[0]
+	}
+	sc, ok := parseTraceparent(s)
+	if !ok {
+		return ctx
+	}
+	return NewContext(ctx, sc)
+}
+
+func parseTraceparent(s string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Version ff is invalid, and version 00 has exactly four fields. Later
+	// versions may add fields, which are ignored.
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	var f [1]byte
+
+	if _, err := hex.Decode(sc.TraceID[:], []byte(traceID)); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(spanID)); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(f[:], []byte(flags)); err != nil {
+		return SpanContext{}, false
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+
+	sc.Sampled = f[0]&0x01 == 0x01
+	sc.Remote = true
+
+	return sc, true
+}
+
+// ContextToHTTP writes the span context carried by ctx to the request
+// header. It is intended to be used as a kithttp.ClientBefore function.
+func ContextToHTTP(ctx context.Context, r *http.Request) context.Context {
+	Inject(ctx, r.Header)
+	return ctx
+}
+
+// Handler returns a handler that continues the trace sent by the client, if
+// any, with a server span around next.
+func Handler(t *Tracer, next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := Extract(r.Context(), r.Header)
+
+		path := token.RedactPath(r.URL.Path)
+
+		ctx, span := t.Start(ctx, r.Method+" "+path, KindServer)
+		defer span.End()
+
+		sw := httpctx.NewStatusWriter(w)
+
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(
+			"http.method", r.Method,
+			"http.target", path,
+			"http.status_code", sw.Code,
+		)
+		if sw.Code >= 500 {
+			span.SetError(fmt.Errorf("%d %s", sw.Code, http.StatusText(sw.Code)))
+		}
+	})
+}
+
+// UnaryServerInterceptor returns an interceptor that continues the trace
+// sent by the client, if any, with a server span around each call.
+func UnaryServerInterceptor(t *Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx oldcontext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if t == nil {
+			return handler(ctx, req)
+		}
+
+		if md, ok := metadata.FromContext(ctx); ok {
+			ctx = ExtractMetadata(ctx, md)
+		}
+
+		ctx, span := t.Start(ctx, info.FullMethod, KindServer)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+
+		code := grpc.Code(err)
+		span.SetAttributes(
+			"rpc.system", "grpc",
+			"rpc.method", info.FullMethod,
+			"rpc.grpc.status_code", int(code),
+		)
+		if serverError(code) {
+			span.SetError(err)
+		}
+
+		return resp, err
+	}
+}
+
+// serverError reports whether calls failing with code failed because of the
+// server rather than the client.
+func serverError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// EndpointMiddleware returns a middleware that wraps each call to the
+// endpoint in a span of the given name and kind.
+func EndpointMiddleware(t *Tracer, name string, kind SpanKind) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			ctx, span := t.Start(ctx, name, kind)
+			defer func() {
+				span.SetError(err)
+				span.End()
+			}()
+			return next(ctx, request)
+		}
+	}
+}
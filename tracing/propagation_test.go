@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		in      string
+		valid   bool
+		sampled bool
+	}{
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: true, sampled: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", valid: true},
+		{in: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", valid: true, sampled: true},
+		{in: ""},
+		{in: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{in: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01"},
+		{in: "00-xbf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		sc, ok := parseTraceparent(tt.in)
+		if ok != tt.valid {
+			t.Errorf("parseTraceparent(%q) ok = %v; want = %v", tt.in, ok, tt.valid)
+			continue
+		}
+		if ok && sc.Sampled != tt.sampled {
+			t.Errorf("parseTraceparent(%q) sampled = %v; want = %v", tt.in, sc.Sampled, tt.sampled)
+		}
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	want := SpanContext{
+		TraceID: TraceID{0x4b, 0xf9, 0x2f},
+		SpanID:  SpanID{0x00, 0xf0, 0x67},
+		Sampled: true,
+	}
+
+	h := make(http.Header)
+	Inject(NewContext(context.Background(), want), h)
+
+	got, ok := FromContext(Extract(context.Background(), h))
+	if !ok {
+		t.Fatalf("no span context extracted from %q", h.Get(TraceparentHeader))
+	}
+
+	want.Remote = true
+	if got != want {
+		t.Errorf("got = %+v; want = %+v", got, want)
+	}
+}
+
+func TestHandlerContinuesTrace(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, 0)
+
+	h := Handler(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracer.Start(r.Context(), "child", KindInternal)
+		span.End()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	req := httptest.NewRequest("GET", "/tracking/v1/cargos/ABC123", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("len(spans) = %d; want = %d", len(spans), 2)
+	}
+
+	server := spans[1]
+	if server.Kind != KindServer {
+		t.Errorf("server.Kind = %s; want = %s", server.Kind, KindServer)
+	}
+	if got := server.SpanContext.TraceID.String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("server.TraceID = %s; want = %s", got, "4bf92f3577b34da6a3ce929d0e0e4736")
+	}
+	if got := server.Parent.String(); got != "00f067aa0ba902b7" {
+		t.Errorf("server.Parent = %s; want = %s", got, "00f067aa0ba902b7")
+	}
+	if spans[0].Parent != server.SpanContext.SpanID {
+		t.Errorf("child.Parent = %s; want = %s", spans[0].Parent, server.SpanContext.SpanID)
+	}
+}
+
+func TestUnaryServerInterceptorContinuesTrace(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, 0)
+
+	interceptor := UnaryServerInterceptor(tracer)
+
+	tests := []struct {
+		err     error
+		wantErr bool
+	}{
+		{err: nil},
+		{err: grpc.Errorf(codes.NotFound, "unknown cargo")},
+		{err: grpc.Errorf(codes.Internal, "database down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		md := metadata.Pairs(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		ctx := metadata.NewContext(context.Background(), md)
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/goddd.tracking.v1.TrackingService/Track"}
+		interceptor(ctx, nil, info, func(ctx oldcontext.Context, _ interface{}) (interface{}, error) {
+			return nil, tt.err
+		})
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != len(tests) {
+		t.Fatalf("len(spans) = %d; want = %d", len(spans), len(tests))
+	}
+
+	for i, tt := range tests {
+		span := spans[i]
+		if span.Name != "/goddd.tracking.v1.TrackingService/Track" {
+			t.Errorf("span.Name = %s; want = %s", span.Name, "/goddd.tracking.v1.TrackingService/Track")
+		}
+		if span.Kind != KindServer {
+			t.Errorf("span.Kind = %s; want = %s", span.Kind, KindServer)
+		}
+		if got := span.Parent.String(); got != "00f067aa0ba902b7" {
+			t.Errorf("span.Parent = %s; want = %s", got, "00f067aa0ba902b7")
+		}
+		if (span.Err != "") != tt.wantErr {
+			t.Errorf("span.Err = %q; want error = %v", span.Err, tt.wantErr)
+		}
+	}
+}
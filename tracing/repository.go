@@ -0,0 +1,174 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/voyage"
+)
+
+type cargoRepository struct {
+	tracer *Tracer
+	cargo.Repository
+}
+
+// NewCargoRepository returns a cargo repository tracing every call to r.
+func NewCargoRepository(t *Tracer, r cargo.Repository) cargo.Repository {
+	return &cargoRepository{t, r}
+}
+
+func (r *cargoRepository) Store(ctx context.Context, c *cargo.Cargo) (err error) {
+	ctx, span := r.tracer.Start(ctx, "cargo_repository.store", KindInternal)
+	span.SetAttributes("tracking_id", c.TrackingID)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.Store(ctx, c)
+}
+
+func (r *cargoRepository) Remove(ctx context.Context, c *cargo.Cargo) (err error) {
+	ctx, span := r.tracer.Start(ctx, "cargo_repository.remove", KindInternal)
+	span.SetAttributes("tracking_id", c.TrackingID)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.Remove(ctx, c)
+}
+
+func (r *cargoRepository) Find(ctx context.Context, id cargo.TrackingID) (c *cargo.Cargo, err error) {
+	ctx, span := r.tracer.Start(ctx, "cargo_repository.find", KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.Find(ctx, id)
+}
+
+func (r *cargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) (cs []*cargo.Cargo, err error) {
+	ctx, span := r.tracer.Start(ctx, "cargo_repository.find_many", KindInternal)
+	span.SetAttributes("requested", len(ids))
+	defer func() {
+		span.SetAttributes("count", len(cs))
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.FindMany(ctx, ids)
+}
+
+func (r *cargoRepository) FindAll(ctx context.Context) (cs []*cargo.Cargo, err error) {
+	ctx, span := r.tracer.Start(ctx, "cargo_repository.find_all", KindInternal)
+	defer func() {
+		span.SetAttributes("count", len(cs))
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.FindAll(ctx)
+}
+
+type locationRepository struct {
+	tracer *Tracer
+	location.Repository
+}
+
+// NewLocationRepository returns a location repository tracing every call to
+// r.
+func NewLocationRepository(t *Tracer, r location.Repository) location.Repository {
+	return &locationRepository{t, r}
+}
+
+func (r *locationRepository) Find(ctx context.Context, locode location.UNLocode) (l *location.Location, err error) {
+	ctx, span := r.tracer.Start(ctx, "location_repository.find", KindInternal)
+	span.SetAttributes("location", locode)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.Find(ctx, locode)
+}
+
+func (r *locationRepository) FindAll(ctx context.Context) (ls []*location.Location, err error) {
+	ctx, span := r.tracer.Start(ctx, "location_repository.find_all", KindInternal)
+	defer func() {
+		span.SetAttributes("count", len(ls))
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.FindAll(ctx)
+}
+
+type voyageRepository struct {
+	tracer *Tracer
+	voyage.Repository
+}
+
+// NewVoyageRepository returns a voyage repository tracing every call to r.
+func NewVoyageRepository(t *Tracer, r voyage.Repository) voyage.Repository {
+	return &voyageRepository{t, r}
+}
+
+func (r *voyageRepository) Find(ctx context.Context, number voyage.Number) (v *voyage.Voyage, err error) {
+	ctx, span := r.tracer.Start(ctx, "voyage_repository.find", KindInternal)
+	span.SetAttributes("voyage", number)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.Find(ctx, number)
+}
+
+func (r *voyageRepository) FindAll(ctx context.Context) (vs []*voyage.Voyage, err error) {
+	ctx, span := r.tracer.Start(ctx, "voyage_repository.find_all", KindInternal)
+	defer func() {
+		span.SetAttributes("count", len(vs))
+		span.SetError(err)
+		span.End()
+	}()
+	return r.Repository.FindAll(ctx)
+}
+
+type handlingEventRepository struct {
+	tracer *Tracer
+	cargo.HandlingEventRepository
+}
+
+// NewHandlingEventRepository returns a handling event repository tracing
+// every call to r.
+func NewHandlingEventRepository(t *Tracer, r cargo.HandlingEventRepository) cargo.HandlingEventRepository {
+	return &handlingEventRepository{t, r}
+}
+
+func (r *handlingEventRepository) Store(ctx context.Context, e cargo.HandlingEvent) (err error) {
+	ctx, span := r.tracer.Start(ctx, "handling_event_repository.store", KindInternal)
+	span.SetAttributes("tracking_id", e.TrackingID, "event_type", e.Activity.Type)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return r.HandlingEventRepository.Store(ctx, e)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistory(ctx context.Context, id cargo.TrackingID) (h cargo.HandlingHistory, err error) {
+	ctx, span := r.tracer.Start(ctx, "handling_event_repository.query_handling_history", KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetAttributes("count", len(h.HandlingEvents))
+		span.SetError(err)
+		span.End()
+	}()
+	return r.HandlingEventRepository.QueryHandlingHistory(ctx, id)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ctx context.Context, ids []cargo.TrackingID) (hs map[cargo.TrackingID]cargo.HandlingHistory, err error) {
+	ctx, span := r.tracer.Start(ctx, "handling_event_repository.query_handling_histories", KindInternal)
+	span.SetAttributes("requested", len(ids))
+	defer func() {
+		span.SetAttributes("count", len(hs))
+		span.SetError(err)
+		span.End()
+	}()
+	return r.HandlingEventRepository.QueryHandlingHistories(ctx, ids)
+}
@@ -0,0 +1,256 @@
+// Package tracing provides spans compatible with OpenTelemetry and the W3C
+// Trace Context specification, and exporters sending them to a writer, to
+// memory or to an OTLP collector.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the trace ID is non-zero.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the span ID is non-zero.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// SpanContext is the part of a span that is propagated to child spans, both
+// within the process and to other services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	Remote  bool
+}
+
+// IsValid reports whether the span context has a trace and span ID.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+type contextKey struct{}
+
+// NewContext returns a new context carrying the span context.
+func NewContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the span context carried by ctx, if any.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// SpanKind describes the relationship between a span and its parent. The
+// values match those of OTLP.
+type SpanKind int
+
+// Span kinds.
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case KindInternal:
+		return "internal"
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	}
+	return "unspecified"
+}
+
+// Attribute is a key-value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanData is a finished span, as handed to exporters.
+type SpanData struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	Parent      SpanID
+	Start       time.Time
+	End         time.Time
+	Attributes  []Attribute
+	Err         string
+}
+
+// Span is an operation being traced. A nil *Span is valid and does nothing,
+// which is what a nil *Tracer returns.
+type Span struct {
+	tracer *Tracer
+
+	mtx   sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context of the span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttributes adds the key-value pairs to the span.
+func (s *Span) SetAttributes(keyvals ...interface{}) {
+	if s == nil {
+		return
+	}
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for i := 0; i < len(keyvals); i += 2 {
+		s.data.Attributes = append(s.data.Attributes, Attribute{
+			Key:   fmt.Sprint(keyvals[i]),
+			Value: keyvals[i+1],
+		})
+	}
+}
+
+// SetError marks the span as failed, unless err is nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.data.Err = err.Error()
+}
+
+// End finishes the span and exports it if it is sampled. Calls after the
+// first one have no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mtx.Lock()
+	if s.ended {
+		s.mtx.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mtx.Unlock()
+
+	if data.SpanContext.Sampled {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+// Tracer starts spans and hands the sampled ones to an exporter. A nil
+// *Tracer is valid and starts no spans.
+type Tracer struct {
+	exporter Exporter
+	ratio    float64
+}
+
+// NewTracer returns a new tracer exporting spans to exporter. Traces started
+// by the tracer are sampled with the given probability, while traces
+// continued from a parent span follow the sampling decision of the parent.
+func NewTracer(exporter Exporter, ratio float64) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		ratio:    ratio,
+	}
+}
+
+// Start starts a span as a child of the span context carried by ctx, if any,
+// and returns a context carrying the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	var sc SpanContext
+	var parent SpanID
+
+	if p, ok := FromContext(ctx); ok && p.IsValid() {
+		sc.TraceID = p.TraceID
+		sc.Sampled = p.Sampled
+		parent = p.SpanID
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample(sc.TraceID)
+	}
+	sc.SpanID = newSpanID()
+
+	s := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			Kind:        kind,
+			SpanContext: sc,
+			Parent:      parent,
+			Start:       time.Now(),
+		},
+	}
+
+	return NewContext(ctx, sc), s
+}
+
+// Shutdown flushes the spans not yet exported.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+// sample makes a sampling decision based on the trace ID, so that all
+// services seeing the trace ID make the same decision given the same ratio.
+func (t *Tracer) sample(id TraceID) bool {
+	if t.ratio >= 1 {
+		return true
+	}
+	if t.ratio <= 0 {
+		return false
+	}
+	x := binary.BigEndian.Uint64(id[8:]) >> 1
+	return x < uint64(t.ratio*(1<<63))
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
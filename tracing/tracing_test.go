@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStartChildSpan(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, 1)
+
+	ctx, parent := tracer.Start(context.Background(), "parent", KindServer)
+	_, child := tracer.Start(ctx, "child", KindInternal)
+	child.SetAttributes("tracking_id", "ABC123")
+	child.SetError(errors.New("unknown cargo"))
+	child.End()
+	parent.End()
+	parent.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("len(spans) = %d; want = %d", len(spans), 2)
+	}
+
+	c, p := spans[0], spans[1]
+
+	if c.SpanContext.TraceID != p.SpanContext.TraceID {
+		t.Errorf("c.TraceID = %s; want = %s", c.SpanContext.TraceID, p.SpanContext.TraceID)
+	}
+	if c.Parent != p.SpanContext.SpanID {
+		t.Errorf("c.Parent = %s; want = %s", c.Parent, p.SpanContext.SpanID)
+	}
+	if p.Parent.IsValid() {
+		t.Errorf("p.Parent = %s; want none", p.Parent)
+	}
+	if c.Err != "unknown cargo" {
+		t.Errorf("c.Err = %q; want = %q", c.Err, "unknown cargo")
+	}
+	if len(c.Attributes) != 1 || c.Attributes[0] != (Attribute{"tracking_id", "ABC123"}) {
+		t.Errorf("c.Attributes = %v; want = %v", c.Attributes, []Attribute{{"tracking_id", "ABC123"}})
+	}
+	if p.End.Before(c.End) {
+		t.Errorf("parent should end after child")
+	}
+}
+
+func TestSampling(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, 0)
+
+	ctx, span := tracer.Start(context.Background(), "unsampled", KindServer)
+	span.End()
+
+	if !span.SpanContext().IsValid() {
+		t.Errorf("unsampled spans should still be propagated")
+	}
+	if len(exporter.Spans()) != 0 {
+		t.Errorf("len(spans) = %d; want = %d", len(exporter.Spans()), 0)
+	}
+
+	// Children follow the decision of the parent, regardless of the ratio.
+	sampled := NewContext(ctx, SpanContext{TraceID: TraceID{1}, SpanID: SpanID{1}, Sampled: true})
+	_, span = tracer.Start(sampled, "sampled", KindInternal)
+	span.End()
+
+	if len(exporter.Spans()) != 1 {
+		t.Errorf("len(spans) = %d; want = %d", len(exporter.Spans()), 1)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+
+	ctx := context.Background()
+
+	got, span := tracer.Start(ctx, "noop", KindInternal)
+	if got != ctx {
+		t.Errorf("a nil tracer should not change the context")
+	}
+
+	span.SetAttributes("key", "value")
+	span.SetError(errors.New("error"))
+	span.End()
+}
@@ -0,0 +1,166 @@
+package tracking
+
+// catalog holds the messages describing tracking read models in one
+// language. Messages refer to values by placeholders, as the order of the
+// values differs between languages:
+//
+//	{location}  the name of a location, or its code if it has no name
+//	{voyage}    a voyage number
+//	{time}      a time, formatted with the time layout of the catalog
+type catalog struct {
+	// Status messages by transport status.
+	status        map[string]string
+	unknownStatus string
+
+	// Next expected activity messages by event type.
+	activities map[string]string
+	noActivity string
+
+	// Handling event messages by event type.
+	events       map[string]string
+	unknownEvent string
+
+	timeLayout string
+}
+
+// defaultLanguage is the language of text for clients not asking for one we
+// have a catalog for.
+const defaultLanguage = "en"
+
+// catalogs are the message catalogs by ISO 639-1 language code.
+var catalogs = map[string]*catalog{
+	// The English messages are those of earlier versions, kept as they were
+	// for clients matching on them, except that locations are named.
+	"en": {
+		status: map[string]string{
+			StatusNotReceived:    "Not received",
+			StatusInPort:         "In port {location}",
+			StatusOnboardCarrier: "Onboard voyage {voyage}",
+			StatusClaimed:        "Claimed",
+		},
+		unknownStatus: "Unknown",
+		activities: map[string]string{
+			EventReceive: "Next expected activity is to receive cargo in {location}.",
+			EventLoad:    "Next expected activity is to load cargo onto voyage {voyage} in {location}.",
+			EventUnload:  "Next expected activity is to unload cargo off of voyage {voyage} in {location}.",
+			EventCustoms: "Next expected activity is to customs cargo in {location}.",
+			EventClaim:   "Next expected activity is to claim cargo in {location}.",
+		},
+		noActivity: "There are currently no expected activities for this cargo.",
+		events: map[string]string{
+			EventReceive:           "Received in {location}, at {time}",
+			EventLoad:              "Loaded onto voyage {voyage} in {location}, at {time}.",
+			EventUnload:            "Unloaded off voyage {voyage} in {location}, at {time}.",
+			EventCustoms:           "Cleared customs in {location}, at {time}.",
+			EventClaim:             "Claimed in {location}, at {time}.",
+			EventGateIn:            "Gated in at {location}, at {time}.",
+			EventGateOut:           "Gated out at {location}, at {time}.",
+			EventStuff:             "Stuffed into container in {location}, at {time}.",
+			EventStrip:             "Stripped from container in {location}, at {time}.",
+			EventRailTransfer:      "Transferred by rail in {location}, at {time}.",
+			EventInspectionHold:    "Held for inspection in {location}, at {time}.",
+			EventInspectionRelease: "Released from inspection in {location}, at {time}.",
+		},
+		unknownEvent: "[Unknown status]",
+		timeLayout:   "2006-01-02T15:04:05Z07:00",
+	},
+	"sv": {
+		status: map[string]string{
+			StatusNotReceived:    "Ej mottaget",
+			StatusInPort:         "I hamn i {location}",
+			StatusOnboardCarrier: "Ombord på resa {voyage}",
+			StatusClaimed:        "Utlämnat",
+		},
+		unknownStatus: "Okänd",
+		activities: map[string]string{
+			EventReceive: "Nästa förväntade aktivitet är att godset tas emot i {location}.",
+			EventLoad:    "Nästa förväntade aktivitet är att godset lastas på resa {voyage} i {location}.",
+			EventUnload:  "Nästa förväntade aktivitet är att godset lossas från resa {voyage} i {location}.",
+			EventCustoms: "Nästa förväntade aktivitet är att godset tullklareras i {location}.",
+			EventClaim:   "Nästa förväntade aktivitet är att godset lämnas ut i {location}.",
+		},
+		noActivity: "Det finns för närvarande inga förväntade aktiviteter för godset.",
+		events: map[string]string{
+			EventReceive:           "Mottaget i {location}, {time}.",
+			EventLoad:              "Lastat på resa {voyage} i {location}, {time}.",
+			EventUnload:            "Lossat från resa {voyage} i {location}, {time}.",
+			EventCustoms:           "Tullklarerat i {location}, {time}.",
+			EventClaim:             "Utlämnat i {location}, {time}.",
+			EventGateIn:            "Inkört genom grinden i {location}, {time}.",
+			EventGateOut:           "Utkört genom grinden i {location}, {time}.",
+			EventStuff:             "Stuvat i container i {location}, {time}.",
+			EventStrip:             "Lossat ur container i {location}, {time}.",
+			EventRailTransfer:      "Omlastat till järnväg i {location}, {time}.",
+			EventInspectionHold:    "Kvarhållet för inspektion i {location}, {time}.",
+			EventInspectionRelease: "Frisläppt efter inspektion i {location}, {time}.",
+		},
+		unknownEvent: "[Okänd status]",
+		timeLayout:   "2006-01-02 15:04 MST",
+	},
+	"de": {
+		status: map[string]string{
+			StatusNotReceived:    "Nicht angenommen",
+			StatusInPort:         "Im Hafen {location}",
+			StatusOnboardCarrier: "An Bord der Reise {voyage}",
+			StatusClaimed:        "Abgeholt",
+		},
+		unknownStatus: "Unbekannt",
+		activities: map[string]string{
+			EventReceive: "Als Nächstes wird die Sendung in {location} angenommen.",
+			EventLoad:    "Als Nächstes wird die Sendung in {location} auf die Reise {voyage} verladen.",
+			EventUnload:  "Als Nächstes wird die Sendung in {location} von der Reise {voyage} entladen.",
+			EventCustoms: "Als Nächstes wird die Sendung in {location} verzollt.",
+			EventClaim:   "Als Nächstes wird die Sendung in {location} abgeholt.",
+		},
+		noActivity: "Für diese Sendung sind derzeit keine Aktivitäten geplant.",
+		events: map[string]string{
+			EventReceive:           "Angenommen in {location}, am {time}.",
+			EventLoad:              "Verladen auf die Reise {voyage} in {location}, am {time}.",
+			EventUnload:            "Entladen von der Reise {voyage} in {location}, am {time}.",
+			EventCustoms:           "Verzollt in {location}, am {time}.",
+			EventClaim:             "Abgeholt in {location}, am {time}.",
+			EventGateIn:            "Eingang am Terminal in {location}, am {time}.",
+			EventGateOut:           "Ausgang am Terminal in {location}, am {time}.",
+			EventStuff:             "In Container gestaut in {location}, am {time}.",
+			EventStrip:             "Aus Container entladen in {location}, am {time}.",
+			EventRailTransfer:      "Auf die Schiene umgeschlagen in {location}, am {time}.",
+			EventInspectionHold:    "Zur Inspektion angehalten in {location}, am {time}.",
+			EventInspectionRelease: "Nach Inspektion freigegeben in {location}, am {time}.",
+		},
+		unknownEvent: "[Unbekannter Status]",
+		timeLayout:   "02.01.2006, 15:04 MST",
+	},
+	"ja": {
+		status: map[string]string{
+			StatusNotReceived:    "未受領",
+			StatusInPort:         "{location}港に停泊中",
+			StatusOnboardCarrier: "航海{voyage}で輸送中",
+			StatusClaimed:        "引き取り済み",
+		},
+		unknownStatus: "不明",
+		activities: map[string]string{
+			EventReceive: "次の予定：{location}で受領。",
+			EventLoad:    "次の予定：{location}で航海{voyage}に積み込み。",
+			EventUnload:  "次の予定：{location}で航海{voyage}から荷揚げ。",
+			EventCustoms: "次の予定：{location}で通関。",
+			EventClaim:   "次の予定：{location}で引き取り。",
+		},
+		noActivity: "現在、この貨物に予定されている作業はありません。",
+		events: map[string]string{
+			EventReceive:           "{time}、{location}で受領。",
+			EventLoad:              "{time}、{location}で航海{voyage}に積み込み。",
+			EventUnload:            "{time}、{location}で航海{voyage}から荷揚げ。",
+			EventCustoms:           "{time}、{location}で通関。",
+			EventClaim:             "{time}、{location}で引き取り。",
+			EventGateIn:            "{time}、{location}でゲートイン。",
+			EventGateOut:           "{time}、{location}でゲートアウト。",
+			EventStuff:             "{time}、{location}でコンテナに積み付け。",
+			EventStrip:             "{time}、{location}でコンテナから取り出し。",
+			EventRailTransfer:      "{time}、{location}で鉄道に積み替え。",
+			EventInspectionHold:    "{time}、{location}で検査のため留置。",
+			EventInspectionRelease: "{time}、{location}で検査後に解放。",
+		},
+		unknownEvent: "[不明なステータス]",
+		timeLayout:   "2006年1月2日 15:04 MST",
+	},
+}
@@ -4,23 +4,114 @@ import (
 	"context"
 
 	"github.com/go-kit/kit/endpoint"
+
+	"github.com/marcusolsson/goddd/cargo"
 )
 
 type trackCargoRequest struct {
 	ID string
+
+	// Text describes the cargo in the text fields, which are left empty if
+	// it is nil.
+	Text *Text
 }
 
 type trackCargoResponse struct {
 	Cargo *Cargo `json:"cargo,omitempty"`
 	Err   error  `json:"error,omitempty"`
+
+	// lang is the language of the text fields, if set.
+	lang string
 }
 
 func (r trackCargoResponse) error() error { return r.Err }
 
+func (r trackCargoResponse) language() string { return r.lang }
+
 func makeTrackCargoEndpoint(ts Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(trackCargoRequest)
-		c, err := ts.Track(req.ID)
-		return trackCargoResponse{Cargo: &c, Err: err}, nil
+		c, err := ts.Track(ctx, req.ID)
+		if err != nil || req.Text == nil {
+			return trackCargoResponse{Cargo: &c, Err: err}, nil
+		}
+		c = req.Text.Describe(c)
+		return trackCargoResponse{Cargo: &c, lang: req.Text.Language()}, nil
+	}
+}
+
+type trackPublicRequest struct {
+	Token string
+
+	// Text describes the cargo in the text fields, which are left empty if
+	// it is nil.
+	Text *Text
+}
+
+func makeTrackPublicEndpoint(ts Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(trackPublicRequest)
+		c, err := ts.TrackPublic(ctx, req.Token)
+		if err != nil || req.Text == nil {
+			return trackCargoResponse{Cargo: &c, Err: err}, nil
+		}
+		c = req.Text.Describe(c)
+		return trackCargoResponse{Cargo: &c, lang: req.Text.Language()}, nil
+	}
+}
+
+type trackCargosRequest struct {
+	IDs []string
+
+	// Text describes the cargos in the text fields, which are left empty if
+	// it is nil.
+	Text *Text
+}
+
+type trackCargosResponse struct {
+	Cargos []Cargo `json:"cargos"`
+
+	// Errors holds the errors of the cargos that could not be tracked, by
+	// tracking ID.
+	Errors map[string]string `json:"errors,omitempty"`
+
+	Err error `json:"error,omitempty"`
+
+	// lang is the language of the text fields, if set.
+	lang string
+}
+
+func (r trackCargosResponse) error() error { return r.Err }
+
+func (r trackCargosResponse) language() string { return r.lang }
+
+func makeTrackCargosEndpoint(ts Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(trackCargosRequest)
+		found, err := ts.TrackMany(ctx, req.IDs)
+		if err != nil {
+			return trackCargosResponse{Err: err}, nil
+		}
+
+		resp := trackCargosResponse{Cargos: make([]Cargo, 0, len(found))}
+		for _, id := range req.IDs {
+			c, ok := found[id]
+			if !ok {
+				if resp.Errors == nil {
+					resp.Errors = make(map[string]string)
+				}
+				resp.Errors[id] = cargo.ErrUnknown.Error()
+				continue
+			}
+			if req.Text != nil {
+				c = req.Text.Describe(c)
+			}
+			resp.Cargos = append(resp.Cargos, c)
+		}
+		if req.Text != nil {
+			resp.lang = req.Text.Language()
+		}
+
+		return resp, nil
 	}
 }
@@ -0,0 +1,163 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+
+	kitlog "github.com/go-kit/kit/log"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/tracking/pb"
+	"github.com/marcusolsson/goddd/validation"
+)
+
+// MakeGRPCServer returns a server serving the tracking service over gRPC.
+func MakeGRPCServer(ctx context.Context, ts Service, logger kitlog.Logger) pb.TrackingServiceServer {
+	opts := []kitgrpc.ServerOption{
+		kitgrpc.ServerErrorLogger(logger),
+	}
+
+	return &grpcServer{
+		track: rpc.NewServer(
+			ctx,
+			makeTrackCargoEndpoint(ts),
+			decodeGRPCTrackCargoRequest,
+			encodeGRPCTrackCargoResponse,
+			opts...,
+		),
+	}
+}
+
+type grpcServer struct {
+	track kitgrpc.Handler
+}
+
+func (s *grpcServer) Track(ctx oldcontext.Context, req *pb.TrackRequest) (*pb.TrackResponse, error) {
+	_, resp, err := s.track.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp.(*pb.TrackResponse), nil
+}
+
+func decodeGRPCTrackCargoRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.TrackRequest)
+	if req.OmitText {
+		return trackCargoRequest{ID: req.TrackingId}, nil
+	}
+
+	zone, err := loadZone("time_zone", req.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	text := NewText(req.Language, zone)
+
+	return trackCargoRequest{ID: req.TrackingId, Text: &text}, nil
+}
+
+func encodeGRPCTrackCargoResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(trackCargoResponse)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+
+	c := resp.Cargo
+	events := make([]*pb.Event, len(c.Events))
+	for i, e := range c.Events {
+		events[i] = &pb.Event{
+			Description:      e.Description,
+			Expected:         e.Expected,
+			Type:             e.Type,
+			Location:         pbLocation(e.Location),
+			VoyageNumber:     e.VoyageNumber,
+			CompletionTime:   rpc.Timestamp(e.CompletionTime),
+			RegistrationTime: rpc.Timestamp(e.RegistrationTime),
+		}
+	}
+
+	status := &pb.Status{
+		Transport:     c.Status.Transport,
+		CurrentVoyage: c.Status.CurrentVoyage,
+		Misdirected:   c.Status.Misdirected,
+	}
+	if l := c.Status.LastKnownLocation; l != nil {
+		status.LastKnownLocation = pbLocation(*l)
+	}
+
+	var next *pb.Activity
+	if a := c.NextActivity; a != nil {
+		next = &pb.Activity{
+			Type:         a.Type,
+			Location:     pbLocation(a.Location),
+			VoyageNumber: a.VoyageNumber,
+		}
+	}
+
+	legs := make([]*pb.Leg, len(c.Legs))
+	for i, l := range c.Legs {
+		legs[i] = &pb.Leg{
+			VoyageNumber: l.VoyageNumber,
+			Mode:         l.Mode,
+			From:         pbLocation(l.From),
+			To:           pbLocation(l.To),
+			LoadTime:     rpc.Timestamp(l.LoadTime),
+			UnloadTime:   rpc.Timestamp(l.UnloadTime),
+			Status:       l.Status,
+		}
+	}
+
+	active := int32(-1)
+	if c.ActiveLeg != nil {
+		active = int32(*c.ActiveLeg)
+	}
+
+	return &pb.TrackResponse{
+		Cargo: &pb.Cargo{
+			TrackingId:           c.TrackingID,
+			StatusText:           c.StatusText,
+			Origin:               c.Origin,
+			Destination:          c.Destination,
+			Eta:                  rpc.Timestamp(c.ETA),
+			NextExpectedActivity: c.NextExpectedActivity,
+			ArrivalDeadline:      rpc.Timestamp(c.ArrivalDeadline),
+			Events:               events,
+			Status:               status,
+			NextActivity:         next,
+			Legs:                 legs,
+			Progress:             int32(c.Progress),
+			ActiveLeg:            active,
+			EtaEarliest:          rpc.Timestamp(c.ETAEarliest),
+			EtaLatest:            rpc.Timestamp(c.ETALatest),
+		},
+	}, nil
+}
+
+func pbLocation(l Location) *pb.Location {
+	return &pb.Location{Code: l.Code, Name: l.Name}
+}
+
+// grpcError returns err with the status code of errors from business-logic,
+// so that clients can tell them apart.
+func grpcError(err error) error {
+	return grpc.Errorf(grpcCode(err), "%v", err)
+}
+
+// grpcCode returns the status code of errors from business-logic.
+func grpcCode(err error) codes.Code {
+	var verr *validation.Error
+	switch {
+	case errors.As(err, &verr):
+		return codes.InvalidArgument
+	case err == cargo.ErrUnknown:
+		return codes.NotFound
+	case err == ErrInvalidArgument:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,108 @@
+package tracking
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/rpc"
+	"github.com/marcusolsson/goddd/tracking/pb"
+)
+
+func dialGRPC(t *testing.T, s Service) pb.TrackingServiceClient {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	pb.RegisterTrackingServiceServer(srv, MakeGRPCServer(context.Background(), s, kitlog.NewNopLogger()))
+	go srv.Serve(ln)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+
+	return pb.NewTrackingServiceClient(conn)
+}
+
+func TestGRPCTrack(t *testing.T) {
+	deadline := time.Date(2030, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
+		if id != "FTL456" {
+			return nil, cargo.ErrUnknown
+		}
+		return cargo.New("FTL456", cargo.RouteSpecification{
+			Origin:          location.AUMEL,
+			Destination:     location.SESTO,
+			ArrivalDeadline: deadline,
+		}), nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(_ context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
+	}
+
+	client := dialGRPC(t, NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil))
+	ctx := context.Background()
+
+	resp, err := client.Track(ctx, &pb.TrackRequest{TrackingId: "FTL456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := resp.GetCargo()
+	if c == nil {
+		t.Fatal("resp.Cargo is nil")
+	}
+	if c.TrackingId != "FTL456" {
+		t.Errorf("c.TrackingId = %v; want = %v", c.TrackingId, "FTL456")
+	}
+	if c.Origin != "AUMEL" {
+		t.Errorf("c.Origin = %v; want = %v", c.Origin, "AUMEL")
+	}
+	if c.StatusText == "" {
+		t.Errorf("c.StatusText is empty")
+	}
+	if s := c.GetStatus(); s == nil || s.Transport != StatusNotReceived {
+		t.Errorf("c.Status = %v; want transport %q", s, StatusNotReceived)
+	}
+	if got := rpc.Time(c.GetArrivalDeadline()); !got.Equal(deadline) {
+		t.Errorf("c.ArrivalDeadline = %v; want = %v", got, deadline)
+	}
+
+	resp, err = client.Track(ctx, &pb.TrackRequest{TrackingId: "FTL456", Language: "ja-JP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.GetCargo().StatusText, "未受領"; got != want {
+		t.Errorf("c.StatusText = %q; want = %q", got, want)
+	}
+
+	_, err = client.Track(ctx, &pb.TrackRequest{TrackingId: "ABC123"})
+	if code := grpc.Code(err); code != codes.NotFound {
+		t.Errorf("code = %s; want = %s (err = %v)", code, codes.NotFound, err)
+	}
+
+	_, err = client.Track(ctx, &pb.TrackRequest{TrackingId: "FTL456", TimeZone: "Nowhere"})
+	if code := grpc.Code(err); code != codes.InvalidArgument {
+		t.Errorf("code = %s; want = %s (err = %v)", code, codes.InvalidArgument, err)
+	}
+}
@@ -1,6 +1,7 @@
 package tracking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -21,11 +22,29 @@ func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram,
 	}
 }
 
-func (s *instrumentingService) Track(id string) (Cargo, error) {
+func (s *instrumentingService) Track(ctx context.Context, id string) (Cargo, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "track").Add(1)
 		s.requestLatency.With("method", "track").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.Service.Track(id)
+	return s.Service.Track(ctx, id)
+}
+
+func (s *instrumentingService) TrackMany(ctx context.Context, ids []string) (map[string]Cargo, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "track_many").Add(1)
+		s.requestLatency.With("method", "track_many").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.Service.TrackMany(ctx, ids)
+}
+
+func (s *instrumentingService) TrackPublic(ctx context.Context, tok string) (Cargo, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "track_public").Add(1)
+		s.requestLatency.With("method", "track_public").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.Service.TrackPublic(ctx, tok)
 }
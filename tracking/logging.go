@@ -1,9 +1,13 @@
 package tracking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	level "github.com/go-kit/kit/log/experimental_level"
+
+	"github.com/marcusolsson/goddd/requestid"
 )
 
 type loggingService struct {
@@ -16,9 +20,34 @@ func NewLoggingService(logger log.Logger, s Service) Service {
 	return &loggingService{logger, s}
 }
 
-func (s *loggingService) Track(id string) (c Cargo, err error) {
+func (s *loggingService) Track(ctx context.Context, id string) (c Cargo, err error) {
+	defer func(begin time.Time) {
+		leveled(requestid.Logger(ctx, s.logger), err).Log("method", "track", "tracking_id", id, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return s.Service.Track(ctx, id)
+}
+
+func (s *loggingService) TrackMany(ctx context.Context, ids []string) (cs map[string]Cargo, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log("method", "track", "tracking_id", id, "took", time.Since(begin), "err", err)
+		leveled(requestid.Logger(ctx, s.logger), err).Log("method", "track_many", "requested", len(ids), "found", len(cs), "took", time.Since(begin), "err", err)
 	}(time.Now())
-	return s.Service.Track(id)
+	return s.Service.TrackMany(ctx, ids)
+}
+
+// TrackPublic logs the tracking ID of the cargo rather than the token, which
+// grants whoever holds it tracking the cargo.
+func (s *loggingService) TrackPublic(ctx context.Context, tok string) (c Cargo, err error) {
+	defer func(begin time.Time) {
+		leveled(requestid.Logger(ctx, s.logger), err).Log("method", "track_public", "tracking_id", c.TrackingID, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return s.Service.TrackPublic(ctx, tok)
+}
+
+// leveled returns logger at error level if err is set, and at info level
+// otherwise, so that successful calls can be filtered by the log level.
+func leveled(logger log.Logger, err error) log.Logger {
+	if err != nil {
+		return level.Error(logger)
+	}
+	return level.Info(logger)
 }
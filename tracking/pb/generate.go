@@ -0,0 +1,3 @@
+package pb
+
+//go:generate protoc --go_out=plugins=grpc,Mgoogle/protobuf/timestamp.proto=github.com/golang/protobuf/ptypes/timestamp:. tracking.proto
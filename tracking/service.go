@@ -3,161 +3,472 @@
 package tracking
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/eta"
+	"github.com/marcusolsson/goddd/location"
+	"github.com/marcusolsson/goddd/token"
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
 var ErrInvalidArgument = errors.New("invalid argument")
 
+// MaxTrackMany is the maximum number of cargos tracked by a single call to
+// TrackMany.
+const MaxTrackMany = 100
+
 // Service is the interface that provides the basic Track method.
 type Service interface {
 	// Track returns a cargo matching a tracking ID.
-	Track(id string) (Cargo, error)
+	Track(ctx context.Context, id string) (Cargo, error)
+
+	// TrackMany returns the cargos matching up to MaxTrackMany tracking IDs,
+	// by tracking ID. Cargos that do not exist are left out.
+	TrackMany(ctx context.Context, ids []string) (map[string]Cargo, error)
+
+	// TrackPublic returns the cargo a tracking token was issued for, with
+	// the details meant for the customer only left out.
+	TrackPublic(ctx context.Context, tok string) (Cargo, error)
 }
 
 type service struct {
 	cargos         cargo.Repository
 	handlingEvents cargo.HandlingEventRepository
+	locations      location.Repository
+	tokens         *token.Issuer
+	estimator      *eta.Estimator
 }
 
-func (s *service) Track(id string) (Cargo, error) {
+func (s *service) Track(ctx context.Context, id string) (Cargo, error) {
 	if id == "" {
 		return Cargo{}, ErrInvalidArgument
 	}
-	c, err := s.cargos.Find(cargo.TrackingID(id))
+	c, err := s.cargos.Find(ctx, cargo.TrackingID(id))
+	if err != nil {
+		return Cargo{}, err
+	}
+	return assemble(ctx, c, s.handlingEvents, s.locations, s.estimator)
+}
+
+func (s *service) TrackMany(ctx context.Context, ids []string) (map[string]Cargo, error) {
+	if len(ids) > MaxTrackMany {
+		return nil, ErrInvalidArgument
+	}
+
+	tids := make([]cargo.TrackingID, len(ids))
+	for i, id := range ids {
+		if id == "" {
+			return nil, ErrInvalidArgument
+		}
+		tids[i] = cargo.TrackingID(id)
+	}
+
+	cs, err := s.cargos.FindMany(ctx, tids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]cargo.TrackingID, len(cs))
+	for i, c := range cs {
+		found[i] = c.TrackingID
+	}
+	histories, err := s.handlingEvents.QueryHandlingHistories(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cargos tend to share locations, so look each up once for all of them.
+	names := &locationNames{ctx: ctx, repo: s.locations}
+
+	result := make(map[string]Cargo, len(cs))
+	for _, c := range cs {
+		result[string(c.TrackingID)] = assembleCargo(c, histories[c.TrackingID], names, s.estimator)
+	}
+
+	return result, nil
+}
+
+func (s *service) TrackPublic(ctx context.Context, tok string) (Cargo, error) {
+	if tok == "" {
+		return Cargo{}, ErrInvalidArgument
+	}
+
+	t, err := s.tokens.Verify(ctx, tok)
+	if err != nil {
+		return Cargo{}, err
+	}
+
+	c, err := s.Track(ctx, string(t.TrackingID))
 	if err != nil {
 		return Cargo{}, err
 	}
-	return assemble(c, s.handlingEvents), nil
+
+	return redact(c), nil
 }
 
-// NewService returns a new instance of the default Service.
-func NewService(cargos cargo.Repository, events cargo.HandlingEventRepository) Service {
+// NewService returns a new instance of the default Service. Tokens verifies
+// the tokens of public tracking, and estimator estimates the times of
+// arrival of cargos.
+func NewService(cargos cargo.Repository, events cargo.HandlingEventRepository, locations location.Repository, tokens *token.Issuer, estimator *eta.Estimator) Service {
 	return &service{
 		cargos:         cargos,
 		handlingEvents: events,
+		locations:      locations,
+		tokens:         tokens,
+		estimator:      estimator,
 	}
 }
 
-// Cargo is a read model for tracking views.
+// Cargo is a read model for tracking views. The text fields describe the
+// structured ones for clients displaying them as is, and are only set by
+// Describe.
 type Cargo struct {
-	TrackingID           string    `json:"tracking_id"`
-	StatusText           string    `json:"status_text"`
-	Origin               string    `json:"origin"`
-	Destination          string    `json:"destination"`
-	ETA                  time.Time `json:"eta"`
-	NextExpectedActivity string    `json:"next_expected_activity"`
-	ArrivalDeadline      time.Time `json:"arrival_deadline"`
-	Events               []Event   `json:"events"`
+	TrackingID      string    `json:"tracking_id"`
+	Origin          string    `json:"origin"`
+	Destination     string    `json:"destination"`
+	ArrivalDeadline time.Time `json:"arrival_deadline"`
+	Status          Status    `json:"status"`
+	NextActivity    *Activity `json:"next_activity"`
+	Events          []Event   `json:"events"`
+
+	// ETA is the estimated time of arrival, from the handling of the cargo
+	// so far and how late its voyages and ports usually are. ETAEarliest
+	// and ETALatest bound the interval the cargo arrives within with 90%
+	// confidence. They are all zero for cargos that are not on track.
+	ETA         time.Time `json:"eta"`
+	ETAEarliest time.Time `json:"eta_earliest"`
+	ETALatest   time.Time `json:"eta_latest"`
+
+	// Legs is the itinerary of the cargo, which is empty until it has been
+	// routed.
+	Legs []Leg `json:"legs"`
+
+	// Progress is the percentage of the itinerary travelled, counting legs
+	// the cargo is onboard as half travelled.
+	Progress int `json:"progress"`
+
+	// ActiveLeg is the index of the leg the cargo is onboard, or else of the
+	// next leg it is to be loaded onto. It is nil when there is no such leg.
+	ActiveLeg *int `json:"active_leg"`
+
+	StatusText           string `json:"status_text,omitempty"`
+	NextExpectedActivity string `json:"next_expected_activity,omitempty"`
+}
+
+// Transport statuses.
+const (
+	StatusNotReceived    = "not_received"
+	StatusInPort         = "in_port"
+	StatusOnboardCarrier = "onboard_carrier"
+	StatusClaimed        = "claimed"
+	StatusUnknown        = "unknown"
+)
+
+// Status is a read model of the transport status of a cargo.
+type Status struct {
+	Transport string `json:"transport"`
+
+	// LastKnownLocation is nil until the cargo has been received.
+	LastKnownLocation *Location `json:"last_known_location"`
+
+	// CurrentVoyage is set while the cargo is onboard a carrier.
+	CurrentVoyage string `json:"current_voyage,omitempty"`
+
+	Misdirected bool `json:"misdirected"`
+}
+
+// Location is a read model for the locations of tracking views.
+type Location struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Handling event types.
+const (
+	EventReceive = "receive"
+	EventLoad    = "load"
+	EventUnload  = "unload"
+	EventCustoms = "customs"
+	EventClaim   = "claim"
+
+	EventGateIn            = "gate_in"
+	EventGateOut           = "gate_out"
+	EventStuff             = "stuff"
+	EventStrip             = "strip"
+	EventRailTransfer      = "rail_transfer"
+	EventInspectionHold    = "inspection_hold"
+	EventInspectionRelease = "inspection_release"
+)
+
+// Activity is a read model of the handling activity expected next.
+type Activity struct {
+	Type         string   `json:"type"`
+	Location     Location `json:"location"`
+	VoyageNumber string   `json:"voyage_number,omitempty"`
 }
 
-// Leg is a read model for booking views.
+// Leg statuses.
+const (
+	LegPending   = "pending"
+	LegLoaded    = "loaded"
+	LegCompleted = "completed"
+	LegSkipped   = "skipped"
+)
+
+// Leg is a read model of a leg of the itinerary of a cargo, with its status
+// derived from the handling history.
 type Leg struct {
 	VoyageNumber string    `json:"voyage_number"`
-	From         string    `json:"from"`
-	To           string    `json:"to"`
+	Mode         string    `json:"mode"`
+	From         Location  `json:"from"`
+	To           Location  `json:"to"`
 	LoadTime     time.Time `json:"load_time"`
 	UnloadTime   time.Time `json:"unload_time"`
+	Status       string    `json:"status"`
 }
 
-// Event is a read model for tracking views.
+// Event is a read model of a handling event for tracking views.
 type Event struct {
-	Description string `json:"description"`
-	Expected    bool   `json:"expected"`
+	Type             string    `json:"type"`
+	Location         Location  `json:"location"`
+	VoyageNumber     string    `json:"voyage_number,omitempty"`
+	CompletionTime   time.Time `json:"completion_time"`
+	RegistrationTime time.Time `json:"registration_time"`
+	Expected         bool      `json:"expected"`
+
+	Description string `json:"description,omitempty"`
+}
+
+// redact returns c without the details meant for the customer only: the
+// arrival deadline agreed on when booking, and when handling events were
+// registered with us. The events of c are copied rather than modified.
+func redact(c Cargo) Cargo {
+	c.ArrivalDeadline = time.Time{}
+
+	if c.Events != nil {
+		events := make([]Event, len(c.Events))
+		for i, e := range c.Events {
+			e.RegistrationTime = time.Time{}
+			events[i] = e
+		}
+		c.Events = events
+	}
+
+	return c
+}
+
+func assemble(ctx context.Context, c *cargo.Cargo, events cargo.HandlingEventRepository, locations location.Repository, estimator *eta.Estimator) (Cargo, error) {
+	h, err := events.QueryHandlingHistory(ctx, c.TrackingID)
+	if err != nil {
+		return Cargo{}, err
+	}
+	names := &locationNames{ctx: ctx, repo: locations}
+	return assembleCargo(c, h, names, estimator), nil
 }
 
-func assemble(c *cargo.Cargo, events cargo.HandlingEventRepository) Cargo {
+func assembleCargo(c *cargo.Cargo, h cargo.HandlingHistory, names *locationNames, estimator *eta.Estimator) Cargo {
+	legs := assembleLegs(c, h, names)
+	est := estimator.Estimate(c, h)
+
 	return Cargo{
-		TrackingID:           string(c.TrackingID),
-		Origin:               string(c.Origin),
-		Destination:          string(c.RouteSpecification.Destination),
-		ETA:                  c.Delivery.ETA,
-		NextExpectedActivity: nextExpectedActivity(c),
-		ArrivalDeadline:      c.RouteSpecification.ArrivalDeadline,
-		StatusText:           assembleStatusText(c),
-		Events:               assembleEvents(c, events),
+		TrackingID:      string(c.TrackingID),
+		Origin:          string(c.Origin),
+		Destination:     string(c.RouteSpecification.Destination),
+		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
+		ETA:             est.ETA,
+		ETAEarliest:     est.Earliest,
+		ETALatest:       est.Latest,
+		Status:          assembleStatus(c, names),
+		NextActivity:    assembleNextActivity(c, names),
+		Events:          assembleEvents(c, h, names),
+		Legs:            legs,
+		Progress:        progress(c, legs),
+		ActiveLeg:       activeLeg(legs),
 	}
 }
 
-func assembleLegs(c cargo.Cargo) []Leg {
-	var legs []Leg
-	for _, l := range c.Itinerary.Legs {
-		legs = append(legs, Leg{
+// assembleLegs returns the legs of the itinerary of c. A leg is loaded once
+// the cargo has been loaded onto its voyage at its load location, and
+// completed once the cargo has been unloaded off it at its unload location.
+// Legs that are not completed are skipped if the cargo has been loaded onto
+// a later leg, or has been claimed.
+func assembleLegs(c *cargo.Cargo, h cargo.HandlingHistory, names *locationNames) []Leg {
+	if c.Itinerary.IsEmpty() {
+		return nil
+	}
+
+	legs := make([]Leg, len(c.Itinerary.Legs))
+	for i, l := range c.Itinerary.Legs {
+		legs[i] = Leg{
 			VoyageNumber: string(l.VoyageNumber),
-			From:         string(l.LoadLocation),
-			To:           string(l.UnloadLocation),
+			Mode:         l.Mode.String(),
+			From:         names.location(l.LoadLocation),
+			To:           names.location(l.UnloadLocation),
 			LoadTime:     l.LoadTime,
 			UnloadTime:   l.UnloadTime,
-		})
+			Status:       legStatus(l, h),
+		}
+	}
+
+	passed := c.Delivery.TransportStatus == cargo.Claimed
+	for i := len(legs) - 1; i >= 0; i-- {
+		switch {
+		case legs[i].Status == LegCompleted:
+			passed = true
+		case passed:
+			legs[i].Status = LegSkipped
+		case legs[i].Status == LegLoaded:
+			passed = true
+		}
 	}
+
 	return legs
 }
 
-func nextExpectedActivity(c *cargo.Cargo) string {
-	a := c.Delivery.NextExpectedActivity
-	prefix := "Next expected activity is to"
+func legStatus(l cargo.Leg, h cargo.HandlingHistory) string {
+	status := LegPending
+	for _, e := range h.HandlingEvents {
+		if e.Activity.VoyageNumber != l.VoyageNumber {
+			continue
+		}
+		switch {
+		case e.Activity.Type == cargo.Unload && e.Activity.Location == l.UnloadLocation:
+			return LegCompleted
+		case e.Activity.Type == cargo.Load && e.Activity.Location == l.LoadLocation:
+			status = LegLoaded
+		}
+	}
+	return status
+}
+
+// progress returns the percentage of the legs travelled, counting loaded
+// legs as half travelled. Claimed cargos have travelled all of them.
+func progress(c *cargo.Cargo, legs []Leg) int {
+	if c.Delivery.TransportStatus == cargo.Claimed {
+		return 100
+	}
+	if len(legs) == 0 {
+		return 0
+	}
 
-	switch a.Type {
-	case cargo.Load:
-		return fmt.Sprintf("%s %s cargo onto voyage %s in %s.", prefix, strings.ToLower(a.Type.String()), a.VoyageNumber, a.Location)
-	case cargo.Unload:
-		return fmt.Sprintf("%s %s cargo off of voyage %s in %s.", prefix, strings.ToLower(a.Type.String()), a.VoyageNumber, a.Location)
-	case cargo.NotHandled:
-		return "There are currently no expected activities for this cargo."
+	var halves int
+	for _, l := range legs {
+		switch l.Status {
+		case LegCompleted, LegSkipped:
+			halves += 2
+		case LegLoaded:
+			halves++
+		}
+	}
+
+	return halves * 100 / (2 * len(legs))
+}
+
+// activeLeg returns the index of the loaded leg, or else of the first
+// pending one.
+func activeLeg(legs []Leg) *int {
+	active := -1
+	for i := len(legs) - 1; i >= 0; i-- {
+		switch legs[i].Status {
+		case LegLoaded:
+			return &i
+		case LegPending:
+			active = i
+		}
+	}
+	if active < 0 {
+		return nil
 	}
+	return &active
+}
+
+func assembleStatus(c *cargo.Cargo, names *locationNames) Status {
+	d := c.Delivery
 
-	return fmt.Sprintf("%s %s cargo in %s.", prefix, strings.ToLower(a.Type.String()), a.Location)
+	s := Status{
+		Transport:   transportStatus(d.TransportStatus),
+		Misdirected: d.IsMisdirected,
+	}
+	if d.LastKnownLocation != "" {
+		l := names.location(d.LastKnownLocation)
+		s.LastKnownLocation = &l
+	}
+	if d.TransportStatus == cargo.OnboardCarrier {
+		s.CurrentVoyage = string(d.CurrentVoyage)
+	}
+
+	return s
 }
 
-func assembleStatusText(c *cargo.Cargo) string {
-	switch c.Delivery.TransportStatus {
+func transportStatus(s cargo.TransportStatus) string {
+	switch s {
 	case cargo.NotReceived:
-		return "Not received"
+		return StatusNotReceived
 	case cargo.InPort:
-		return fmt.Sprintf("In port %s", c.Delivery.LastKnownLocation)
+		return StatusInPort
 	case cargo.OnboardCarrier:
-		return fmt.Sprintf("Onboard voyage %s", c.Delivery.CurrentVoyage)
+		return StatusOnboardCarrier
 	case cargo.Claimed:
-		return "Claimed"
+		return StatusClaimed
 	default:
-		return "Unknown"
+		return StatusUnknown
+	}
+}
+
+func assembleNextActivity(c *cargo.Cargo, names *locationNames) *Activity {
+	a := c.Delivery.NextExpectedActivity
+	if a.Type == cargo.NotHandled {
+		return nil
+	}
+	return &Activity{
+		Type:         eventType(a.Type),
+		Location:     names.location(a.Location),
+		VoyageNumber: string(a.VoyageNumber),
 	}
 }
 
-func assembleEvents(c *cargo.Cargo, handlingEvents cargo.HandlingEventRepository) []Event {
-	h := handlingEvents.QueryHandlingHistory(c.TrackingID)
+func eventType(t cargo.HandlingEventType) string {
+	s, _ := t.Spec()
+	return s.Code
+}
 
+func assembleEvents(c *cargo.Cargo, h cargo.HandlingHistory, names *locationNames) []Event {
 	var events []Event
 	for _, e := range h.HandlingEvents {
-		var description string
-
-		switch e.Activity.Type {
-		case cargo.NotHandled:
-			description = "Cargo has not yet been received."
-		case cargo.Receive:
-			description = fmt.Sprintf("Received in %s, at %s", e.Activity.Location, time.Now().Format(time.RFC3339))
-		case cargo.Load:
-			description = fmt.Sprintf("Loaded onto voyage %s in %s, at %s.", e.Activity.VoyageNumber, e.Activity.Location, time.Now().Format(time.RFC3339))
-		case cargo.Unload:
-			description = fmt.Sprintf("Unloaded off voyage %s in %s, at %s.", e.Activity.VoyageNumber, e.Activity.Location, time.Now().Format(time.RFC3339))
-		case cargo.Claim:
-			description = fmt.Sprintf("Claimed in %s, at %s.", e.Activity.Location, time.Now().Format(time.RFC3339))
-		case cargo.Customs:
-			description = fmt.Sprintf("Cleared customs in %s, at %s.", e.Activity.Location, time.Now().Format(time.RFC3339))
-		default:
-			description = "[Unknown status]"
-		}
-
 		events = append(events, Event{
-			Description: description,
-			Expected:    c.Itinerary.IsExpected(e),
+			Type:             eventType(e.Activity.Type),
+			Location:         names.location(e.Activity.Location),
+			VoyageNumber:     string(e.Activity.VoyageNumber),
+			CompletionTime:   e.CompletionTime,
+			RegistrationTime: e.RegistrationTime,
+			Expected:         c.Itinerary.IsExpected(e),
 		})
 	}
 
 	return events
 }
+
+// locationNames looks up the names of locations, once for each location.
+// Locations that cannot be found are left without a name.
+type locationNames struct {
+	ctx   context.Context
+	repo  location.Repository
+	names map[location.UNLocode]string
+}
+
+func (n *locationNames) location(code location.UNLocode) Location {
+	name, ok := n.names[code]
+	if !ok {
+		if l, err := n.repo.Find(n.ctx, code); err == nil {
+			name = l.Name
+		}
+		if n.names == nil {
+			n.names = make(map[location.UNLocode]string)
+		}
+		n.names[code] = name
+	}
+	return Location{Code: string(code), Name: name}
+}
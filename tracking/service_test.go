@@ -1,16 +1,23 @@
 package tracking
 
 import (
+	"context"
+	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/eta"
+	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/voyage"
 )
 
 func TestTrack(t *testing.T) {
 	var cargos mock.CargoRepository
-	cargos.FindFn = func(id cargo.TrackingID) (*cargo.Cargo, error) {
+	cargos.FindFn = func(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 		return cargo.New("FTL456", cargo.RouteSpecification{
 			Origin:      location.AUMEL,
 			Destination: location.SESTO,
@@ -18,13 +25,13 @@ func TestTrack(t *testing.T) {
 	}
 
 	var events mock.HandlingEventRepository
-	events.QueryHandlingHistoryFn = func(id cargo.TrackingID) cargo.HandlingHistory {
-		return cargo.HandlingHistory{}
+	events.QueryHandlingHistoryFn = func(_ context.Context, id cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
 	}
 
-	s := NewService(&cargos, &events)
+	s := NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil)
 
-	c, err := s.Track("FTL456")
+	c, err := s.Track(context.Background(), "FTL456")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -38,7 +45,316 @@ func TestTrack(t *testing.T) {
 	if c.Destination != "SESTO" {
 		t.Errorf("c.Destination = %v; want = %v", c.Destination, "SESTO")
 	}
-	if c.StatusText != cargo.NotReceived.String() {
-		t.Errorf("c.StatusText = %v; want = %v", c.StatusText, cargo.NotReceived.String())
+	if c.Status.Transport != StatusNotReceived {
+		t.Errorf("c.Status.Transport = %v; want = %v", c.Status.Transport, StatusNotReceived)
+	}
+	if c.StatusText != "" {
+		t.Errorf("c.StatusText = %q; want text to be left to Describe", c.StatusText)
+	}
+}
+
+func TestTrackHandledCargo(t *testing.T) {
+	var (
+		received = time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+		loaded   = received.Add(24 * time.Hour)
+	)
+
+	c := cargo.New("ABC123", cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.CNHKG,
+	})
+	c.AssignToRoute(cargo.Itinerary{Legs: []cargo.Leg{
+		cargo.NewLeg(voyage.V100.Number, location.SESTO, location.CNHKG, loaded, loaded.Add(72*time.Hour)),
+	}})
+
+	history := cargo.HandlingHistory{HandlingEvents: []cargo.HandlingEvent{
+		{
+			TrackingID:       c.TrackingID,
+			Activity:         cargo.HandlingActivity{Type: cargo.Receive, Location: location.SESTO},
+			CompletionTime:   received,
+			RegistrationTime: received.Add(time.Minute),
+		},
+		{
+			TrackingID:       c.TrackingID,
+			Activity:         cargo.HandlingActivity{Type: cargo.Load, Location: location.SESTO, VoyageNumber: voyage.V100.Number},
+			CompletionTime:   loaded,
+			RegistrationTime: loaded.Add(time.Minute),
+		},
+	}}
+	c.DeriveDeliveryProgress(history)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(context.Context, cargo.TrackingID) (*cargo.Cargo, error) {
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return history, nil
+	}
+
+	s := NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil)
+
+	got, err := s.Track(context.Background(), "ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		stockholm = Location{Code: "SESTO", Name: "Stockholm"}
+		hongkong  = Location{Code: "CNHKG", Name: "Hongkong"}
+	)
+
+	wantStatus := Status{
+		Transport:         StatusOnboardCarrier,
+		LastKnownLocation: &stockholm,
+		CurrentVoyage:     string(voyage.V100.Number),
+	}
+	if !reflect.DeepEqual(got.Status, wantStatus) {
+		t.Errorf("Status = %+v; want = %+v", got.Status, wantStatus)
+	}
+
+	wantNext := &Activity{Type: EventUnload, Location: hongkong, VoyageNumber: string(voyage.V100.Number)}
+	if !reflect.DeepEqual(got.NextActivity, wantNext) {
+		t.Errorf("NextActivity = %+v; want = %+v", got.NextActivity, wantNext)
+	}
+
+	wantEvents := []Event{
+		{Type: EventReceive, Location: stockholm, CompletionTime: received, RegistrationTime: received.Add(time.Minute), Expected: true},
+		{Type: EventLoad, Location: stockholm, VoyageNumber: string(voyage.V100.Number), CompletionTime: loaded, RegistrationTime: loaded.Add(time.Minute), Expected: true},
+	}
+	if !reflect.DeepEqual(got.Events, wantEvents) {
+		t.Errorf("Events = %+v; want = %+v", got.Events, wantEvents)
+	}
+
+	wantLegs := []Leg{
+		{VoyageNumber: string(voyage.V100.Number), Mode: "sea", From: stockholm, To: hongkong, LoadTime: loaded, UnloadTime: loaded.Add(72 * time.Hour), Status: LegLoaded},
+	}
+	if !reflect.DeepEqual(got.Legs, wantLegs) {
+		t.Errorf("Legs = %+v; want = %+v", got.Legs, wantLegs)
+	}
+	if got.Progress != 50 {
+		t.Errorf("Progress = %d; want = %d", got.Progress, 50)
+	}
+	if got.ActiveLeg == nil || *got.ActiveLeg != 0 {
+		t.Errorf("ActiveLeg = %v; want = 0", got.ActiveLeg)
+	}
+}
+
+func TestTrackCargoETA(t *testing.T) {
+	planned := time.Date(2030, time.January, 2, 12, 0, 0, 0, time.UTC)
+
+	c := cargo.New("ABC123", cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.CNHKG,
+	})
+	c.AssignToRoute(cargo.Itinerary{Legs: []cargo.Leg{
+		cargo.NewLeg(voyage.V100.Number, location.SESTO, location.CNHKG, planned, planned.Add(72*time.Hour)),
+	}})
+
+	// Loaded six hours late.
+	history := cargo.HandlingHistory{HandlingEvents: []cargo.HandlingEvent{{
+		TrackingID:     c.TrackingID,
+		Activity:       cargo.HandlingActivity{Type: cargo.Load, Location: location.SESTO, VoyageNumber: voyage.V100.Number},
+		CompletionTime: planned.Add(6 * time.Hour),
+	}}}
+	c.DeriveDeliveryProgress(history)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(context.Context, cargo.TrackingID) (*cargo.Cargo, error) {
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return history, nil
+	}
+
+	s := NewService(&cargos, &events, inmem.NewLocationRepository(), nil, eta.NewEstimator(&cargos))
+
+	got, err := s.Track(context.Background(), "ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := planned.Add(78 * time.Hour)
+	if !got.ETA.Equal(want) {
+		t.Errorf("ETA = %v; want = %v", got.ETA, want)
+	}
+	if !got.ETAEarliest.Equal(want) || !got.ETALatest.Equal(want) {
+		t.Errorf("ETA interval = [%v, %v]; want = [%v, %v]", got.ETAEarliest, got.ETALatest, want, want)
+	}
+}
+
+func TestLegProgress(t *testing.T) {
+	var (
+		v100 = voyage.V100.Number
+		v300 = voyage.V300.Number
+		v400 = voyage.V400.Number
+	)
+
+	itinerary := cargo.Itinerary{Legs: []cargo.Leg{
+		{VoyageNumber: v100, LoadLocation: location.SESTO, UnloadLocation: location.CNHKG},
+		{VoyageNumber: v300, LoadLocation: location.CNHKG, UnloadLocation: location.USNYC},
+		{VoyageNumber: v400, LoadLocation: location.USNYC, UnloadLocation: location.AUMEL},
+	}}
+
+	activity := func(t cargo.HandlingEventType, l location.UNLocode, v voyage.Number) cargo.HandlingEvent {
+		return cargo.HandlingEvent{Activity: cargo.HandlingActivity{Type: t, Location: l, VoyageNumber: v}}
+	}
+
+	tests := []struct {
+		name     string
+		events   []cargo.HandlingEvent
+		claimed  bool
+		want     []string
+		progress int
+		active   int
+	}{
+		{
+			name:     "not handled",
+			want:     []string{LegPending, LegPending, LegPending},
+			progress: 0,
+			active:   0,
+		},
+		{
+			name: "unloaded",
+			events: []cargo.HandlingEvent{
+				activity(cargo.Load, location.SESTO, v100),
+				activity(cargo.Unload, location.CNHKG, v100),
+			},
+			want:     []string{LegCompleted, LegPending, LegPending},
+			progress: 33,
+			active:   1,
+		},
+		{
+			name: "onboard",
+			events: []cargo.HandlingEvent{
+				activity(cargo.Load, location.SESTO, v100),
+				activity(cargo.Unload, location.CNHKG, v100),
+				activity(cargo.Load, location.CNHKG, v300),
+			},
+			want:     []string{LegCompleted, LegLoaded, LegPending},
+			progress: 50,
+			active:   1,
+		},
+		{
+			name: "rerouted past a leg",
+			events: []cargo.HandlingEvent{
+				activity(cargo.Load, location.SESTO, v100),
+				activity(cargo.Unload, location.CNHKG, v100),
+				activity(cargo.Load, location.USNYC, v400),
+			},
+			want:     []string{LegCompleted, LegSkipped, LegLoaded},
+			progress: 83,
+			active:   2,
+		},
+		{
+			name: "unloaded elsewhere",
+			events: []cargo.HandlingEvent{
+				activity(cargo.Load, location.SESTO, v100),
+				activity(cargo.Unload, location.DEHAM, v100),
+			},
+			want:     []string{LegLoaded, LegPending, LegPending},
+			progress: 16,
+			active:   0,
+		},
+		{
+			name: "claimed",
+			events: []cargo.HandlingEvent{
+				activity(cargo.Load, location.SESTO, v100),
+				activity(cargo.Unload, location.CNHKG, v100),
+			},
+			claimed:  true,
+			want:     []string{LegCompleted, LegSkipped, LegSkipped},
+			progress: 100,
+			active:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		c := cargo.New("ABC123", cargo.RouteSpecification{Origin: location.SESTO, Destination: location.AUMEL})
+		c.Itinerary = itinerary
+		if tt.claimed {
+			c.Delivery.TransportStatus = cargo.Claimed
+		}
+
+		names := &locationNames{ctx: context.Background(), repo: inmem.NewLocationRepository()}
+		legs := assembleLegs(c, cargo.HandlingHistory{HandlingEvents: tt.events}, names)
+
+		var got []string
+		for _, l := range legs {
+			got = append(got, l.Status)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: statuses = %v; want = %v", tt.name, got, tt.want)
+		}
+		if p := progress(c, legs); p != tt.progress {
+			t.Errorf("%s: progress = %d; want = %d", tt.name, p, tt.progress)
+		}
+
+		active := -1
+		if i := activeLeg(legs); i != nil {
+			active = *i
+		}
+		if active != tt.active {
+			t.Errorf("%s: active leg = %d; want = %d", tt.name, active, tt.active)
+		}
+	}
+}
+
+func TestTrackMany(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindManyFn = func(_ context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+		var cs []*cargo.Cargo
+		for _, id := range ids {
+			if id != "XYZ" {
+				cs = append(cs, cargo.New(id, cargo.RouteSpecification{
+					Origin:      location.SESTO,
+					Destination: location.CNHKG,
+				}))
+			}
+		}
+		return cs, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoriesFn = func(_ context.Context, ids []cargo.TrackingID) (map[cargo.TrackingID]cargo.HandlingHistory, error) {
+		return map[cargo.TrackingID]cargo.HandlingHistory{
+			"ABC": {HandlingEvents: []cargo.HandlingEvent{
+				{TrackingID: "ABC", Activity: cargo.HandlingActivity{Type: cargo.Receive, Location: location.SESTO}},
+			}},
+		}, nil
+	}
+
+	s := NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil)
+
+	got, err := s.TrackMany(context.Background(), []string{"ABC", "DEF", "XYZ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if _, ok := got["XYZ"]; ok {
+		t.Error("unknown cargo XYZ was tracked")
+	}
+	if n := len(got["ABC"].Events); n != 1 {
+		t.Errorf("len(got[ABC].Events) = %d; want = %d", n, 1)
+	}
+	if n := len(got["DEF"].Events); n != 0 {
+		t.Errorf("len(got[DEF].Events) = %d; want = %d", n, 0)
+	}
+	if cargos.FindInvoked || events.QueryHandlingHistoryInvoked {
+		t.Error("cargos were looked up one at a time")
+	}
+
+	tooMany := make([]string, MaxTrackMany+1)
+	for i := range tooMany {
+		tooMany[i] = strconv.Itoa(i)
+	}
+	if _, err := s.TrackMany(context.Background(), tooMany); err != ErrInvalidArgument {
+		t.Errorf("TrackMany(%d IDs) err = %v; want = %v", len(tooMany), err, ErrInvalidArgument)
 	}
 }
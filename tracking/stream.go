@@ -0,0 +1,256 @@
+package tracking
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/eta"
+	"github.com/marcusolsson/goddd/location"
+)
+
+// Kinds of updates.
+const (
+	// UpdateHandled is published whenever a handling event has been
+	// registered for a cargo.
+	UpdateHandled = "handled"
+
+	// UpdateMisdirected is published when inspecting a cargo finds it
+	// misdirected.
+	UpdateMisdirected = "misdirected"
+
+	// UpdateArrived is published when inspecting a cargo finds it unloaded
+	// at its final destination.
+	UpdateArrived = "arrived"
+)
+
+// Update is a change to the tracking read model of a cargo.
+type Update struct {
+	// ID orders the updates of a stream. Subscribers resume from the ID of
+	// the last update they received.
+	ID   uint64 `json:"id,string"`
+	Kind string `json:"type"`
+
+	Cargo Cargo `json:"cargo"`
+}
+
+// Filter selects the updates of a subscription. Empty fields match any
+// update.
+type Filter struct {
+	TrackingIDs  []string
+	Destinations []string
+	Kinds        []string
+}
+
+// Match reports whether u is selected by the filter.
+func (f Filter) Match(u Update) bool {
+	return matchAny(f.TrackingIDs, u.Cargo.TrackingID) &&
+		matchAny(f.Destinations, u.Cargo.Destination) &&
+		matchAny(f.Kinds, u.Kind)
+}
+
+func matchAny(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionBuffer is the number of updates a subscriber may fall behind
+// before it is dropped.
+const subscriptionBuffer = 64
+
+// Stream publishes updates to the tracking read models of cargos as they are
+// handled and inspected. It is notified as both a handling and an inspection
+// event handler, and keeps the most recent updates so that subscribers can
+// resume after reconnecting.
+//
+// Updates are published within the process only; subscribers of one instance
+// of the application are not told about cargos handled by another.
+type Stream struct {
+	cargos         cargo.Repository
+	handlingEvents cargo.HandlingEventRepository
+	locations      location.Repository
+	estimator      *eta.Estimator
+
+	mu      sync.Mutex
+	last    uint64
+	history []Update
+	size    int
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+// NewStream returns a new Stream keeping the last size updates for
+// subscribers to resume from.
+func NewStream(cargos cargo.Repository, events cargo.HandlingEventRepository, locations location.Repository, estimator *eta.Estimator, size int) *Stream {
+	return &Stream{
+		cargos:         cargos,
+		handlingEvents: events,
+		locations:      locations,
+		estimator:      estimator,
+		// Start from the current time so that the IDs given out before a
+		// restart are older than those given out after it.
+		last: uint64(time.Now().UnixNano()),
+		size: size,
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// CargoWasHandled publishes the tracking read model of the handled cargo.
+// It should be notified after the cargo has been inspected, so that the
+// delivery progress is up to date.
+func (s *Stream) CargoWasHandled(ctx context.Context, e cargo.HandlingEvent) {
+	c, err := s.cargos.Find(ctx, e.TrackingID)
+	if err != nil {
+		return
+	}
+	s.publishCargo(ctx, UpdateHandled, c)
+}
+
+// CargoWasMisdirected publishes the tracking read model of the misdirected
+// cargo.
+func (s *Stream) CargoWasMisdirected(ctx context.Context, c *cargo.Cargo) {
+	s.publishCargo(ctx, UpdateMisdirected, c)
+}
+
+// CargoHasArrived publishes the tracking read model of the arrived cargo.
+func (s *Stream) CargoHasArrived(ctx context.Context, c *cargo.Cargo) {
+	s.publishCargo(ctx, UpdateArrived, c)
+}
+
+// publishCargo publishes the tracking read model of c, unless it could not
+// be assembled.
+func (s *Stream) publishCargo(ctx context.Context, kind string, c *cargo.Cargo) {
+	tc, err := assemble(ctx, c, s.handlingEvents, s.locations, s.estimator)
+	if err != nil {
+		return
+	}
+	s.publish(kind, tc)
+}
+
+func (s *Stream) publish(kind string, c Cargo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.last++
+	u := Update{ID: s.last, Kind: kind, Cargo: c}
+
+	if s.size > 0 {
+		if len(s.history) == s.size {
+			s.history = append(s.history[:0], s.history[1:]...)
+		}
+		s.history = append(s.history, u)
+	}
+
+	for sub := range s.subs {
+		if !sub.filter.Match(u) {
+			continue
+		}
+		select {
+		case sub.c <- u:
+		default:
+			// Rather than holding up everyone else, drop the subscriber. It
+			// can resume from the last update it received.
+			s.drop(sub)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the updates selected by f. If
+// lastEventID is not empty, the kept updates published after it are
+// returned, to be delivered before those of the subscription. If the update
+// is no longer kept, or the ID is not one given out by the stream, all kept
+// updates are returned.
+func (s *Stream) Subscribe(f Filter, lastEventID string) ([]Update, *Subscription) {
+	sub := &Subscription{
+		stream: s,
+		filter: f,
+		c:      make(chan Update, subscriptionBuffer),
+	}
+	sub.C = sub.c
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(sub.c)
+		return nil, sub
+	}
+	s.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return nil, sub
+	}
+
+	var missed []Update
+	for _, u := range s.history[s.resumeFrom(lastEventID):] {
+		if f.Match(u) {
+			missed = append(missed, u)
+		}
+	}
+
+	return missed, sub
+}
+
+// resumeFrom returns the index of the first kept update after the update
+// with the given ID.
+func (s *Stream) resumeFrom(lastEventID string) int {
+	id, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || id > s.last || len(s.history) == 0 || id < s.history[0].ID-1 {
+		return 0
+	}
+	return len(s.history) - int(s.last-id)
+}
+
+// drop ends the subscription. The caller must hold the lock.
+func (s *Stream) drop(sub *Subscription) {
+	if _, ok := s.subs[sub]; !ok {
+		return
+	}
+	delete(s.subs, sub)
+	close(sub.c)
+}
+
+// Close ends all subscriptions. Updates are no longer published, and new
+// subscriptions end immediately.
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subs {
+		s.drop(sub)
+	}
+}
+
+// Subscription is a subscription to the updates of a Stream.
+type Subscription struct {
+	// C delivers the updates. It is closed when the subscription ends,
+	// either because the subscriber fell too far behind or because the
+	// stream was closed.
+	C <-chan Update
+
+	stream *Stream
+	filter Filter
+	c      chan Update
+}
+
+// Close ends the subscription.
+func (sub *Subscription) Close() {
+	sub.stream.mu.Lock()
+	defer sub.stream.mu.Unlock()
+
+	sub.stream.drop(sub)
+}
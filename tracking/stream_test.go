@@ -0,0 +1,166 @@
+package tracking
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/location"
+)
+
+func newTestStream(t *testing.T, size int, ids ...cargo.TrackingID) *Stream {
+	cargos := inmem.NewCargoRepository()
+	for _, id := range ids {
+		c := cargo.New(id, cargo.RouteSpecification{
+			Origin:          location.SESTO,
+			Destination:     location.CNHKG,
+			ArrivalDeadline: time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC),
+		})
+		if err := cargos.Store(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewStream(cargos, inmem.NewHandlingEventRepository(), inmem.NewLocationRepository(), nil, size)
+}
+
+func handled(s *Stream, id cargo.TrackingID) {
+	s.CargoWasHandled(context.Background(), cargo.HandlingEvent{TrackingID: id})
+}
+
+func ids(us []Update) []uint64 {
+	var ids []uint64
+	for _, u := range us {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func TestStreamPublishesToSubscribers(t *testing.T) {
+	s := newTestStream(t, 10, "ABC", "DEF")
+
+	missed, sub := s.Subscribe(Filter{TrackingIDs: []string{"DEF"}}, "")
+	defer sub.Close()
+
+	if len(missed) != 0 {
+		t.Errorf("missed = %v; want none", missed)
+	}
+
+	handled(s, "ABC")
+	handled(s, "DEF")
+	handled(s, "XYZ")
+
+	select {
+	case u := <-sub.C:
+		if u.Cargo.TrackingID != "DEF" || u.Kind != UpdateHandled {
+			t.Errorf("update = %s %s; want = DEF %s", u.Cargo.TrackingID, u.Kind, UpdateHandled)
+		}
+	default:
+		t.Fatal("no update")
+	}
+
+	select {
+	case u := <-sub.C:
+		t.Errorf("unexpected update %v", u)
+	default:
+	}
+}
+
+func TestStreamResumes(t *testing.T) {
+	s := newTestStream(t, 3, "ABC")
+
+	for i := 0; i < 4; i++ {
+		handled(s, "ABC")
+	}
+
+	_, sub := s.Subscribe(Filter{}, "")
+	sub.Close()
+
+	first := s.history[0].ID
+
+	tests := []struct {
+		lastEventID string
+		want        int
+	}{
+		{strconv.FormatUint(first, 10), 2},
+		{strconv.FormatUint(first+2, 10), 0},
+		{strconv.FormatUint(first-1, 10), 3},
+		{strconv.FormatUint(first-2, 10), 3}, // no longer kept
+		{strconv.FormatUint(first+3, 10), 3}, // not given out by the stream
+		{"garbage", 3},
+	}
+
+	for _, tt := range tests {
+		missed, sub := s.Subscribe(Filter{}, tt.lastEventID)
+		sub.Close()
+
+		if len(missed) != tt.want {
+			t.Errorf("Subscribe(%q): missed = %v; want %d updates", tt.lastEventID, ids(missed), tt.want)
+		}
+		for i := 1; i < len(missed); i++ {
+			if missed[i].ID <= missed[i-1].ID {
+				t.Errorf("Subscribe(%q): missed = %v; want increasing IDs", tt.lastEventID, ids(missed))
+			}
+		}
+	}
+}
+
+func TestStreamDropsSlowSubscribers(t *testing.T) {
+	s := newTestStream(t, 0, "ABC")
+
+	_, sub := s.Subscribe(Filter{}, "")
+
+	for i := 0; i < subscriptionBuffer+1; i++ {
+		handled(s, "ABC")
+	}
+
+	var n int
+	for range sub.C {
+		n++
+	}
+
+	if n != subscriptionBuffer {
+		t.Errorf("received %d updates; want = %d", n, subscriptionBuffer)
+	}
+}
+
+func TestStreamClose(t *testing.T) {
+	s := newTestStream(t, 10, "ABC")
+
+	_, sub := s.Subscribe(Filter{}, "")
+
+	s.Close()
+	handled(s, "ABC")
+
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription was not ended")
+	}
+
+	_, sub = s.Subscribe(Filter{}, "")
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription to a closed stream was not ended")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	u := Update{Kind: UpdateArrived, Cargo: Cargo{TrackingID: "ABC", Destination: "CNHKG"}}
+
+	tests := []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{}, true},
+		{Filter{TrackingIDs: []string{"DEF", "ABC"}}, true},
+		{Filter{TrackingIDs: []string{"DEF"}}, false},
+		{Filter{Destinations: []string{"CNHKG"}, Kinds: []string{UpdateArrived}}, true},
+		{Filter{Destinations: []string{"CNHKG"}, Kinds: []string{UpdateMisdirected}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Match(u); got != tt.want {
+			t.Errorf("%+v.Match() = %v; want = %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package tracking
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"golang.org/x/net/websocket"
+
+	"github.com/marcusolsson/goddd/cors"
+	"github.com/marcusolsson/goddd/validation"
+)
+
+// heartbeatInterval is how often idle streams are written to, to keep
+// proxies from timing them out and to notice clients that have gone away.
+var heartbeatInterval = 15 * time.Second
+
+// streamHandler streams the updates of a Stream as server-sent events, or
+// over a WebSocket if the client asks for an upgrade.
+type streamHandler struct {
+	stream  *Stream
+	origins cors.Policy
+	logger  kitlog.Logger
+}
+
+func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f, err := decodeFilter(r)
+	if err != nil {
+		encodeError(r.Context(), err, w)
+		return
+	}
+
+	// Browsers send the header when reconnecting, but cannot set it
+	// themselves.
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	var text *Text
+	if r.URL.Query().Get("text") != "false" {
+		t, err := TextFromRequest(r)
+		if err != nil {
+			encodeError(r.Context(), err, w)
+			return
+		}
+		text = &t
+	}
+
+	if isWebSocket(r) {
+		h.serveWebSocket(w, r, f, lastEventID, text)
+		return
+	}
+	h.serveEvents(w, r, f, lastEventID, text)
+}
+
+// encodeUpdate returns u as JSON, with the cargo described in its text
+// fields unless text is nil.
+func encodeUpdate(u Update, text *Text) []byte {
+	if text != nil {
+		u.Cargo = text.Describe(u.Cargo)
+	}
+	b, _ := json.Marshal(u)
+	return b
+}
+
+// serveEvents streams the updates as server-sent events. The stream ends
+// when the client goes away or the subscription ends, after which the client
+// reconnects with the ID of the last update it received.
+func (h *streamHandler) serveEvents(w http.ResponseWriter, r *http.Request, f Filter, lastEventID string, text *Text) {
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	if text != nil {
+		w.Header().Set("Content-Language", text.Language())
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		h.logger.Log("err", err)
+		return
+	}
+
+	missed, sub := h.stream.Subscribe(f, lastEventID)
+	defer sub.Close()
+
+	for _, u := range missed {
+		writeEvent(w, u, text)
+	}
+	rc.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case u, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeEvent(w, u, text)
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, u Update, text *Text) {
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", u.ID, encodeUpdate(u, text))
+}
+
+// serveWebSocket sends each update as a JSON text message. The connection is
+// closed when the subscription ends, after which the client reconnects with
+// the ID of the last update it received in the last_event_id parameter.
+func (h *streamHandler) serveWebSocket(w http.ResponseWriter, r *http.Request, f Filter, lastEventID string, text *Text) {
+	srv := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			h.streamWebSocket(conn, f, lastEventID, text)
+		},
+	}
+	srv.ServeHTTP(hijacker{w}, r)
+}
+
+// checkOrigin rejects connections from browsers on origins not allowed by
+// the CORS policy. Browsers do not apply the same-origin policy to
+// WebSockets, so this is what keeps other sites from reading the stream.
+// Clients that send no Origin are not browsers, and are accepted.
+func (h *streamHandler) checkOrigin(_ *websocket.Config, r *http.Request) error {
+	if origin := r.Header.Get("Origin"); origin != "" && !h.origins.AllowsOrigin(origin) {
+		return fmt.Errorf("origin %s not allowed", origin)
+	}
+	return nil
+}
+
+func (h *streamHandler) streamWebSocket(conn *websocket.Conn, f Filter, lastEventID string, text *Text) {
+	defer conn.Close()
+
+	missed, sub := h.stream.Subscribe(f, lastEventID)
+	defer sub.Close()
+
+	// Messages from the client are not used, but reading them answers its
+	// pings and tells when it has gone away.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var msg []byte
+		for {
+			if err := websocket.Message.Receive(conn, &msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(u Update) error {
+		return websocket.Message.Send(conn, string(encodeUpdate(u, text)))
+	}
+
+	for _, u := range missed {
+		if err := send(u); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-gone:
+			return
+		case u, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			err = send(u)
+		case <-heartbeat.C:
+			err = ping(conn)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ping sends a ping, which the client answers with a pong.
+func ping(conn *websocket.Conn) error {
+	conn.PayloadType = websocket.PingFrame
+	defer func() { conn.PayloadType = websocket.TextFrame }()
+
+	_, err := conn.Write(nil)
+	return err
+}
+
+// isWebSocket reports whether r asks for the connection to be upgraded to
+// the WebSocket protocol.
+func isWebSocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// hijacker lets the WebSocket server take over connections through
+// middlewares that wrap the http.ResponseWriter.
+type hijacker struct {
+	http.ResponseWriter
+}
+
+func (w hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func decodeFilter(r *http.Request) (Filter, error) {
+	q := r.URL.Query()
+
+	f := Filter{
+		TrackingIDs:  splitList(q.Get("tracking_id")),
+		Destinations: splitList(q.Get("destination")),
+		Kinds:        splitList(q.Get("type")),
+	}
+
+	// Updates carry the whole read model of the cargo, which is only given
+	// to clients knowing its tracking ID, as by the track endpoint.
+	var errs validation.Errors
+	switch {
+	case len(f.TrackingIDs) == 0:
+		errs.Add("tracking_id", "is required")
+	case len(f.TrackingIDs) > MaxTrackMany:
+		errs.Add("tracking_id", "must have at most %d tracking IDs", MaxTrackMany)
+	}
+	for _, k := range f.Kinds {
+		switch k {
+		case UpdateHandled, UpdateMisdirected, UpdateArrived:
+		default:
+			errs.Add("type", "must be one of %s, %s or %s", UpdateHandled, UpdateMisdirected, UpdateArrived)
+		}
+	}
+
+	return f, errs.Err()
+}
+
+// splitList splits a comma-separated list, leaving out empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
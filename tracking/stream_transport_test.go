@@ -0,0 +1,143 @@
+package tracking
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"golang.org/x/net/websocket"
+
+	"github.com/marcusolsson/goddd/cors"
+)
+
+// readEvent reads the next server-sent event, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) (id string, u Update) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && id != "":
+			return id, u
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &u); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	s := newTestStream(t, 10, "ABC", "DEF")
+
+	handled(s, "ABC")
+	handled(s, "DEF")
+	handled(s, "ABC")
+
+	srv := httptest.NewServer(MakeHandler(context.Background(), nil, s, cors.Policy{}, log.NewNopLogger()))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL+"/tracking/v1/stream?tracking_id=ABC", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(s.history[0].ID, 10))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if content := resp.Header.Get("Content-Type"); content != "text/event-stream" {
+		t.Errorf("Content-Type = %q; want = %q", content, "text/event-stream")
+	}
+
+	br := bufio.NewReader(resp.Body)
+
+	// The update missed since the last event, skipping DEF.
+	id, u := readEvent(t, br)
+	if want := strconv.FormatUint(s.history[2].ID, 10); id != want || u.Cargo.TrackingID != "ABC" {
+		t.Errorf("event = %s %s; want = %s ABC", id, u.Cargo.TrackingID, want)
+	}
+
+	handled(s, "DEF")
+	handled(s, "ABC")
+
+	id, u = readEvent(t, br)
+	if want := strconv.FormatUint(s.history[4].ID, 10); id != want || u.Cargo.TrackingID != "ABC" {
+		t.Errorf("event = %s %s; want = %s ABC", id, u.Cargo.TrackingID, want)
+	}
+	if u.Kind != UpdateHandled {
+		t.Errorf("u.Kind = %q; want = %q", u.Kind, UpdateHandled)
+	}
+}
+
+func TestStreamInvalidFilter(t *testing.T) {
+	s := newTestStream(t, 10)
+
+	h := MakeHandler(context.Background(), nil, s, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/tracking/v1/stream?type=lost", nil))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestStreamRequiresTrackingIDs(t *testing.T) {
+	s := newTestStream(t, 10)
+
+	h := MakeHandler(context.Background(), nil, s, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/tracking/v1/stream?destination=CNHKG", nil))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	s := newTestStream(t, 10, "ABC", "DEF")
+
+	policy := cors.Policy{AllowedOrigins: []string{"https://example.com"}}
+
+	srv := httptest.NewServer(MakeHandler(context.Background(), nil, s, policy, log.NewNopLogger()))
+	defer srv.Close()
+
+	handled(s, "ABC")
+	handled(s, "DEF")
+	handled(s, "ABC")
+
+	// Resume after the first update, so that the second one for ABC is sent
+	// regardless of when the subscription starts.
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/tracking/v1/stream?tracking_id=ABC&last_event_id=" + strconv.FormatUint(s.history[0].ID, 10)
+
+	if _, err := websocket.Dial(url, "", "https://evil.example"); err == nil {
+		t.Errorf("Dial from disallowed origin succeeded; want error")
+	}
+
+	conn, err := websocket.Dial(url, "", "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var u Update
+	if err := websocket.JSON.Receive(conn, &u); err != nil {
+		t.Fatal(err)
+	}
+	if want := s.history[2].ID; u.ID != want || u.Cargo.TrackingID != "ABC" {
+		t.Errorf("update = %d %s; want = %d ABC", u.ID, u.Cargo.TrackingID, want)
+	}
+}
@@ -0,0 +1,184 @@
+package tracking
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcusolsson/goddd/validation"
+)
+
+// Text describes tracking read models in one of the languages we have
+// message catalogs for, with times in a time zone. The zero value describes
+// them in English, with times in UTC.
+type Text struct {
+	lang string
+	zone *time.Location
+}
+
+// NewText returns a Text in the language of the catalog best matching an
+// Accept-Language header, with times in zone. It falls back to English if
+// there is no match, and to UTC if zone is nil.
+func NewText(acceptLanguage string, zone *time.Location) Text {
+	return Text{lang: matchLanguage(acceptLanguage), zone: zone}
+}
+
+// TextFromRequest returns the Text for the Accept-Language header of r and
+// the IANA time zone named by its tz parameter, if any.
+func TextFromRequest(r *http.Request) (Text, error) {
+	zone, err := loadZone("tz", r.URL.Query().Get("tz"))
+	if err != nil {
+		return Text{}, err
+	}
+	return NewText(r.Header.Get("Accept-Language"), zone), nil
+}
+
+// loadZone returns the IANA time zone with the given name, or nil if the
+// name is empty. Unknown zones are reported as invalid values of field.
+func loadZone(field, name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		var errs validation.Errors
+		errs.Add(field, "unknown time zone %q", name)
+		return nil, errs.Err()
+	}
+	return zone, nil
+}
+
+// Language returns the language of the text, as an ISO 639-1 code.
+func (t Text) Language() string {
+	if t.lang == "" {
+		return defaultLanguage
+	}
+	return t.lang
+}
+
+// Describe returns c with its text fields set to descriptions of its status,
+// next expected activity and handling events. The events of c are copied
+// rather than modified, as read models may be shared.
+func (t Text) Describe(c Cargo) Cargo {
+	cat := catalogs[t.Language()]
+
+	c.StatusText = t.statusText(cat, c.Status)
+	c.NextExpectedActivity = t.nextActivityText(cat, c.NextActivity)
+
+	if c.Events != nil {
+		events := make([]Event, len(c.Events))
+		for i, e := range c.Events {
+			e.Description = t.eventText(cat, e)
+			events[i] = e
+		}
+		c.Events = events
+	}
+
+	return c
+}
+
+func (t Text) statusText(cat *catalog, s Status) string {
+	msg, ok := cat.status[s.Transport]
+	if !ok {
+		return cat.unknownStatus
+	}
+
+	var location string
+	if s.LastKnownLocation != nil {
+		location = locationName(*s.LastKnownLocation)
+	}
+
+	return expand(msg, location, s.CurrentVoyage, "")
+}
+
+func (t Text) nextActivityText(cat *catalog, a *Activity) string {
+	if a == nil {
+		return cat.noActivity
+	}
+
+	msg, ok := cat.activities[a.Type]
+	if !ok {
+		return cat.noActivity
+	}
+
+	return expand(msg, locationName(a.Location), a.VoyageNumber, "")
+}
+
+func (t Text) eventText(cat *catalog, e Event) string {
+	msg, ok := cat.events[e.Type]
+	if !ok {
+		return cat.unknownEvent
+	}
+
+	zone := t.zone
+	if zone == nil {
+		zone = time.UTC
+	}
+
+	return expand(msg, locationName(e.Location), e.VoyageNumber, e.CompletionTime.In(zone).Format(cat.timeLayout))
+}
+
+// locationName returns the name of l, or its code if it has no name.
+func locationName(l Location) string {
+	if l.Name == "" {
+		return l.Code
+	}
+	return l.Name
+}
+
+// expand replaces the placeholders of a catalog message.
+func expand(msg, location, voyage, time string) string {
+	return strings.NewReplacer(
+		"{location}", location,
+		"{voyage}", voyage,
+		"{time}", time,
+	).Replace(msg)
+}
+
+// matchLanguage returns the language of the catalog best matching an
+// Accept-Language header, or the default language if none does. Languages
+// are matched on their primary subtag only, so that sv-SE and sv-FI both
+// get Swedish.
+func matchLanguage(acceptLanguage string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, r := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(r, ";")
+
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if i := strings.IndexByte(tag, '-'); i >= 0 {
+			tag = tag[:i]
+		}
+
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if !strings.HasPrefix(p, "q=") {
+				continue
+			}
+			if v, err := strconv.ParseFloat(p[len("q="):], 64); err == nil {
+				q = v
+			}
+		}
+
+		if _, ok := catalogs[tag]; ok && q > 0 {
+			langs = append(langs, weighted{lang: tag, q: q})
+		}
+	}
+
+	if len(langs) == 0 {
+		return defaultLanguage
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	return langs[0].lang
+}
@@ -0,0 +1,157 @@
+package tracking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcusolsson/goddd/cargo"
+)
+
+func TestDescribe(t *testing.T) {
+	completed := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	c := Cargo{
+		Status: Status{
+			Transport:     StatusOnboardCarrier,
+			CurrentVoyage: "V100",
+		},
+		NextActivity: &Activity{Type: EventUnload, Location: Location{Code: "CNHKG"}, VoyageNumber: "V100"},
+		Events: []Event{
+			{Type: EventReceive, Location: Location{Code: "SESTO"}, CompletionTime: completed},
+			{Type: EventLoad, Location: Location{Code: "SESTO"}, VoyageNumber: "V100", CompletionTime: completed},
+		},
+	}
+
+	got := Text{}.Describe(c)
+
+	if want := "Onboard voyage V100"; got.StatusText != want {
+		t.Errorf("StatusText = %q; want = %q", got.StatusText, want)
+	}
+	if want := "Next expected activity is to unload cargo off of voyage V100 in CNHKG."; got.NextExpectedActivity != want {
+		t.Errorf("NextExpectedActivity = %q; want = %q", got.NextExpectedActivity, want)
+	}
+
+	wantEvents := []string{
+		"Received in SESTO, at 2030-01-01T12:00:00Z",
+		"Loaded onto voyage V100 in SESTO, at 2030-01-01T12:00:00Z.",
+	}
+	for i, want := range wantEvents {
+		if got.Events[i].Description != want {
+			t.Errorf("Events[%d].Description = %q; want = %q", i, got.Events[i].Description, want)
+		}
+	}
+
+	if c.Events[0].Description != "" {
+		t.Error("Describe modified the events of its argument")
+	}
+}
+
+func TestDescribeWithoutActivities(t *testing.T) {
+	got := Text{}.Describe(Cargo{Status: Status{Transport: StatusNotReceived}})
+
+	if want := "Not received"; got.StatusText != want {
+		t.Errorf("StatusText = %q; want = %q", got.StatusText, want)
+	}
+	if want := "There are currently no expected activities for this cargo."; got.NextExpectedActivity != want {
+		t.Errorf("NextExpectedActivity = %q; want = %q", got.NextExpectedActivity, want)
+	}
+}
+
+func TestDescribeInLanguage(t *testing.T) {
+	completed := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	c := Cargo{
+		Status: Status{
+			Transport:         StatusInPort,
+			LastKnownLocation: &Location{Code: "SESTO", Name: "Stockholm"},
+		},
+		NextActivity: &Activity{Type: EventLoad, Location: Location{Code: "SESTO", Name: "Stockholm"}, VoyageNumber: "V100"},
+		Events: []Event{
+			{Type: EventReceive, Location: Location{Code: "SESTO", Name: "Stockholm"}, CompletionTime: completed},
+		},
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text     Text
+		status   string
+		activity string
+		event    string
+	}{
+		{
+			text:     NewText("", nil),
+			status:   "In port Stockholm",
+			activity: "Next expected activity is to load cargo onto voyage V100 in Stockholm.",
+			event:    "Received in Stockholm, at 2030-01-01T12:00:00Z",
+		},
+		{
+			text:     NewText("sv-SE,sv;q=0.9,en;q=0.8", nil),
+			status:   "I hamn i Stockholm",
+			activity: "Nästa förväntade aktivitet är att godset lastas på resa V100 i Stockholm.",
+			event:    "Mottaget i Stockholm, 2030-01-01 12:00 UTC.",
+		},
+		{
+			text:     NewText("ja", tokyo),
+			status:   "Stockholm港に停泊中",
+			activity: "次の予定：Stockholmで航海V100に積み込み。",
+			event:    "2030年1月1日 21:00 JST、Stockholmで受領。",
+		},
+		{
+			text:     NewText("de-DE", tokyo),
+			status:   "Im Hafen Stockholm",
+			activity: "Als Nächstes wird die Sendung in Stockholm auf die Reise V100 verladen.",
+			event:    "Angenommen in Stockholm, am 01.01.2030, 21:00 JST.",
+		},
+	}
+
+	for _, tt := range tests {
+		got := tt.text.Describe(c)
+
+		if got.StatusText != tt.status {
+			t.Errorf("%s: StatusText = %q; want = %q", tt.text.Language(), got.StatusText, tt.status)
+		}
+		if got.NextExpectedActivity != tt.activity {
+			t.Errorf("%s: NextExpectedActivity = %q; want = %q", tt.text.Language(), got.NextExpectedActivity, tt.activity)
+		}
+		if got.Events[0].Description != tt.event {
+			t.Errorf("%s: Events[0].Description = %q; want = %q", tt.text.Language(), got.Events[0].Description, tt.event)
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "en"},
+		{"*", "en"},
+		{"fr-FR, fr;q=0.9", "en"},
+		{"sv", "sv"},
+		{"SV-fi", "sv"},
+		{"fr;q=0.9, ja;q=0.5, de;q=0.7", "de"},
+		{"en;q=0.2, ja", "ja"},
+		{"de;q=0, sv;q=0.1", "sv"},
+		{"ja;q=garbage", "ja"},
+	}
+
+	for _, tt := range tests {
+		if got := matchLanguage(tt.in); got != tt.want {
+			t.Errorf("matchLanguage(%q) = %q; want = %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsDescribeAllEventTypes(t *testing.T) {
+	for lang, cat := range catalogs {
+		for _, typ := range cargo.HandlingEventTypes() {
+			if _, ok := cat.events[eventType(typ)]; !ok {
+				t.Errorf("%s: no message for %s events", lang, typ)
+			}
+		}
+	}
+}
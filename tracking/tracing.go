@@ -0,0 +1,48 @@
+package tracking
+
+import (
+	"context"
+
+	"github.com/marcusolsson/goddd/tracing"
+)
+
+type tracingService struct {
+	tracer *tracing.Tracer
+	Service
+}
+
+// NewTracingService returns an instance of a tracing Service.
+func NewTracingService(tracer *tracing.Tracer, s Service) Service {
+	return &tracingService{tracer, s}
+}
+
+func (s *tracingService) Track(ctx context.Context, id string) (c Cargo, err error) {
+	ctx, span := s.tracer.Start(ctx, "tracking.track", tracing.KindInternal)
+	span.SetAttributes("tracking_id", id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.Track(ctx, id)
+}
+
+func (s *tracingService) TrackMany(ctx context.Context, ids []string) (cs map[string]Cargo, err error) {
+	ctx, span := s.tracer.Start(ctx, "tracking.track_many", tracing.KindInternal)
+	span.SetAttributes("requested", len(ids))
+	defer func() {
+		span.SetAttributes("found", len(cs))
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.TrackMany(ctx, ids)
+}
+
+func (s *tracingService) TrackPublic(ctx context.Context, tok string) (c Cargo, err error) {
+	ctx, span := s.tracer.Start(ctx, "tracking.track_public", tracing.KindInternal)
+	defer func() {
+		span.SetAttributes("tracking_id", c.TrackingID)
+		span.SetError(err)
+		span.End()
+	}()
+	return s.Service.TrackPublic(ctx, tok)
+}
@@ -12,13 +12,21 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/cors"
+	"github.com/marcusolsson/goddd/httpctx"
+	"github.com/marcusolsson/goddd/requestid"
+	"github.com/marcusolsson/goddd/token"
+	"github.com/marcusolsson/goddd/validation"
 )
 
-// MakeHandler returns a handler for the tracking service.
-func MakeHandler(ctx context.Context, ts Service, logger kitlog.Logger) http.Handler {
+// MakeHandler returns a router for the tracking service, streaming the
+// updates of s to clients of /tracking/v1/stream. WebSocket connections are
+// only accepted from the origins allowed by the CORS policy.
+func MakeHandler(ctx context.Context, ts Service, s *Stream, origins cors.Policy, logger kitlog.Logger) *mux.Router {
 	r := mux.NewRouter()
 
 	opts := []kithttp.ServerOption{
+		kithttp.ServerBefore(httpctx.FromRequest, requestid.HTTPToContext),
 		kithttp.ServerErrorLogger(logger),
 		kithttp.ServerErrorEncoder(encodeError),
 	}
@@ -31,19 +39,110 @@ func MakeHandler(ctx context.Context, ts Service, logger kitlog.Logger) http.Han
 		opts...,
 	)
 
+	trackCargosHandler := kithttp.NewServer(
+		ctx,
+		makeTrackCargosEndpoint(ts),
+		decodeTrackCargosRequest,
+		encodeResponse,
+		opts...,
+	)
+
+	trackPublicHandler := kithttp.NewServer(
+		ctx,
+		makeTrackPublicEndpoint(ts),
+		decodeTrackPublicRequest,
+		encodeResponse,
+		opts...,
+	)
+
+	r.Handle("/tracking/v1/cargos", trackCargosHandler).Methods("GET")
 	r.Handle("/tracking/v1/cargos/{id}", trackCargoHandler).Methods("GET")
-	r.Handle("/tracking/v1/docs", http.StripPrefix("/tracking/v1/docs", http.FileServer(http.Dir("tracking/docs"))))
+	r.Handle(token.PathPrefix+"{token}", private(trackPublicHandler)).Methods("GET")
+	r.Handle("/tracking/v1/stream", &streamHandler{stream: s, origins: origins, logger: logger}).Methods("GET")
+	r.Handle("/tracking/v1/openapi.json", OpenAPI).Methods("GET")
 
 	return r
 }
 
+// private keeps the responses of h out of shared caches, and the URL of the
+// request, which is a secret, out of the Referer header of links followed
+// from pages showing them.
+func private(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		h.ServeHTTP(w, r)
+	})
+}
+
 func decodeTrackCargoRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
 		return nil, errors.New("bad route")
 	}
-	return trackCargoRequest{ID: id}, nil
+
+	req := trackCargoRequest{ID: id}
+	if r.URL.Query().Get("text") != "false" {
+		text, err := TextFromRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		req.Text = &text
+	}
+	return req, nil
+}
+
+func decodeTrackPublicRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	tok, ok := vars["token"]
+	if !ok {
+		return nil, errors.New("bad route")
+	}
+
+	req := trackPublicRequest{Token: tok}
+	if r.URL.Query().Get("text") != "false" {
+		text, err := TextFromRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		req.Text = &text
+	}
+	return req, nil
+}
+
+func decodeTrackCargosRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var (
+		req  trackCargosRequest
+		seen = make(map[string]bool)
+	)
+	for _, id := range splitList(r.URL.Query().Get("tracking_id")) {
+		if !seen[id] {
+			seen[id] = true
+			req.IDs = append(req.IDs, id)
+		}
+	}
+
+	var errs validation.Errors
+	switch {
+	case len(req.IDs) == 0:
+		errs.Add("tracking_id", "is required")
+	case len(req.IDs) > MaxTrackMany:
+		errs.Add("tracking_id", "must have at most %d tracking IDs", MaxTrackMany)
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	if r.URL.Query().Get("text") != "false" {
+		text, err := TextFromRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		req.Text = &text
+	}
+
+	return req, nil
 }
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
@@ -51,6 +150,10 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 		encodeError(ctx, e.error(), w)
 		return nil
 	}
+	if l, ok := response.(languager); ok && l.language() != "" {
+		w.Header().Set("Content-Language", l.language())
+	}
+	w.Header().Add("Vary", "Accept-Language")
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	return json.NewEncoder(w).Encode(response)
 }
@@ -59,18 +162,31 @@ type errorer interface {
 	error() error
 }
 
+// languager is implemented by responses with text in a negotiated language.
+type languager interface {
+	language() string
+}
+
 // encode errors from business-logic
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	switch err {
-	case cargo.ErrUnknown:
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	body := map[string]interface{}{
+		"error": err.Error(),
+	}
+
+	var verr *validation.Error
+	switch {
+	case errors.As(err, &verr):
+		body["fields"] = verr.Fields
+		w.WriteHeader(verr.StatusCode())
+	case err == cargo.ErrUnknown, err == token.ErrInvalid:
 		w.WriteHeader(http.StatusNotFound)
-	case ErrInvalidArgument:
+	case err == token.ErrExpired, err == token.ErrRevoked:
+		w.WriteHeader(http.StatusGone)
+	case err == ErrInvalidArgument:
 		w.WriteHeader(http.StatusBadRequest)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
+	json.NewEncoder(w).Encode(body)
 }
@@ -2,10 +2,12 @@ package tracking
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,18 +16,24 @@ import (
 	"github.com/go-kit/kit/log"
 
 	"github.com/marcusolsson/goddd/cargo"
+	"github.com/marcusolsson/goddd/cors"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/location"
 	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/openapi/openapitest"
+	"github.com/marcusolsson/goddd/token"
+	"github.com/marcusolsson/goddd/voyage"
 )
 
 func TestTrackCargo(t *testing.T) {
 	var cargos mockCargoRepository
 
 	var events mock.HandlingEventRepository
-	events.QueryHandlingHistoryFn = func(cargo.TrackingID) cargo.HandlingHistory {
-		return cargo.HandlingHistory{}
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
 	}
 
-	s := NewService(&cargos, &events)
+	s := NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil)
 
 	c := cargo.New("TEST", cargo.RouteSpecification{
 		Origin:          "SESTO",
@@ -33,13 +41,13 @@ func TestTrackCargo(t *testing.T) {
 		ArrivalDeadline: time.Date(2005, 12, 4, 0, 0, 0, 0, time.UTC),
 	})
 
-	cargos.Store(c)
+	cargos.Store(context.Background(), c)
 
 	ctx := context.Background()
 
 	logger := log.NewLogfmtLogger(ioutil.Discard)
 
-	h := MakeHandler(ctx, s, logger)
+	h := MakeHandler(ctx, s, nil, cors.Policy{}, logger)
 
 	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST", nil)
 	rec := httptest.NewRecorder()
@@ -71,6 +79,7 @@ func TestTrackCargo(t *testing.T) {
 		Destination:          "FIHEL",
 		ArrivalDeadline:      time.Date(2005, 12, 4, 0, 0, 0, 0, time.UTC),
 		ETA:                  eta.In(time.UTC),
+		Status:               Status{Transport: StatusNotReceived},
 		StatusText:           "Not received",
 		NextExpectedActivity: "There are currently no expected activities for this cargo.",
 		Events:               nil,
@@ -81,21 +90,258 @@ func TestTrackCargo(t *testing.T) {
 	}
 }
 
+func TestTrackCargoWithoutText(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
+	}
+
+	cargos.Store(context.Background(), cargo.New("TEST", cargo.RouteSpecification{
+		Origin:      "SESTO",
+		Destination: "FIHEL",
+	}))
+
+	h := MakeHandler(context.Background(), NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST?text=false", nil))
+
+	var response struct {
+		Cargo map[string]interface{} `json:"cargo"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{"status_text", "next_expected_activity"} {
+		if v, ok := response.Cargo[field]; ok {
+			t.Errorf("%s = %q; want it left out", field, v)
+		}
+	}
+	if _, ok := response.Cargo["status"]; !ok {
+		t.Error("status is missing")
+	}
+}
+
+func TestTrackRoutedCargo(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		departure = time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	c := cargo.New("TEST", cargo.RouteSpecification{
+		Origin:      location.SESTO,
+		Destination: location.AUMEL,
+	})
+	c.AssignToRoute(cargo.Itinerary{Legs: []cargo.Leg{
+		cargo.NewLeg(voyage.V100.Number, location.SESTO, location.CNHKG, departure, departure.Add(72*time.Hour)),
+		cargo.NewLeg(voyage.V400.Number, location.CNHKG, location.AUMEL, departure.Add(96*time.Hour), departure.Add(240*time.Hour)),
+	}})
+
+	cargos := inmem.NewCargoRepository()
+	events := inmem.NewHandlingEventRepository()
+
+	for i, a := range []cargo.HandlingActivity{
+		{Type: cargo.Receive, Location: location.SESTO},
+		{Type: cargo.Load, Location: location.SESTO, VoyageNumber: voyage.V100.Number},
+		{Type: cargo.Unload, Location: location.CNHKG, VoyageNumber: voyage.V100.Number},
+	} {
+		if err := events.Store(ctx, cargo.HandlingEvent{
+			TrackingID:     c.TrackingID,
+			Activity:       a,
+			CompletionTime: departure.Add(time.Duration(i) * 24 * time.Hour),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := events.QueryHandlingHistory(ctx, c.TrackingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.DeriveDeliveryProgress(history)
+
+	if err := cargos.Store(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	h := MakeHandler(ctx, NewService(cargos, events, inmem.NewLocationRepository(), nil, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST?text=false", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Cargo struct {
+			Legs []struct {
+				VoyageNumber string   `json:"voyage_number"`
+				From         Location `json:"from"`
+				To           Location `json:"to"`
+				Status       string   `json:"status"`
+			} `json:"legs"`
+			Progress  int  `json:"progress"`
+			ActiveLeg *int `json:"active_leg"`
+		} `json:"cargo"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	legs := response.Cargo.Legs
+	if len(legs) != 2 {
+		t.Fatalf("len(legs) = %d; want = %d", len(legs), 2)
+	}
+	if legs[0].Status != LegCompleted || legs[1].Status != LegPending {
+		t.Errorf("leg statuses = %s, %s; want = %s, %s", legs[0].Status, legs[1].Status, LegCompleted, LegPending)
+	}
+	if want := (Location{Code: "CNHKG", Name: "Hongkong"}); legs[1].From != want {
+		t.Errorf("legs[1].From = %+v; want = %+v", legs[1].From, want)
+	}
+	if legs[1].VoyageNumber != "V400" {
+		t.Errorf("legs[1].VoyageNumber = %q; want = %q", legs[1].VoyageNumber, "V400")
+	}
+	if response.Cargo.Progress != 50 {
+		t.Errorf("progress = %d; want = %d", response.Cargo.Progress, 50)
+	}
+	if a := response.Cargo.ActiveLeg; a == nil || *a != 1 {
+		t.Errorf("active_leg = %v; want = 1", a)
+	}
+}
+
+func TestTrackCargos(t *testing.T) {
+	ctx := context.Background()
+
+	cargos := inmem.NewCargoRepository()
+	for _, id := range []cargo.TrackingID{"ABC", "DEF"} {
+		cargos.Store(ctx, cargo.New(id, cargo.RouteSpecification{
+			Origin:      location.SESTO,
+			Destination: location.CNHKG,
+		}))
+	}
+
+	h := MakeHandler(ctx, NewService(cargos, inmem.NewHandlingEventRepository(), inmem.NewLocationRepository(), nil, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/cargos?tracking_id=DEF,XYZ,ABC,DEF", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Cargos []Cargo           `json:"cargos"`
+		Errors map[string]string `json:"errors"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, c := range response.Cargos {
+		ids = append(ids, c.TrackingID)
+		if c.StatusText == "" {
+			t.Errorf("%s: status_text is empty", c.TrackingID)
+		}
+	}
+	if want := []string{"DEF", "ABC"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("cargos = %v; want = %v", ids, want)
+	}
+
+	if want := map[string]string{"XYZ": "unknown cargo"}; !reflect.DeepEqual(response.Errors, want) {
+		t.Errorf("errors = %v; want = %v", response.Errors, want)
+	}
+}
+
+func TestTrackCargosInvalidRequest(t *testing.T) {
+	h := MakeHandler(context.Background(), NewService(inmem.NewCargoRepository(), inmem.NewHandlingEventRepository(), inmem.NewLocationRepository(), nil, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	tooMany := make([]string, MaxTrackMany+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("ID%d", i)
+	}
+
+	for _, ids := range []string{"", ",", strings.Join(tooMany, ",")} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/cargos?tracking_id="+ids, nil))
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%d tracking IDs: rec.Code = %d; want = %d", len(splitList(ids)), rec.Code, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestTrackCargoInLanguage(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
+	}
+
+	cargos.Store(context.Background(), cargo.New("TEST", cargo.RouteSpecification{
+		Origin:      "SESTO",
+		Destination: "FIHEL",
+	}))
+
+	h := MakeHandler(context.Background(), NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	req := httptest.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST?tz=Europe/Berlin", nil)
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.5")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Language"); got != "de" {
+		t.Errorf("Content-Language = %q; want = %q", got, "de")
+	}
+
+	var response struct {
+		Cargo Cargo `json:"cargo"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Nicht angenommen"; response.Cargo.StatusText != want {
+		t.Errorf("StatusText = %q; want = %q", response.Cargo.StatusText, want)
+	}
+}
+
+func TestTrackCargoInUnknownTimeZone(t *testing.T) {
+	var cargos mockCargoRepository
+
+	h := MakeHandler(context.Background(), NewService(&cargos, nil, inmem.NewLocationRepository(), nil, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST?tz=Mars/Olympus_Mons", nil))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
 func TestTrackUnknownCargo(t *testing.T) {
 	var cargos mockCargoRepository
 
 	var events mock.HandlingEventRepository
-	events.QueryHandlingHistoryFn = func(cargo.TrackingID) cargo.HandlingHistory {
-		return cargo.HandlingHistory{}
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{}, nil
 	}
 
-	s := NewService(&cargos, &events)
+	s := NewService(&cargos, &events, inmem.NewLocationRepository(), nil, nil)
 
 	ctx := context.Background()
 
 	logger := log.NewLogfmtLogger(ioutil.Discard)
 
-	h := MakeHandler(ctx, s, logger)
+	h := MakeHandler(ctx, s, nil, cors.Policy{}, logger)
 
 	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/not_found", nil)
 	rec := httptest.NewRecorder()
@@ -125,22 +371,142 @@ func TestTrackUnknownCargo(t *testing.T) {
 	}
 }
 
+func TestTrackPublic(t *testing.T) {
+	ctx := context.Background()
+
+	var cargos mockCargoRepository
+	cargos.Store(ctx, cargo.New("TEST", cargo.RouteSpecification{
+		Origin:          "SESTO",
+		Destination:     "FIHEL",
+		ArrivalDeadline: time.Date(2005, 12, 4, 0, 0, 0, 0, time.UTC),
+	}))
+
+	received := time.Date(2005, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(context.Context, cargo.TrackingID) (cargo.HandlingHistory, error) {
+		return cargo.HandlingHistory{HandlingEvents: []cargo.HandlingEvent{{
+			TrackingID:       "TEST",
+			Activity:         cargo.HandlingActivity{Type: cargo.Receive, Location: location.SESTO},
+			CompletionTime:   received,
+			RegistrationTime: received.Add(time.Minute),
+		}}}, nil
+	}
+
+	tokens := token.NewIssuer([]byte("secret"), time.Hour, inmem.NewRevocationStore())
+	tok, _ := tokens.Issue("TEST")
+
+	h := MakeHandler(ctx, NewService(&cargos, &events, inmem.NewLocationRepository(), tokens, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/public/"+tok, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	for name, want := range map[string]string{
+		"Cache-Control":   "no-store",
+		"Referrer-Policy": "no-referrer",
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q; want = %q", name, got, want)
+		}
+	}
+
+	var response trackCargoResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	c := response.Cargo
+	if c.TrackingID != "TEST" {
+		t.Errorf("TrackingID = %q; want = %q", c.TrackingID, "TEST")
+	}
+	if !c.ArrivalDeadline.IsZero() {
+		t.Errorf("ArrivalDeadline = %v; want zero", c.ArrivalDeadline)
+	}
+	if len(c.Events) != 1 {
+		t.Fatalf("len(Events) = %d; want = %d", len(c.Events), 1)
+	}
+	if !c.Events[0].CompletionTime.Equal(received) {
+		t.Errorf("CompletionTime = %v; want = %v", c.Events[0].CompletionTime, received)
+	}
+	if !c.Events[0].RegistrationTime.IsZero() {
+		t.Errorf("RegistrationTime = %v; want zero", c.Events[0].RegistrationTime)
+	}
+}
+
+func TestTrackPublicWithInvalidToken(t *testing.T) {
+	ctx := context.Background()
+
+	var cargos mockCargoRepository
+	cargos.Store(ctx, cargo.New("TEST", cargo.RouteSpecification{}))
+
+	tokens := token.NewIssuer([]byte("secret"), time.Hour, inmem.NewRevocationStore())
+	revoked, _ := tokens.Issue("TEST")
+	if err := tokens.Revoke(ctx, "TEST"); err != nil {
+		t.Fatal(err)
+	}
+	forged, _ := token.NewIssuer([]byte("guess"), time.Hour, inmem.NewRevocationStore()).Issue("TEST")
+
+	h := MakeHandler(ctx, NewService(&cargos, nil, inmem.NewLocationRepository(), tokens, nil), nil, cors.Policy{}, log.NewNopLogger())
+
+	for _, tt := range []struct {
+		token string
+		want  int
+	}{
+		{forged, http.StatusNotFound},
+		{"garbage", http.StatusNotFound},
+		{revoked, http.StatusGone},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/tracking/v1/public/"+tt.token, nil))
+
+		if rec.Code != tt.want {
+			t.Errorf("rec.Code = %d; want = %d", rec.Code, tt.want)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("Cache-Control = %q; want = %q", got, "no-store")
+		}
+	}
+}
+
 type mockCargoRepository struct {
 	cargo *cargo.Cargo
 }
 
-func (r *mockCargoRepository) Store(c *cargo.Cargo) error {
+func (r *mockCargoRepository) Store(_ context.Context, c *cargo.Cargo) error {
 	r.cargo = c
 	return nil
 }
 
-func (r *mockCargoRepository) Find(id cargo.TrackingID) (*cargo.Cargo, error) {
+func (r *mockCargoRepository) Find(_ context.Context, id cargo.TrackingID) (*cargo.Cargo, error) {
 	if r.cargo != nil {
 		return r.cargo, nil
 	}
 	return nil, cargo.ErrUnknown
 }
 
-func (r *mockCargoRepository) FindAll() []*cargo.Cargo {
-	return []*cargo.Cargo{r.cargo}
+func (r *mockCargoRepository) FindMany(ctx context.Context, ids []cargo.TrackingID) ([]*cargo.Cargo, error) {
+	var cs []*cargo.Cargo
+	for _, id := range ids {
+		if c, err := r.Find(ctx, id); err == nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+func (r *mockCargoRepository) FindAll(_ context.Context) ([]*cargo.Cargo, error) {
+	return []*cargo.Cargo{r.cargo}, nil
+}
+
+func (r *mockCargoRepository) Remove(_ context.Context, c *cargo.Cargo) error {
+	r.cargo = nil
+	return nil
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	openapitest.CheckRoutes(t, OpenAPI, MakeHandler(context.Background(), nil, nil, cors.Policy{}, log.NewNopLogger()))
 }
@@ -0,0 +1,139 @@
+// Package validation provides field-level errors for invalid requests, and
+// strict decoding of JSON request bodies.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldError describes why the value of a field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error lists the invalid fields of a request.
+type Error struct {
+	// Malformed is set when the request could not be decoded, as opposed
+	// to being well-formed but holding values that are not valid.
+	Malformed bool
+
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		if f.Field == "" {
+			msgs[i] = f.Message
+		} else {
+			msgs[i] = f.Field + ": " + f.Message
+		}
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// StatusCode returns 400 Bad Request for malformed requests and 422
+// Unprocessable Entity otherwise.
+func (e *Error) StatusCode() int {
+	if e.Malformed {
+		return http.StatusBadRequest
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// Errors collects field errors.
+type Errors struct {
+	fields []FieldError
+}
+
+// Add adds an error for the field.
+func (es *Errors) Add(field, format string, args ...interface{}) {
+	es.fields = append(es.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns an *Error listing the collected field errors, or nil if there
+// are none.
+func (es *Errors) Err() error {
+	if len(es.fields) == 0 {
+		return nil
+	}
+	return &Error{Fields: es.fields}
+}
+
+// DecodeJSON decodes a single JSON value from r into v, rejecting unknown
+// fields. Decoding errors are returned as a malformed *Error.
+func DecodeJSON(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		if dec.More() {
+			return malformed("", "must contain a single JSON value")
+		}
+		return nil
+	}
+
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+
+	switch {
+	case err == io.EOF:
+		return malformed("", "body is required")
+	case errors.As(err, &typeErr):
+		return malformed(typeErr.Field, "must be "+describe(typeErr.Type))
+	case errors.As(err, &syntaxErr), err == io.ErrUnexpectedEOF:
+		return malformed("", "malformed JSON")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return malformed(field, "unknown field")
+	}
+
+	return malformed("", err.Error())
+}
+
+// ParseTime parses an RFC 3339 time given in the field. An empty string is
+// parsed as the zero time, leaving it to the caller to decide whether the
+// field is required.
+func ParseTime(field, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, malformed(field, "must be an RFC 3339 date-time")
+	}
+	return t, nil
+}
+
+// describe returns the kind of JSON value decoded into t.
+func describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+func malformed(field, msg string) error {
+	return &Error{Malformed: true, Fields: []FieldError{{Field: field, Message: msg}}}
+}
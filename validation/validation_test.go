@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		body string
+		err  string
+	}{
+		{body: `{"name": "SESTO", "count": 2}`},
+		{body: ``, err: "invalid request: body is required"},
+		{body: `{"name": `, err: "invalid request: malformed JSON"},
+		{body: `{"name": 12}`, err: "invalid request: name: must be a string"},
+		{body: `{"count": "two"}`, err: "invalid request: count: must be an integer"},
+		{body: `{"color": "red"}`, err: "invalid request: color: unknown field"},
+		{body: `{} {}`, err: "invalid request: must contain a single JSON value"},
+	}
+
+	for _, tt := range tests {
+		var v struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		}
+
+		err := DecodeJSON(strings.NewReader(tt.body), &v)
+
+		var got string
+		if err != nil {
+			got = err.Error()
+			if e, ok := err.(*Error); !ok || !e.Malformed {
+				t.Errorf("%q: err = %#v; want malformed *Error", tt.body, err)
+			}
+		}
+		if got != tt.err {
+			t.Errorf("%q: err = %q; want = %q", tt.body, got, tt.err)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	got, err := ParseTime("deadline", "2016-03-21T19:50:24Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2016, time.March, 21, 19, 50, 24, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseTime() = %v; want = %v", got, want)
+	}
+
+	if got, err := ParseTime("deadline", ""); err != nil || !got.IsZero() {
+		t.Errorf("ParseTime(\"\") = %v, %v; want = zero time, nil", got, err)
+	}
+
+	_, err = ParseTime("deadline", "tomorrow")
+	if want := "invalid request: deadline: must be an RFC 3339 date-time"; err == nil || err.Error() != want {
+		t.Errorf("err = %v; want = %s", err, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var errs Errors
+	if err := errs.Err(); err != nil {
+		t.Errorf("err = %v; want = nil", err)
+	}
+
+	errs.Add("origin", "is required")
+	errs.Add("destination", "unknown location %q", "XXXXX")
+
+	err := errs.Err().(*Error)
+	if want := `invalid request: origin: is required; destination: unknown location "XXXXX"`; err.Error() != want {
+		t.Errorf("err = %s; want = %s", err, want)
+	}
+	if err.StatusCode() != http.StatusUnprocessableEntity {
+		t.Errorf("err.StatusCode() = %d; want = %d", err.StatusCode(), http.StatusUnprocessableEntity)
+	}
+}
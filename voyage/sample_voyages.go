@@ -29,6 +29,23 @@ var (
 	})
 )
 
+// A set of sample inland voyages, by rail and road.
+var (
+	R100 = &Voyage{Number: "R100", Mode: Rail, Schedule: Schedule{
+		[]CarrierMovement{
+			{DepartureLocation: location.USNYC, ArrivalLocation: location.USCHI},
+			{DepartureLocation: location.USCHI, ArrivalLocation: location.USNYC},
+		},
+	}}
+
+	T100 = &Voyage{Number: "T100", Mode: Road, Schedule: Schedule{
+		[]CarrierMovement{
+			{DepartureLocation: location.NLRTM, ArrivalLocation: location.DEHAM},
+			{DepartureLocation: location.DEHAM, ArrivalLocation: location.NLRTM},
+		},
+	}}
+)
+
 // These voyages are hard-coded into the current pathfinder. Make sure
 // they exist.
 var (
@@ -2,7 +2,9 @@
 package voyage
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/marcusolsson/goddd/location"
@@ -11,23 +13,82 @@ import (
 // Number uniquely identifies a particular Voyage.
 type Number string
 
-// Voyage is a uniquely identifiable series of carrier movements.
+// Voyage is a uniquely identifiable series of carrier movements, by vessel,
+// train, truck or aircraft.
 type Voyage struct {
 	Number   Number
+	Mode     Mode
 	Schedule Schedule
 }
 
-// New creates a voyage with a voyage number and a provided schedule.
+// New creates a voyage by sea with a voyage number and a provided schedule.
 func New(n Number, s Schedule) *Voyage {
 	return &Voyage{Number: n, Schedule: s}
 }
 
+// Mode is the mode of transport of a voyage.
+type Mode int
+
+// Modes of transport. The zero value is Sea, so that voyages stored before
+// modes were introduced remain sea voyages.
+const (
+	Sea Mode = iota
+	Rail
+	Road
+	Air
+)
+
+var modeNames = []string{
+	Sea:  "sea",
+	Rail: "rail",
+	Road: "road",
+	Air:  "air",
+}
+
+// Modes lists the modes of transport.
+var Modes = []Mode{Sea, Rail, Road, Air}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return ""
+	}
+	return modeNames[m]
+}
+
+// ParseMode returns the mode of transport by name, e.g. "rail".
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return Sea, fmt.Errorf("unknown mode of transport %q", s)
+}
+
+// MarshalText encodes the mode by name.
+func (m Mode) MarshalText() ([]byte, error) {
+	if m.String() == "" {
+		return nil, fmt.Errorf("unknown mode of transport %d", int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a mode by name.
+func (m *Mode) UnmarshalText(b []byte) error {
+	mode, err := ParseMode(string(b))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
 // Schedule describes a voyage schedule.
 type Schedule struct {
 	CarrierMovements []CarrierMovement
 }
 
-// CarrierMovement is a vessel voyage from one location to another.
+// CarrierMovement is a movement of a carrier from one location to another.
 type CarrierMovement struct {
 	DepartureLocation location.UNLocode
 	ArrivalLocation   location.UNLocode
@@ -40,5 +101,6 @@ var ErrUnknown = errors.New("unknown voyage")
 
 // Repository provides access a voyage store.
 type Repository interface {
-	Find(Number) (*Voyage, error)
+	Find(ctx context.Context, number Number) (*Voyage, error)
+	FindAll(ctx context.Context) ([]*Voyage, error)
 }
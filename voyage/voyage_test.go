@@ -0,0 +1,28 @@
+package voyage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestModeText(t *testing.T) {
+	for _, m := range Modes {
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got Mode
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != m {
+			t.Errorf("got = %v; want = %v", got, m)
+		}
+	}
+
+	var m Mode
+	if err := json.Unmarshal([]byte(`"hovercraft"`), &m); err == nil {
+		t.Errorf("err = nil; want error for unknown mode")
+	}
+}